@@ -0,0 +1,12 @@
+CREATE TABLE `transaction_changelogs` (
+    `changelog_id` BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `transaction_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `source` ENUM('importer', 'user', 'rule') NOT NULL COLLATE 'utf8mb4_bin',
+    `actor` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_bin',
+    `changelog` JSON NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (`changelog_id`) USING BTREE,
+    INDEX `transaction_changelogs_item_id_transaction_id_idx` (`item_id`, `transaction_id`) USING BTREE,
+    CONSTRAINT `transaction_changelogs_item_id_user_items_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `ledger`.`user_items` (`item_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE
+    `transactions` ADD COLUMN `hidden_reason` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_bin'
+AFTER
+    `hidden_at`;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE `transaction_relations` (
+    `relation_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `transaction_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `related_transaction_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `type` ENUM('refund') NOT NULL COLLATE 'utf8mb4_bin',
+    `status` ENUM('suggested', 'confirmed', 'rejected') NOT NULL DEFAULT 'suggested' COLLATE 'utf8mb4_bin',
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`relation_id`) USING BTREE,
+    UNIQUE INDEX `transaction_relations_item_id_transaction_id_type_idx` (`item_id`, `transaction_id`, `type`) USING BTREE,
+    INDEX `transaction_relations_item_id_related_transaction_id_idx` (`item_id`, `related_transaction_id`) USING BTREE,
+    CONSTRAINT `transaction_relations_item_id_user_items_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `ledger`.`user_items` (`item_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
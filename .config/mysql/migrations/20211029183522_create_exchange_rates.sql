@@ -0,0 +1,9 @@
+CREATE TABLE `exchange_rates` (
+    `date` DATE NOT NULL,
+    `from_currency` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `to_currency` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `rate` DOUBLE NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`from_currency`, `to_currency`, `date`) USING BTREE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
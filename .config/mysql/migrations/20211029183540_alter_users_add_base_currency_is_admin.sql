@@ -0,0 +1,6 @@
+ALTER TABLE
+    `users`
+ADD
+    COLUMN `base_currency` VARCHAR(16) NOT NULL DEFAULT 'USD' COLLATE 'utf8mb4_bin' AFTER `auth0_subject`,
+ADD
+    COLUMN `is_admin` TINYINT(1) NOT NULL DEFAULT 0 AFTER `base_currency`;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE `transaction_attachments` (
+    `attachment_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `transaction_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `object_key` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_bin',
+    `filename` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_bin',
+    `content_type` VARCHAR(128) NOT NULL COLLATE 'utf8mb4_bin',
+    `size` BIGINT UNSIGNED NOT NULL,
+    `checksum` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `uploaded_at` DATETIME NOT NULL,
+    PRIMARY KEY (`attachment_id`) USING BTREE,
+    UNIQUE INDEX `transaction_attachments_object_key_idx` (`object_key`) USING BTREE,
+    INDEX `transaction_attachments_item_id_transaction_id_idx` (`item_id`, `transaction_id`) USING BTREE,
+    CONSTRAINT `transaction_attachments_item_id_user_items_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `ledger`.`user_items` (`item_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
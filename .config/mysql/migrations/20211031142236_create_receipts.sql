@@ -0,0 +1,22 @@
+CREATE TABLE `receipts` (
+    `receipt_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `item_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `transaction_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `object_key` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_bin',
+    `content_type` VARCHAR(128) NOT NULL COLLATE 'utf8mb4_bin',
+    `text` MEDIUMTEXT NULL DEFAULT NULL COLLATE 'utf8mb4_unicode_ci',
+    `total` DOUBLE NULL DEFAULT NULL,
+    `receipt_date` DATE NULL DEFAULT NULL,
+    `merchant_name` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_unicode_ci',
+    `amount_mismatch` TINYINT(1) NOT NULL DEFAULT 0,
+    `date_mismatch` TINYINT(1) NOT NULL DEFAULT 0,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`receipt_id`) USING BTREE,
+    UNIQUE INDEX `receipts_item_id_transaction_id_idx` (`item_id`, `transaction_id`) USING BTREE,
+    INDEX `receipts_user_id_idx` (`user_id`) USING BTREE,
+    FULLTEXT INDEX `receipts_text_merchant_name_idx` (`text`, `merchant_name`),
+    CONSTRAINT `receipts_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT `receipts_item_id_user_items_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `ledger`.`user_items` (`item_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
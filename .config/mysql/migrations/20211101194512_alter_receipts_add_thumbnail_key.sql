@@ -0,0 +1,4 @@
+ALTER TABLE
+    `receipts`
+ADD
+    COLUMN `thumbnail_key` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_bin' AFTER `object_key`;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE `account_balance_snapshots` (
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `account_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `snapshot_date` DATE NOT NULL,
+    `balance_available` DOUBLE NOT NULL DEFAULT '0',
+    `balance_current` DOUBLE NOT NULL DEFAULT '0',
+    `balance_limit` DOUBLE NOT NULL DEFAULT '0',
+    `iso_currency_code` VARCHAR(128) NOT NULL DEFAULT '' COLLATE 'utf8mb4_bin',
+    `unofficial_currency_code` VARCHAR(128) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`item_id`, `account_id`, `snapshot_date`) USING BTREE,
+    INDEX `account_balance_snapshots_snapshot_date_idx` (`snapshot_date`) USING BTREE,
+    CONSTRAINT `account_balance_snapshots_accounts_foreign` FOREIGN KEY (`item_id`, `account_id`) REFERENCES `ledger`.`accounts` (`item_id`, `account_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
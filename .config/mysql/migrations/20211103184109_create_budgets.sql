@@ -0,0 +1,16 @@
+CREATE TABLE `budgets` (
+    `budget_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `name` VARCHAR(128) NOT NULL COLLATE 'utf8mb4_unicode_ci',
+    `scope` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `scope_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `period` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `amount` DOUBLE NOT NULL,
+    `rollover` TINYINT(1) NOT NULL DEFAULT 0,
+    `start_date` DATE NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`budget_id`) USING BTREE,
+    INDEX `budgets_user_id_idx` (`user_id`) USING BTREE,
+    CONSTRAINT `budgets_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
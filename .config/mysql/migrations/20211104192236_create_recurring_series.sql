@@ -0,0 +1,20 @@
+CREATE TABLE `recurring_series` (
+    `series_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `merchant_id` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_bin',
+    `currency_code` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `frequency` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `status` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `amount` DOUBLE NOT NULL,
+    `previous_amount` DOUBLE NULL DEFAULT NULL,
+    `price_changed_at` DATE NULL DEFAULT NULL,
+    `transaction_count` INT UNSIGNED NOT NULL,
+    `first_date` DATE NOT NULL,
+    `last_date` DATE NOT NULL,
+    `next_date` DATE NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`series_id`) USING BTREE,
+    UNIQUE INDEX `recurring_series_user_id_merchant_id_currency_code_unique_idx` (`user_id`, `merchant_id`, `currency_code`) USING BTREE,
+    CONSTRAINT `recurring_series_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
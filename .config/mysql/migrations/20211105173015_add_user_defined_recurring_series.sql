@@ -0,0 +1,16 @@
+ALTER TABLE
+    `recurring_series`
+MODIFY
+    COLUMN `merchant_id` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+MODIFY
+    COLUMN `last_date` DATE NULL DEFAULT NULL,
+ADD
+    COLUMN `name` VARCHAR(255) NOT NULL DEFAULT '' COLLATE 'utf8mb4_unicode_ci' AFTER `user_id`,
+ADD
+    COLUMN `kind` VARCHAR(16) NOT NULL DEFAULT 'bill' COLLATE 'utf8mb4_bin' AFTER `name`,
+ADD
+    COLUMN `source` VARCHAR(16) NOT NULL DEFAULT 'detected' COLLATE 'utf8mb4_bin' AFTER `kind`,
+ADD
+    COLUMN `item_id` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_bin' AFTER `source`,
+ADD
+    COLUMN `account_id` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_bin' AFTER `item_id`;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE
+    `users`
+ADD
+    COLUMN `calendar_token` CHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin' AFTER `is_admin`,
+ADD
+    UNIQUE INDEX `users_calendar_token_unique_idx` (`calendar_token`) USING BTREE;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE TABLE `transaction_locations` (
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `transaction_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `address` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_unicode_ci',
+    `city` VARCHAR(128) NULL DEFAULT NULL COLLATE 'utf8mb4_unicode_ci',
+    `region` VARCHAR(128) NULL DEFAULT NULL COLLATE 'utf8mb4_unicode_ci',
+    `postal_code` VARCHAR(32) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `country` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `lat` DOUBLE NOT NULL DEFAULT 0,
+    `lon` DOUBLE NOT NULL DEFAULT 0,
+    `store_number` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`item_id`, `transaction_id`) USING BTREE,
+    CONSTRAINT `transaction_locations_item_id_user_items_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `ledger`.`user_items` (`item_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE `transaction_anomalies` (
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `transaction_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `score` DOUBLE NOT NULL,
+    `reasons` JSON NOT NULL,
+    `acknowledged_at` DATETIME NULL DEFAULT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`item_id`, `transaction_id`) USING BTREE,
+    INDEX `transaction_anomalies_acknowledged_at_idx` (`acknowledged_at`) USING BTREE,
+    CONSTRAINT `transaction_anomalies_item_id_user_items_item_id_foreign` FOREIGN KEY (`item_id`) REFERENCES `ledger`.`user_items` (`item_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE
+    `accounts`
+ADD
+    COLUMN `balance_floor` DOUBLE NULL DEFAULT NULL AFTER `recalculate_balance`;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE TABLE `account_forecasts` (
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `account_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `currency_code` VARCHAR(128) NOT NULL DEFAULT '' COLLATE 'utf8mb4_bin',
+    `current_balance` DOUBLE NOT NULL,
+    `floor` DOUBLE NOT NULL,
+    `lowest_balance` DOUBLE NOT NULL,
+    `lowest_date` DATE NOT NULL,
+    `shortfall_date` DATE NULL DEFAULT NULL,
+    `days` JSON NOT NULL,
+    `categories` JSON NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`item_id`, `account_id`) USING BTREE,
+    CONSTRAINT `account_forecasts_accounts_foreign` FOREIGN KEY (`item_id`, `account_id`) REFERENCES `ledger`.`accounts` (`item_id`, `account_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
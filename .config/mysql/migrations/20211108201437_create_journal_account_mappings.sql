@@ -0,0 +1,10 @@
+CREATE TABLE `journal_account_mappings` (
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `source_type` VARCHAR(16) NOT NULL COLLATE 'utf8mb4_bin',
+    `source_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `account_name` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_unicode_ci',
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`user_id`, `source_type`, `source_id`) USING BTREE,
+    CONSTRAINT `journal_account_mappings_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE `statement_profiles` (
+    `item_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `account_id` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `delimiter` VARCHAR(1) NOT NULL DEFAULT ',' COLLATE 'utf8mb4_bin',
+    `skip_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+    `date_column` INT UNSIGNED NOT NULL,
+    `date_format` VARCHAR(8) NOT NULL COLLATE 'utf8mb4_bin',
+    `description_column` INT UNSIGNED NOT NULL,
+    `amount_column` INT UNSIGNED NULL DEFAULT NULL,
+    `debit_column` INT UNSIGNED NULL DEFAULT NULL,
+    `credit_column` INT UNSIGNED NULL DEFAULT NULL,
+    `id_column` INT UNSIGNED NULL DEFAULT NULL,
+    `invert_amounts` TINYINT(1) NOT NULL DEFAULT 0,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`item_id`, `account_id`) USING BTREE,
+    CONSTRAINT `statement_profiles_accounts_foreign` FOREIGN KEY (`item_id`, `account_id`) REFERENCES `ledger`.`accounts` (`item_id`, `account_id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
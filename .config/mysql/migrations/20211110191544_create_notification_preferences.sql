@@ -0,0 +1,10 @@
+CREATE TABLE `notification_preferences` (
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `new_transactions` TINYINT(1) NOT NULL DEFAULT 1,
+    `digest` VARCHAR(16) NOT NULL DEFAULT 'none' COLLATE 'utf8mb4_bin',
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`user_id`) USING BTREE,
+    INDEX `notification_preferences_digest_idx` (`digest`) USING BTREE,
+    CONSTRAINT `notification_preferences_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE `alert_rules` (
+    `rule_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `name` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_unicode_ci',
+    `type` VARCHAR(32) NOT NULL COLLATE 'utf8mb4_bin',
+    `threshold` DOUBLE NOT NULL,
+    `item_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `account_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `budget_id` CHAR(36) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `cooldown_minutes` INT(10) UNSIGNED NOT NULL DEFAULT 0,
+    `enabled` TINYINT(1) NOT NULL DEFAULT 1,
+    `last_fired_at` DATETIME NULL DEFAULT NULL,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`rule_id`) USING BTREE,
+    INDEX `alert_rules_user_id_idx` (`user_id`) USING BTREE,
+    CONSTRAINT `alert_rules_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE `alerts` (
+    `alert_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `rule_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `type` VARCHAR(32) NOT NULL COLLATE 'utf8mb4_bin',
+    `alert_key` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_bin',
+    `message` VARCHAR(1024) NOT NULL COLLATE 'utf8mb4_unicode_ci',
+    `item_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `account_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `transaction_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `budget_id` CHAR(36) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `amount` DOUBLE NULL DEFAULT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (`alert_id`) USING BTREE,
+    UNIQUE INDEX `alerts_rule_id_alert_key_idx` (`rule_id`, `alert_key`) USING BTREE,
+    INDEX `alerts_user_id_created_at_idx` (`user_id`, `created_at`) USING BTREE,
+    CONSTRAINT `alerts_rule_id_alert_rules_rule_id_foreign` FOREIGN KEY (`rule_id`) REFERENCES `ledger`.`alert_rules` (`rule_id`) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT `alerts_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
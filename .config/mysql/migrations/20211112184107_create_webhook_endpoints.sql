@@ -0,0 +1,14 @@
+CREATE TABLE `webhook_endpoints` (
+    `endpoint_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `url` VARCHAR(2048) NOT NULL COLLATE 'utf8mb4_bin',
+    `description` VARCHAR(255) NULL DEFAULT NULL COLLATE 'utf8mb4_unicode_ci',
+    `secret` VARCHAR(128) NOT NULL COLLATE 'utf8mb4_bin',
+    `event_types` JSON NOT NULL,
+    `enabled` TINYINT(1) NOT NULL DEFAULT 1,
+    `created_at` DATETIME NOT NULL,
+    `updated_at` DATETIME NOT NULL,
+    PRIMARY KEY (`endpoint_id`) USING BTREE,
+    INDEX `webhook_endpoints_user_id_idx` (`user_id`) USING BTREE,
+    CONSTRAINT `webhook_endpoints_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
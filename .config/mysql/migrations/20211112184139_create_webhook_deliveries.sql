@@ -0,0 +1,18 @@
+CREATE TABLE `webhook_deliveries` (
+    `delivery_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `endpoint_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `event_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `event_type` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `attempt` INT(10) UNSIGNED NOT NULL,
+    `succeeded` TINYINT(1) NOT NULL,
+    `status_code` INT(10) NULL DEFAULT NULL,
+    `error` VARCHAR(1024) NULL DEFAULT NULL COLLATE 'utf8mb4_unicode_ci',
+    `duration_ms` INT(10) UNSIGNED NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (`delivery_id`) USING BTREE,
+    INDEX `webhook_deliveries_endpoint_id_created_at_idx` (`endpoint_id`, `created_at`) USING BTREE,
+    INDEX `webhook_deliveries_user_id_idx` (`user_id`) USING BTREE,
+    CONSTRAINT `webhook_deliveries_endpoint_id_foreign` FOREIGN KEY (`endpoint_id`) REFERENCES `ledger`.`webhook_endpoints` (`endpoint_id`) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT `webhook_deliveries_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE `notifications` (
+    `notification_id` CHAR(36) NOT NULL COLLATE 'utf8mb4_bin',
+    `user_id` CHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `type` VARCHAR(64) NOT NULL COLLATE 'utf8mb4_bin',
+    `title` VARCHAR(255) NOT NULL COLLATE 'utf8mb4_unicode_ci',
+    `message` VARCHAR(1024) NOT NULL COLLATE 'utf8mb4_unicode_ci',
+    `item_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `reference_id` VARCHAR(64) NULL DEFAULT NULL COLLATE 'utf8mb4_bin',
+    `read_at` DATETIME NULL DEFAULT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (`notification_id`) USING BTREE,
+    INDEX `notifications_user_id_created_at_idx` (`user_id`, `created_at`) USING BTREE,
+    INDEX `notifications_user_id_read_at_idx` (`user_id`, `read_at`) USING BTREE,
+    CONSTRAINT `notifications_user_id_users_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `ledger`.`users` (`id`) ON UPDATE CASCADE ON DELETE CASCADE
+) COLLATE = 'utf8mb4_bin' ENGINE = InnoDB;
\ No newline at end of file
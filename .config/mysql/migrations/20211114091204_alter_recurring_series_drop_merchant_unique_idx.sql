@@ -0,0 +1,6 @@
+ALTER TABLE
+    `recurring_series`
+DROP INDEX
+    `recurring_series_user_id_merchant_id_currency_code_unique_idx`,
+ADD
+    INDEX `recurring_series_user_id_merchant_id_currency_code_idx` (`user_id`, `merchant_id`, `currency_code`) USING BTREE;
\ No newline at end of file
@@ -17,8 +17,17 @@ type AccountRepository interface {
 	CreateAccount(ctx context.Context, account *Account) (*Account, error)
 	UpdateAccount(ctx context.Context, itemID, accountID string, account *Account) (*Account, error)
 	DeleteAccount(ctx context.Context, itemID, accountID string) error
+	UpdateAccountBalanceFloor(ctx context.Context, itemID, accountID string, floor null.Float64) error
+
+	AccountBalanceSnapshots(ctx context.Context, itemID, accountID string, from, to time.Time) ([]*AccountBalanceSnapshot, error)
+	AccountBalanceSnapshotsByUserID(ctx context.Context, userID uuid.UUID, to time.Time) ([]*AccountBalanceSnapshot, error)
+	SaveAccountBalanceSnapshot(ctx context.Context, snapshot *AccountBalanceSnapshot) error
+	SnapshotAccountBalances(ctx context.Context, date time.Time) error
 }
 
+// Account is an account held at the institution of an item. BalanceFloor is the balance the user does
+// not want the account to fall below, forecasts warn when it is expected to. It is set by the user
+// and is never changed by an import
 type Account struct {
 	ItemID             string          `db:"item_id" json:"itemID"`
 	AccountID          string          `db:"account_id" json:"accountID"`
@@ -29,6 +38,7 @@ type Account struct {
 	Subtype            null.String     `db:"subtype" json:"subtype"`
 	Type               null.String     `db:"type" json:"type"`
 	RecalculateBalance bool            `db:"recalculate_balance" json:"recalculateBalance"`
+	BalanceFloor       null.Float64    `db:"balance_floor" json:"balanceFloor"`
 	CreatedAt          time.Time       `db:"created_at" json:"createdAt"`
 	UpdatedAt          time.Time       `db:"updated_at" json:"updated_at"`
 }
@@ -63,3 +73,37 @@ type AccountBalance struct {
 	UnofficialCurrencyCode null.String `db:"unofficial_currency_code" json:"unofficialCurrencyCode"`
 	LastUpdated            null.Time   `db:"last_updated_datetime" json:"lastUpdatedDatetime"`
 }
+
+// CurrencyCode returns the ISO currency code of the balance, falling back to the
+// unofficial code Plaid reports for currencies such as cryptocurrencies
+func (b *AccountBalance) CurrencyCode() string {
+	if b.ISOCurrencyCode != "" {
+		return b.ISOCurrencyCode
+	}
+
+	return b.UnofficialCurrencyCode.String
+}
+
+// AccountBalanceSnapshot is the balance of an account at the end of a day. Balances are
+// overwritten on the account every time it is refreshed, snapshots preserve their history
+type AccountBalanceSnapshot struct {
+	ItemID                 string      `db:"item_id" json:"itemID"`
+	AccountID              string      `db:"account_id" json:"accountID"`
+	Date                   time.Time   `db:"snapshot_date" json:"date"`
+	Available              float64     `db:"balance_available" json:"available"`
+	Current                float64     `db:"balance_current" json:"current"`
+	Limit                  float64     `db:"balance_limit" json:"limit"`
+	ISOCurrencyCode        string      `db:"iso_currency_code" json:"isoCurrencyCode"`
+	UnofficialCurrencyCode null.String `db:"unofficial_currency_code" json:"unofficialCurrencyCode"`
+	CreatedAt              time.Time   `db:"created_at" json:"createdAt"`
+	UpdatedAt              time.Time   `db:"updated_at" json:"updatedAt"`
+}
+
+// CurrencyCode returns the ISO currency code of the snapshot, falling back to the unofficial code
+func (s *AccountBalanceSnapshot) CurrencyCode() string {
+	if s.ISOCurrencyCode != "" {
+		return s.ISOCurrencyCode
+	}
+
+	return s.UnofficialCurrencyCode.String
+}
@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+type AlertRepository interface {
+	AlertRule(ctx context.Context, userID uuid.UUID, ruleID string) (*AlertRule, error)
+	AlertRules(ctx context.Context, userID uuid.UUID) ([]*AlertRule, error)
+	CreateAlertRule(ctx context.Context, rule *AlertRule) (*AlertRule, error)
+	UpdateAlertRule(ctx context.Context, ruleID string, rule *AlertRule) (*AlertRule, error)
+	UpdateAlertRuleFiredAt(ctx context.Context, ruleID string, firedAt time.Time) error
+	DeleteAlertRule(ctx context.Context, userID uuid.UUID, ruleID string) error
+
+	Alert(ctx context.Context, userID uuid.UUID, alertID string) (*Alert, error)
+	AlertByKey(ctx context.Context, ruleID, key string) (*Alert, error)
+	Alerts(ctx context.Context, userID uuid.UUID, limit uint64) ([]*Alert, error)
+	CreateAlert(ctx context.Context, alert *Alert) (*Alert, error)
+}
+
+// AlertRuleType is the condition an alert rule fires on
+type AlertRuleType string
+
+const (
+	// AlertRuleTypeLargeTransaction fires for a new transaction whose amount, in or out, is at least
+	// the threshold
+	AlertRuleTypeLargeTransaction AlertRuleType = "large_transaction"
+	// AlertRuleTypeLowBalance fires when the current balance of the account of the rule is below
+	// the threshold
+	AlertRuleTypeLowBalance AlertRuleType = "low_balance"
+	// AlertRuleTypeBudget fires when the budget of the rule has used at least the threshold, as a
+	// percentage, of its current period
+	AlertRuleTypeBudget AlertRuleType = "budget"
+	// AlertRuleTypeNewMerchant fires for a new transaction at a merchant the user has not had a
+	// transaction with before
+	AlertRuleTypeNewMerchant AlertRuleType = "new_merchant"
+)
+
+func (t AlertRuleType) Valid() bool {
+	switch t {
+	case AlertRuleTypeLargeTransaction, AlertRuleTypeLowBalance, AlertRuleTypeBudget, AlertRuleTypeNewMerchant:
+		return true
+	}
+
+	return false
+}
+
+// AlertRule is a condition a user wants to be alerted to. Rules are evaluated after every import.
+// ItemID and AccountID limit a rule to the transactions of an account, and are required for low
+// balance rules. A rule that has fired does not fire again until CooldownMinutes have passed
+type AlertRule struct {
+	RuleID          string        `db:"rule_id" json:"ruleID"`
+	UserID          uuid.UUID     `db:"user_id" json:"userID"`
+	Name            string        `db:"name" json:"name"`
+	Type            AlertRuleType `db:"type" json:"type"`
+	Threshold       float64       `db:"threshold" json:"threshold"`
+	ItemID          null.String   `db:"item_id" json:"itemID"`
+	AccountID       null.String   `db:"account_id" json:"accountID"`
+	BudgetID        null.String   `db:"budget_id" json:"budgetID"`
+	CooldownMinutes uint          `db:"cooldown_minutes" json:"cooldownMinutes"`
+	Enabled         bool          `db:"enabled" json:"enabled"`
+	LastFiredAt     null.Time     `db:"last_fired_at" json:"lastFiredAt"`
+	CreatedAt       time.Time     `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time     `db:"updated_at" json:"updatedAt"`
+}
+
+// CoolingDown returns whether the rule fired too recently to fire again at now
+func (r *AlertRule) CoolingDown(now time.Time) bool {
+	return r.LastFiredAt.Valid && now.Before(r.LastFiredAt.Time.Add(time.Duration(r.CooldownMinutes)*time.Minute))
+}
+
+// Alert is a record of an alert rule firing. Key identifies what the rule fired for, such as a
+// transaction or a budget period, so that a rule never fires twice for the same thing
+type Alert struct {
+	AlertID       string        `db:"alert_id" json:"alertID"`
+	RuleID        string        `db:"rule_id" json:"ruleID"`
+	UserID        uuid.UUID     `db:"user_id" json:"userID"`
+	Type          AlertRuleType `db:"type" json:"type"`
+	Key           string        `db:"alert_key" json:"key"`
+	Message       string        `db:"message" json:"message"`
+	ItemID        null.String   `db:"item_id" json:"itemID"`
+	AccountID     null.String   `db:"account_id" json:"accountID"`
+	TransactionID null.String   `db:"transaction_id" json:"transactionID"`
+	BudgetID      null.String   `db:"budget_id" json:"budgetID"`
+	Amount        null.Float64  `db:"amount" json:"amount"`
+	CreatedAt     time.Time     `db:"created_at" json:"createdAt"`
+}
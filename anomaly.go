@@ -0,0 +1,93 @@
+package ledger
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+type AnomalyRepository interface {
+	TransactionAnomaly(ctx context.Context, itemID, transactionID string) (*TransactionAnomaly, error)
+	TransactionAnomaliesByUserID(ctx context.Context, userID uuid.UUID, acknowledged null.Bool) ([]*TransactionAnomaly, error)
+	SaveTransactionAnomaly(ctx context.Context, anomaly *TransactionAnomaly) error
+	AcknowledgeTransactionAnomaly(ctx context.Context, itemID, transactionID string) error
+	AnomalyBaseline(ctx context.Context, transaction *Transaction, from time.Time) (*AnomalyBaseline, error)
+}
+
+// AnomalyBaseline summarises the posted outflows made by the owner of a transaction, across all
+// of their items, before it. Means and standard deviations are of the absolute amount and are null
+// when there are too few transactions to calculate them. Location counts only include transactions
+// that have a country
+type AnomalyBaseline struct {
+	HistoryCount   int64        `db:"history_count"`
+	MerchantCount  int64        `db:"merchant_count"`
+	MerchantMean   null.Float64 `db:"merchant_mean"`
+	MerchantStdDev null.Float64 `db:"merchant_stddev"`
+	CategoryCount  int64        `db:"category_count"`
+	CategoryMean   null.Float64 `db:"category_mean"`
+	CategoryStdDev null.Float64 `db:"category_stddev"`
+	LocatedCount   int64        `db:"located_count"`
+	CountryCount   int64        `db:"country_count"`
+	RegionCount    int64        `db:"region_count"`
+}
+
+type AnomalyReasonType string
+
+const (
+	AnomalyReasonAmount      AnomalyReasonType = "amount"
+	AnomalyReasonNewMerchant AnomalyReasonType = "new_merchant"
+	AnomalyReasonLocation    AnomalyReasonType = "location"
+)
+
+// AnomalyReason is one of the ways a transaction differs from the history of the user and how
+// much it contributed to the score of the transaction
+type AnomalyReason struct {
+	Type        AnomalyReasonType `json:"type"`
+	Score       float64           `json:"score"`
+	Description string            `json:"description"`
+}
+
+type AnomalyReasons []*AnomalyReason
+
+func (r AnomalyReasons) Value() (driver.Value, error) {
+
+	if len(r) == 0 {
+		return []byte(`[]`), nil
+	}
+
+	return json.Marshal(r)
+
+}
+
+func (r *AnomalyReasons) Scan(value interface{}) error {
+
+	switch data := value.(type) {
+	case []byte:
+		err := json.Unmarshal(data, r)
+		if err != nil {
+			return fmt.Errorf("failed to scan string into AnomalyReasons: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to scan value into AnomalyReasons: unsupported type %T", value)
+	}
+
+	return nil
+
+}
+
+// TransactionAnomaly is a transaction that scored above the anomaly threshold. Score is the sum of
+// the scores of its reasons. It stays flagged until the user acknowledges it
+type TransactionAnomaly struct {
+	ItemID         string         `db:"item_id" json:"itemID"`
+	TransactionID  string         `db:"transaction_id" json:"transactionID"`
+	Score          float64        `db:"score" json:"score"`
+	Reasons        AnomalyReasons `db:"reasons" json:"reasons"`
+	AcknowledgedAt null.Time      `db:"acknowledged_at" json:"acknowledgedAt"`
+	CreatedAt      time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updatedAt"`
+}
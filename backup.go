@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type BackupRepository interface {
+	// BackupTables returns the tables that hold the data of a user, ordered so that every table comes
+	// after the tables it references
+	BackupTables() []string
+	BackupRows(ctx context.Context, table string, userID uuid.UUID, includeAccessTokens bool, fn func(row BackupRow) error) error
+	RestoreRows(ctx context.Context, table string, rows []BackupRow) error
+}
+
+// BackupVersion is the version of the archive layout written by a backup. Restores refuse archives
+// written with a different version
+const BackupVersion = 1
+
+// BackupRow is a row of a table in a backup, keyed by column name
+type BackupRow map[string]interface{}
+
+// BackupManifest describes a backup archive. It is the first entry of the archive so that a restore
+// can check the archive before it changes anything. Tables are listed in the order they are restored in
+type BackupManifest struct {
+	Version              int       `json:"version"`
+	UserID               uuid.UUID `json:"userID"`
+	CreatedAt            time.Time `json:"createdAt"`
+	IncludesAccessTokens bool      `json:"includesAccessTokens"`
+	Tables               []string  `json:"tables"`
+}
+
+// BackupStats counts the rows of each table and the blobs a backup wrote or a restore read
+type BackupStats struct {
+	Manifest *BackupManifest
+	Rows     map[string]int
+	Blobs    int
+}
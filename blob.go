@@ -0,0 +1,20 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore persists the files, such as receipts and attachments, that users upload
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns the contents of the blob. The caller is responsible for closing the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a url that grants read access to the blob until expires has elapsed
+	SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
@@ -0,0 +1,62 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+type BudgetRepository interface {
+	Budget(ctx context.Context, userID uuid.UUID, budgetID string) (*Budget, error)
+	Budgets(ctx context.Context, userID uuid.UUID) ([]*Budget, error)
+	CreateBudget(ctx context.Context, budget *Budget) (*Budget, error)
+	UpdateBudget(ctx context.Context, budgetID string, budget *Budget) (*Budget, error)
+	DeleteBudget(ctx context.Context, userID uuid.UUID, budgetID string) error
+}
+
+// BudgetScope is what the transactions counted against a budget are matched on
+type BudgetScope string
+
+const (
+	BudgetScopeCategory BudgetScope = "category"
+	BudgetScopeMerchant BudgetScope = "merchant"
+)
+
+func (s BudgetScope) Valid() bool {
+	return s == BudgetScopeCategory || s == BudgetScopeMerchant
+}
+
+// Budget limits the spending in a category or at a merchant each period. Amount is in the base
+// currency of the user. When Rollover is set, whatever is left of the budget at the end of a
+// period is added to the next one, and overspending is taken out of it
+type Budget struct {
+	BudgetID  string      `db:"budget_id" json:"budgetID"`
+	UserID    uuid.UUID   `db:"user_id" json:"userID"`
+	Name      string      `db:"name" json:"name"`
+	Scope     BudgetScope `db:"scope" json:"scope"`
+	ScopeID   string      `db:"scope_id" json:"scopeID"`
+	Period    Interval    `db:"period" json:"period"`
+	Amount    float64     `db:"amount" json:"amount"`
+	Rollover  bool        `db:"rollover" json:"rollover"`
+	StartDate time.Time   `db:"start_date" json:"startDate"`
+	CreatedAt time.Time   `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time   `db:"updated_at" json:"updatedAt"`
+}
+
+// BudgetStatus is the progress of a budget through a single period. Limit is the amount of the
+// budget plus anything rolled over from earlier periods. PercentUsed is null when there is nothing
+// left to spend because earlier periods were overspent. Unconverted is the number of transactions
+// of the period left out of Spent because there is no exchange rate for their currency on their date
+type BudgetStatus struct {
+	Budget      *Budget      `json:"budget"`
+	PeriodStart time.Time    `json:"periodStart"`
+	PeriodEnd   time.Time    `json:"periodEnd"`
+	Rollover    float64      `json:"rollover"`
+	Limit       float64      `json:"limit"`
+	Spent       float64      `json:"spent"`
+	Remaining   float64      `json:"remaining"`
+	PercentUsed null.Float64 `json:"percentUsed"`
+	Unconverted int64        `json:"unconverted"`
+}
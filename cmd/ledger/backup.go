@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/ddouglas/ledger/internal/backup"
+	"github.com/gofrs/uuid"
+	"github.com/urfave/cli/v2"
+)
+
+func actionBackup(c *cli.Context) error {
+
+	core := buildCore()
+
+	entry := core.logger.WithField("user", c.String("user")).WithField("output", c.String("output"))
+
+	userID, err := uuid.FromString(c.String("user"))
+	if err != nil {
+		entry.WithError(err).Fatal("invalid user id")
+	}
+
+	ctx := context.Background()
+
+	_, err = core.repos.user.User(ctx, userID)
+	if err != nil {
+		entry.WithError(err).Fatal("failed to fetch user")
+	}
+
+	f, err := os.Create(c.String("output"))
+	if err != nil {
+		entry.WithError(err).Fatal("failed to create output file")
+	}
+	defer f.Close()
+
+	backup := backup.New(core.blobs, core.repos.backup)
+
+	stats, err := backup.Backup(ctx, f, userID, c.Bool("include-access-tokens"))
+	if err != nil {
+		entry.WithError(err).Fatal("failed to back up user")
+	}
+
+	err = f.Close()
+	if err != nil {
+		entry.WithError(err).Fatal("failed to close output file")
+	}
+
+	entry.WithField("rows", stats.Rows).WithField("blobs", stats.Blobs).Info("backup complete")
+
+	return nil
+
+}
+
+func actionRestore(c *cli.Context) error {
+
+	core := buildCore()
+
+	entry := core.logger.WithField("input", c.String("input"))
+
+	f, err := os.Open(c.String("input"))
+	if err != nil {
+		entry.WithError(err).Fatal("failed to open input file")
+	}
+	defer f.Close()
+
+	backup := backup.New(core.blobs, core.repos.backup)
+
+	stats, err := backup.Restore(context.Background(), f)
+	if err != nil {
+		entry.WithError(err).Fatal("failed to restore backup")
+	}
+
+	entry.WithField("user", stats.Manifest.UserID).WithField("rows", stats.Rows).WithField("blobs", stats.Blobs).Info("restore complete")
+
+	return nil
+
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/ddouglas/ledger/internal/currency"
+	"github.com/urfave/cli/v2"
+)
+
+func actionImportExchangeRates(c *cli.Context) error {
+
+	core := buildCore()
+
+	filename := c.String("file")
+	entry := core.logger.WithField("file", filename)
+
+	f, err := os.Open(filename)
+	if err != nil {
+		entry.WithError(err).Fatal("failed to open exchange rate file")
+	}
+	defer f.Close()
+
+	currency := currency.New(core.repos.exchangeRate)
+
+	count, err := currency.ImportExchangeRates(context.Background(), f)
+	if err != nil {
+		entry.WithError(err).Fatal("failed to import exchange rates")
+	}
+
+	entry.WithField("count", count).Info("exchange rates imported successfully")
+	return nil
+
+}
@@ -51,11 +51,40 @@ type config struct {
 
 	UserRegistrationEnabled bool `envconfig:"USER_REGISTRATION_ENABLED" required:"true"`
 
+	// RefundMatchWindowDays is how many days before a refund we look for the charge it reverses
+	RefundMatchWindowDays uint `envconfig:"REFUND_MATCH_WINDOW_DAYS" default:"30"`
+
+	// AnomalyThreshold is the score at which a transaction is flagged as unusual. Each reason a
+	// transaction is unusual scores between 0 and 1
+	AnomalyThreshold float64 `envconfig:"ANOMALY_THRESHOLD" default:"0.6"`
+
+	Blob struct {
+		// Driver selects where receipts and attachments are stored, either s3 or local
+		Driver string `envconfig:"BLOB_DRIVER" default:"s3"`
+		// LocalPath is the directory the local driver writes files to
+		LocalPath string `envconfig:"BLOB_LOCAL_PATH" default:".data/blobs"`
+		// BaseURL is the public url of the /blobs route that serves files written by the local driver.
+		// It defaults to the API port on localhost
+		BaseURL string `envconfig:"BLOB_BASE_URL"`
+		// SigningKey signs the expiring download urls generated by the local driver
+		SigningKey string `envconfig:"BLOB_SIGNING_KEY"`
+	}
+
+	SMTP struct {
+		// Host is the SMTP server notifications are sent through. Notifications are not sent when it is empty
+		Host     string `envconfig:"SMTP_HOST"`
+		Port     uint   `envconfig:"SMTP_PORT" default:"587"`
+		Username string `envconfig:"SMTP_USERNAME"`
+		Password string `envconfig:"SMTP_PASSWORD"`
+		// From is the address notifications are sent from, optionally with a display name
+		From string `envconfig:"SMTP_FROM" default:"Ledger <ledger@localhost>"`
+	}
+
 	S3 struct {
-		// ClientID     string `envconfig:"SPACES_CLIENT_ID" required:"true"`
-		// ClientSecret string `envconfig:"SPACES_CLIENT_SECRET" required:"true"`
-		// Endpoint     string `envconfig:"SPACES_ENDPOINT" required:"true"`
-		Bucket string `envconfig:"S3_BUCKET_NAME" required:"true"`
+		Bucket       string `envconfig:"S3_BUCKET_NAME"`
+		Region       string `envconfig:"S3_REGION"`
+		Endpoint     string `envconfig:"S3_ENDPOINT"`
+		UsePathStyle bool   `envconfig:"S3_USE_PATH_STYLE"`
 	}
 }
 
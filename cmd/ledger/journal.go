@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/journal"
+	"github.com/urfave/cli/v2"
+	"github.com/volatiletech/null"
+)
+
+func actionExportJournal(c *cli.Context) error {
+
+	core := buildCore()
+
+	format := ledger.JournalFormat(c.String("format"))
+	entry := core.logger.WithField("format", format)
+	if !format.Valid() {
+		entry.Fatal("unsupported format, expected one of ledger, hledger or beancount")
+	}
+
+	ctx := context.Background()
+
+	user, err := core.repos.user.UserByEmail(ctx, c.String("user"))
+	if err != nil {
+		entry.WithError(err).Fatal("failed to fetch user")
+	}
+
+	filters := &ledger.TransactionFilter{
+		DateInclusive: null.BoolFrom(true),
+	}
+	if start := c.Timestamp("start"); start != nil {
+		filters.StartDate = null.TimeFrom(*start)
+	}
+	if end := c.Timestamp("end"); end != nil {
+		filters.EndDate = null.TimeFrom(*end)
+	}
+
+	var w io.Writer = os.Stdout
+	if filename := c.String("output"); filename != "" {
+		entry = entry.WithField("output", filename)
+
+		f, err := os.Create(filename)
+		if err != nil {
+			entry.WithError(err).Fatal("failed to create output file")
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	journal := journal.New(core.repos.account, core.repos.transaction, core.repos.journal)
+
+	err = journal.WriteJournal(ctx, w, user.ID, format, filters)
+	if err != nil {
+		entry.WithError(err).Fatal("failed to export journal")
+	}
+
+	return nil
+
+}
@@ -16,16 +16,31 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/ddouglas/ledger"
 	"github.com/ddouglas/ledger/internal/account"
+	"github.com/ddouglas/ledger/internal/alert"
+	"github.com/ddouglas/ledger/internal/anomaly"
 	"github.com/ddouglas/ledger/internal/auth"
+	"github.com/ddouglas/ledger/internal/blob"
+	"github.com/ddouglas/ledger/internal/budget"
 	"github.com/ddouglas/ledger/internal/cache"
+	"github.com/ddouglas/ledger/internal/currency"
+	"github.com/ddouglas/ledger/internal/event"
+	"github.com/ddouglas/ledger/internal/export"
+	"github.com/ddouglas/ledger/internal/forecast"
 	"github.com/ddouglas/ledger/internal/gateway"
 	"github.com/ddouglas/ledger/internal/importer"
 	"github.com/ddouglas/ledger/internal/item"
+	"github.com/ddouglas/ledger/internal/journal"
+	"github.com/ddouglas/ledger/internal/mail"
 	"github.com/ddouglas/ledger/internal/mysql"
+	"github.com/ddouglas/ledger/internal/notification"
+	"github.com/ddouglas/ledger/internal/recurring"
+	"github.com/ddouglas/ledger/internal/report"
 	"github.com/ddouglas/ledger/internal/server"
 	"github.com/ddouglas/ledger/internal/server/gql/dataloaders"
+	"github.com/ddouglas/ledger/internal/statement"
 	"github.com/ddouglas/ledger/internal/transaction"
 	"github.com/ddouglas/ledger/internal/user"
+	"github.com/ddouglas/ledger/internal/webhook"
 	"github.com/go-redis/redis/v8"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/plaid/plaid-go/plaid"
@@ -49,20 +64,33 @@ type core struct {
 	newrelic *newrelic.Application
 	repos    *repositories
 	gateway  gateway.Service
-	s3       *s3.Client
+	blobs    ledger.BlobStore
+	mailer   ledger.Mailer
 }
 
 type repositories struct {
-	starter     ledger.Starter
-	account     ledger.AccountRepository
-	health      ledger.HealthRepository
-	item        ledger.ItemRepository
-	migrations  ledger.MigrationRepository
-	plaid       ledger.PlaidRepository
-	transaction ledger.TransactionRepository
-	user        ledger.UserRepository
-	webhook     ledger.WebhookRepository
-	merchant    ledger.MerchantRepository
+	starter         ledger.Starter
+	account         ledger.AccountRepository
+	health          ledger.HealthRepository
+	item            ledger.ItemRepository
+	migrations      ledger.MigrationRepository
+	plaid           ledger.PlaidRepository
+	transaction     ledger.TransactionRepository
+	user            ledger.UserRepository
+	webhook         ledger.WebhookRepository
+	merchant        ledger.MerchantRepository
+	exchangeRate    ledger.ExchangeRateRepository
+	receipt         ledger.ReceiptRepository
+	budget          ledger.BudgetRepository
+	recurring       ledger.RecurringRepository
+	anomaly         ledger.AnomalyRepository
+	forecast        ledger.ForecastRepository
+	journal         ledger.JournalRepository
+	statement       ledger.StatementRepository
+	backup          ledger.BackupRepository
+	alert           ledger.AlertRepository
+	notification    ledger.NotificationRepository
+	webhookEndpoint ledger.WebhookEndpointRepository
 }
 
 func init() {
@@ -104,6 +132,88 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:  "exchange-rates",
+			Usage: "Manage the exchange rates used to convert amounts to a users base currency",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "import",
+					Usage:  "import exchange rates from a csv file with the columns date, from, to, rate",
+					Action: actionImportExchangeRates,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "file",
+							Required: true,
+							Usage:    "path to the csv file to import",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "export",
+			Usage:  "export the transactions of a user as a plain text accounting journal",
+			Action: actionExportJournal,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "format",
+					Required: true,
+					Usage:    "the format of the journal, one of ledger, hledger or beancount",
+				},
+				&cli.StringFlag{
+					Name:     "user",
+					Required: true,
+					Usage:    "the email address of the user whose transactions are exported",
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "path of the file to write the journal to, the journal is written to stdout when not set",
+				},
+				&cli.TimestampFlag{
+					Name:   "start",
+					Layout: "2006-01-02",
+					Usage:  "only export transactions on or after this date, formatted as YYYY-MM-DD",
+				},
+				&cli.TimestampFlag{
+					Name:   "end",
+					Layout: "2006-01-02",
+					Usage:  "only export transactions on or before this date, formatted as YYYY-MM-DD",
+				},
+			},
+		},
+		{
+			Name:   "backup",
+			Usage:  "write an archive of everything stored for a user, including their receipts and attachments",
+			Action: actionBackup,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "user",
+					Required: true,
+					Usage:    "the id of the user to back up",
+				},
+				&cli.StringFlag{
+					Name:     "output",
+					Required: true,
+					Usage:    "path of the file to write the archive to",
+				},
+				&cli.BoolFlag{
+					Name:  "include-access-tokens",
+					Usage: "include the Plaid access tokens of the users items and the signing secrets of their webhook endpoints in the archive",
+				},
+			},
+		},
+		{
+			Name:   "restore",
+			Usage:  "restore an archive written by backup, restoring the same archive more than once is safe",
+			Action: actionRestore,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "input",
+					Required: true,
+					Usage:    "path of the archive to restore",
+				},
+			},
+		},
 	}
 
 	err := app.Run(os.Args)
@@ -124,39 +234,79 @@ func buildCore() *core {
 		newrelic: nr,
 		repos:    buildRepositories(),
 		gateway:  buildGateway(r, nr, repos),
-		s3:       buildS3(),
+		blobs:    buildBlobStore(),
+		mailer:   buildMailer(),
 	}
 }
 
 func buildAWSConfig() aws.Config {
-	awsConf, err := awsConfig.LoadDefaultConfig(
-		context.TODO(),
-		// awsConfig.WithCredentialsProvider(
-		// 	credentials.NewStaticCredentialsProvider(
-		// 		cfg.Spaces.ClientID,
-		// 		cfg.Spaces.ClientSecret,
-		// 		"",
-		// 	),
-		// ),
-		// awsConfig.WithEndpointResolver(aws.EndpointResolverFunc(func(service, region string) (aws.Endpoint, error) {
-		// 	if service == s3.ServiceID {
-		// 		return aws.Endpoint{
-		// 			URL: cfg.Spaces.Endpoint,
-		// 		}, nil
-		// 	}
-
-		// 	return aws.Endpoint{}, nil
-		// })),
-	)
+	awsConf, err := awsConfig.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		panic(fmt.Sprintf("failed to load aws configuration: %s", err))
 	}
 
+	if cfg.S3.Region != "" {
+		awsConf.Region = cfg.S3.Region
+	}
+
 	return awsConf
 }
 
 func buildS3() *s3.Client {
-	return s3.NewFromConfig(buildAWSConfig())
+	return s3.NewFromConfig(buildAWSConfig(), func(o *s3.Options) {
+		// MinIO and DigitalOcean Spaces expose S3 compatible APIs at their own endpoints
+		if cfg.S3.Endpoint != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(cfg.S3.Endpoint)
+		}
+		o.UsePathStyle = cfg.S3.UsePathStyle
+	})
+}
+
+func buildBlobStore() ledger.BlobStore {
+
+	switch cfg.Blob.Driver {
+	case "local":
+		baseURL := cfg.Blob.BaseURL
+		if baseURL == "" {
+			baseURL = fmt.Sprintf("http://localhost:%d/blobs", cfg.API.Port)
+		}
+
+		store, err := blob.NewLocalStore(cfg.Blob.LocalPath, baseURL, []byte(cfg.Blob.SigningKey))
+		if err != nil {
+			logger.WithError(err).Panic("failed to configure local blob store")
+		}
+
+		return store
+	case "s3":
+		if cfg.S3.Bucket == "" {
+			logger.Panic("S3_BUCKET_NAME is required when using the s3 blob driver")
+		}
+
+		return blob.NewS3Store(buildS3(), cfg.S3.Bucket)
+	default:
+		logger.Panicf("unsupported blob driver %s, expected one of s3 or local", cfg.Blob.Driver)
+	}
+
+	return nil
+
+}
+
+// buildMailer returns the mailer notifications are sent through, or nil when SMTP_HOST is not set,
+// which turns notifications off
+func buildMailer() ledger.Mailer {
+
+	if cfg.SMTP.Host == "" {
+		logger.Info("SMTP_HOST is not set, notifications will not be sent")
+		return nil
+	}
+
+	mailer, err := mail.NewSMTPMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+	if err != nil {
+		logger.WithError(err).Panic("failed to configure smtp mailer")
+	}
+
+	return mailer
+
 }
 
 func buildNewRelic() *newrelic.Application {
@@ -232,16 +382,28 @@ func buildRepositories() *repositories {
 	dbx = sqlx.NewDb(db, "mysql")
 
 	return &repositories{
-		starter:     mysql.NewTransactioner(dbx),
-		account:     mysql.NewAccountRepository(dbx),
-		health:      mysql.NewHealthRepository(dbx),
-		item:        mysql.NewItemRepository(dbx),
-		migrations:  mysql.NewMigrationRepostory(dbx),
-		plaid:       mysql.NewPlaidRepository(dbx),
-		transaction: mysql.NewTransactionRepository(dbx),
-		user:        mysql.NewUserRepository(dbx),
-		webhook:     mysql.NewWebhookRepository(dbx),
-		merchant:    mysql.NewMerchantRepository(dbx),
+		starter:         mysql.NewTransactioner(dbx),
+		account:         mysql.NewAccountRepository(dbx),
+		health:          mysql.NewHealthRepository(dbx),
+		item:            mysql.NewItemRepository(dbx),
+		migrations:      mysql.NewMigrationRepostory(dbx),
+		plaid:           mysql.NewPlaidRepository(dbx),
+		transaction:     mysql.NewTransactionRepository(dbx),
+		user:            mysql.NewUserRepository(dbx),
+		webhook:         mysql.NewWebhookRepository(dbx),
+		merchant:        mysql.NewMerchantRepository(dbx),
+		exchangeRate:    mysql.NewExchangeRateRepository(dbx),
+		receipt:         mysql.NewReceiptRepository(dbx),
+		budget:          mysql.NewBudgetRepository(dbx),
+		recurring:       mysql.NewRecurringRepository(dbx),
+		anomaly:         mysql.NewAnomalyRepository(dbx),
+		forecast:        mysql.NewForecastRepository(dbx),
+		journal:         mysql.NewJournalRepository(dbx),
+		statement:       mysql.NewStatementRepository(dbx),
+		backup:          mysql.NewBackupRepository(dbx),
+		alert:           mysql.NewAlertRepository(dbx),
+		notification:    mysql.NewNotificationRepository(dbx),
+		webhookEndpoint: mysql.NewWebhookEndpointRepository(dbx),
 	}
 
 }
@@ -323,15 +485,87 @@ func actionAPI(c *cli.Context) error {
 		core.repos.plaid,
 	)
 
+	anomaly := anomaly.New(
+		cfg.AnomalyThreshold,
+		core.repos.anomaly,
+	)
+
+	// Webhooks are queued by whichever process emits the event and delivered by the worker
+	webhook := webhook.New(
+		core.logger,
+		core.redis,
+		core.repos.webhookEndpoint,
+	)
+
+	event := event.New(
+		core.logger,
+		core.repos.user,
+	)
+	event.Subscribe(webhook.HandleEvent)
+
 	transaction := transaction.New(
-		core.s3,
+		core.blobs,
 		core.logger,
+		anomaly,
 		core.gateway,
 		cache,
-		cfg.S3.Bucket,
+		event,
+		time.Duration(cfg.RefundMatchWindowDays)*time.Hour*24,
 		core.repos.starter,
 		core.repos.transaction,
 		core.repos.merchant,
+		core.repos.receipt,
+	)
+
+	recurring := recurring.New(
+		event,
+		core.repos.account,
+		core.repos.merchant,
+		core.repos.transaction,
+		core.repos.recurring,
+	)
+
+	forecast := forecast.New(
+		recurring,
+		core.repos.account,
+		core.repos.transaction,
+		core.repos.forecast,
+	)
+
+	currency := currency.New(
+		core.repos.exchangeRate,
+	)
+
+	report := report.New(
+		currency,
+		core.repos.account,
+		core.repos.transaction,
+	)
+
+	budget := budget.New(
+		report,
+		core.repos.budget,
+	)
+
+	notification := notification.New(
+		core.logger,
+		core.mailer,
+		core.repos.account,
+		core.repos.transaction,
+		core.repos.user,
+		core.repos.notification,
+	)
+	event.Subscribe(notification.HandleEvent)
+
+	alert := alert.New(
+		core.logger,
+		budget,
+		currency,
+		event,
+		core.repos.account,
+		core.repos.transaction,
+		core.repos.user,
+		core.repos.alert,
 	)
 
 	importer := importer.New(
@@ -340,11 +574,33 @@ func actionAPI(c *cli.Context) error {
 		core.redis,
 		core.gateway,
 		account,
+		alert,
+		event,
+		forecast,
 		item,
 		transaction,
+		user,
 		core.repos.webhook,
 	)
 
+	export := export.New(
+		cache,
+		core.repos.transaction,
+	)
+
+	journal := journal.New(
+		core.repos.account,
+		core.repos.transaction,
+		core.repos.journal,
+	)
+
+	statement := statement.New(
+		account,
+		event,
+		transaction,
+		core.repos.statement,
+	)
+
 	loaders := dataloaders.New(item, transaction)
 
 	server := server.New(
@@ -359,6 +615,19 @@ func actionAPI(c *cli.Context) error {
 		account,
 		item,
 		transaction,
+		currency,
+		report,
+		budget,
+		recurring,
+		anomaly,
+		forecast,
+		export,
+		journal,
+		statement,
+		notification,
+		alert,
+		webhook,
+		core.blobs,
 	)
 
 	// Channel to listen for errors generated by api server
@@ -416,15 +685,87 @@ func actionWorker(c *cli.Context) error {
 	)
 	cache := cache.New(core.redis)
 
+	anomaly := anomaly.New(
+		cfg.AnomalyThreshold,
+		core.repos.anomaly,
+	)
+
+	// Webhooks are queued by whichever process emits the event and delivered by the worker
+	webhook := webhook.New(
+		core.logger,
+		core.redis,
+		core.repos.webhookEndpoint,
+	)
+
+	event := event.New(
+		core.logger,
+		core.repos.user,
+	)
+	event.Subscribe(webhook.HandleEvent)
+
 	transaction := transaction.New(
-		core.s3,
+		core.blobs,
 		core.logger,
+		anomaly,
 		core.gateway,
 		cache,
-		cfg.S3.Bucket,
+		event,
+		time.Duration(cfg.RefundMatchWindowDays)*time.Hour*24,
 		core.repos.starter,
 		core.repos.transaction,
 		core.repos.merchant,
+		core.repos.receipt,
+	)
+
+	recurring := recurring.New(
+		event,
+		core.repos.account,
+		core.repos.merchant,
+		core.repos.transaction,
+		core.repos.recurring,
+	)
+
+	forecast := forecast.New(
+		recurring,
+		core.repos.account,
+		core.repos.transaction,
+		core.repos.forecast,
+	)
+
+	currency := currency.New(
+		core.repos.exchangeRate,
+	)
+
+	report := report.New(
+		currency,
+		core.repos.account,
+		core.repos.transaction,
+	)
+
+	budget := budget.New(
+		report,
+		core.repos.budget,
+	)
+
+	notification := notification.New(
+		core.logger,
+		core.mailer,
+		core.repos.account,
+		core.repos.transaction,
+		core.repos.user,
+		core.repos.notification,
+	)
+	event.Subscribe(notification.HandleEvent)
+
+	alert := alert.New(
+		core.logger,
+		budget,
+		currency,
+		event,
+		core.repos.account,
+		core.repos.transaction,
+		core.repos.user,
+		core.repos.alert,
 	)
 
 	importer := importer.New(
@@ -433,8 +774,12 @@ func actionWorker(c *cli.Context) error {
 		core.redis,
 		core.gateway,
 		account,
+		alert,
+		event,
+		forecast,
 		item,
 		transaction,
+		user,
 		core.repos.webhook,
 	)
 
@@ -457,12 +802,73 @@ func actionWorker(c *cli.Context) error {
 	}
 	core.logger.WithField("id", id).Debug("successfully added import institutions job to cron scheduler")
 
+	// Snapshot balances just before the day ends so that accounts which were not refreshed
+	// by a webhook today still have a balance recorded for it
+	id, err = crn.AddFunc("55 23 * * *", func() {
+		err := account.SnapshotAccountBalances(ctx, time.Now())
+		if err != nil {
+			core.logger.WithError(err).Error("failed to snapshot account balances")
+		}
+	})
+	if err != nil {
+		core.logger.WithError(err).Fatal("failed to add snapshot account balances job to cron scheduler. exiting go routing")
+	}
+	core.logger.WithField("id", id).Debug("successfully added snapshot account balances job to cron scheduler")
+
+	// Detect recurring series every night so that a charge which did not arrive when it was
+	// expected is reported as missing
+	id, err = crn.AddFunc("30 3 * * *", func() {
+		users, err := user.Users(ctx)
+		if err != nil {
+			core.logger.WithError(err).Error("failed to fetch users to detect recurring series for")
+			return
+		}
+
+		for _, u := range users {
+			_, err := recurring.DetectRecurringSeries(ctx, u)
+			if err != nil {
+				core.logger.WithError(err).WithField("user_id", u.ID).Error("failed to detect recurring series")
+			}
+		}
+	})
+	if err != nil {
+		core.logger.WithError(err).Fatal("failed to add detect recurring series job to cron scheduler. exiting go routing")
+	}
+	core.logger.WithField("id", id).Debug("successfully added detect recurring series job to cron scheduler")
+
+	// Digests go out in the morning, once the imports of the night before have finished. Weekly
+	// digests cover the week up to the Sunday before they are sent
+	id, err = crn.AddFunc("0 7 * * *", func() {
+		err := notification.SendDigests(ctx, ledger.DigestFrequencyDaily, time.Now())
+		if err != nil {
+			core.logger.WithError(err).Error("failed to send daily digests")
+		}
+	})
+	if err != nil {
+		core.logger.WithError(err).Fatal("failed to add daily digest job to cron scheduler. exiting go routing")
+	}
+	core.logger.WithField("id", id).Debug("successfully added daily digest job to cron scheduler")
+
+	id, err = crn.AddFunc("0 7 * * 1", func() {
+		err := notification.SendDigests(ctx, ledger.DigestFrequencyWeekly, time.Now())
+		if err != nil {
+			core.logger.WithError(err).Error("failed to send weekly digests")
+		}
+	})
+	if err != nil {
+		core.logger.WithError(err).Fatal("failed to add weekly digest job to cron scheduler. exiting go routing")
+	}
+	core.logger.WithField("id", id).Debug("successfully added weekly digest job to cron scheduler")
+
 	core.logger.Info("starting cron...")
 	crn.Start()
 
 	core.logger.Info("starting importer...")
 	go importer.Run(ctx)
 
+	core.logger.Info("starting webhook delivery...")
+	go webhook.Run(ctx)
+
 	// Channel to listen for interrupts and to run a graceful shutdown
 	osSignals := make(chan os.Signal, 1)
 	signal.Notify(osSignals, os.Interrupt, syscall.SIGTERM)
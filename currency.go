@@ -0,0 +1,25 @@
+package ledger
+
+import (
+	"context"
+	"time"
+)
+
+const DefaultBaseCurrency = "USD"
+
+type ExchangeRateRepository interface {
+	// ExchangeRate returns the most recent rate for the currency pair published on or before date
+	ExchangeRate(ctx context.Context, from, to string, date time.Time) (*ExchangeRate, error)
+	ExchangeRates(ctx context.Context, from, to string) ([]*ExchangeRate, error)
+	SaveExchangeRates(ctx context.Context, rates []*ExchangeRate) error
+}
+
+// ExchangeRate is the number of units of ToCurrency that a single unit of FromCurrency bought on Date
+type ExchangeRate struct {
+	Date         time.Time `db:"date" json:"date"`
+	FromCurrency string    `db:"from_currency" json:"fromCurrency"`
+	ToCurrency   string    `db:"to_currency" json:"toCurrency"`
+	Rate         float64   `db:"rate" json:"rate"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+}
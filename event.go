@@ -0,0 +1,84 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// EventType is the kind of thing that happened to the data of a user
+type EventType string
+
+const (
+	// EventTransactionCreated is emitted for every transaction that is imported for the first time.
+	// Its data is the transaction
+	EventTransactionCreated EventType = "transaction.created"
+	// EventTransactionUpdated is emitted when a transaction changes, whether it was updated by an
+	// import or by the user. Its data is the transaction
+	EventTransactionUpdated EventType = "transaction.updated"
+	// EventItemError is emitted when an item cannot be imported, usually because the user needs to
+	// log in to their institution again. Its data is an ItemErrorEvent
+	EventItemError EventType = "item.error"
+	// EventImportCompleted is emitted once the transactions of an import have been processed. Its
+	// data is an ImportCompletedEvent
+	EventImportCompleted EventType = "import.completed"
+	// EventAlertFired is emitted when one of the alert rules of the user fires. Its data is the alert
+	EventAlertFired EventType = "alert.fired"
+	// EventSubscriptionDetected is emitted when a recurring series is found in the transactions of
+	// the user for the first time. Its data is the series
+	EventSubscriptionDetected EventType = "subscription.detected"
+)
+
+// AllEventTypes are the event types in the order they are documented
+var AllEventTypes = []EventType{
+	EventTransactionCreated, EventTransactionUpdated, EventItemError, EventImportCompleted,
+	EventAlertFired, EventSubscriptionDetected,
+}
+
+func (t EventType) Valid() bool {
+	for _, eventType := range AllEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is something that happened to the data of a user. Events are emitted once for every user
+// of the item they happened to
+type Event struct {
+	EventID   string      `json:"id"`
+	Type      EventType   `json:"type"`
+	UserID    uuid.UUID   `json:"userID"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// EventHandler is called with every event that is emitted. Handlers are called in the order they
+// subscribed, before Emit returns, so they should hand slow work off rather than doing it inline
+type EventHandler func(ctx context.Context, event *Event)
+
+// ItemErrorEvent is the data of an item.error event
+type ItemErrorEvent struct {
+	ItemID string `json:"itemID"`
+	Error  string `json:"error"`
+}
+
+const (
+	ImportSourcePlaid     = "plaid"
+	ImportSourceStatement = "statement"
+)
+
+// ImportCompletedEvent is the data of an import.completed event. Source is plaid for imports triggered
+// by a Plaid webhook and statement for statement files. Created and WebhookCode are only set for Plaid
+// imports and are not sent to webhooks
+type ImportCompletedEvent struct {
+	ItemID          string         `json:"itemID"`
+	Source          string         `json:"source"`
+	Transactions    int            `json:"transactions"`
+	NewTransactions int            `json:"newTransactions"`
+	Created         []*Transaction `json:"-"`
+	WebhookCode     WebhookCode    `json:"-"`
+}
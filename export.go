@@ -0,0 +1,166 @@
+package ledger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+// ExportColumn is a column that can be selected for a transaction export. The value is used as the
+// header of the column
+type ExportColumn string
+
+const (
+	ExportColumnDate              ExportColumn = "date"
+	ExportColumnAuthorizedDate    ExportColumn = "authorized_date"
+	ExportColumnName              ExportColumn = "name"
+	ExportColumnAmount            ExportColumn = "amount"
+	ExportColumnCurrency          ExportColumn = "currency"
+	ExportColumnMerchant          ExportColumn = "merchant"
+	ExportColumnCategory          ExportColumn = "category"
+	ExportColumnCategoryHierarchy ExportColumn = "category_hierarchy"
+	ExportColumnAccount           ExportColumn = "account"
+	ExportColumnAccountMask       ExportColumn = "account_mask"
+	ExportColumnPending           ExportColumn = "pending"
+	ExportColumnPaymentChannel    ExportColumn = "payment_channel"
+	ExportColumnHiddenReason      ExportColumn = "hidden_reason"
+	ExportColumnItemID            ExportColumn = "item_id"
+	ExportColumnAccountID         ExportColumn = "account_id"
+	ExportColumnTransactionID     ExportColumn = "transaction_id"
+)
+
+// AllExportColumns are the columns a transaction export can include, in the order they are
+// exported when none are selected
+var AllExportColumns = []ExportColumn{
+	ExportColumnDate, ExportColumnAuthorizedDate, ExportColumnName, ExportColumnAmount, ExportColumnCurrency,
+	ExportColumnMerchant, ExportColumnCategory, ExportColumnCategoryHierarchy, ExportColumnAccount,
+	ExportColumnAccountMask, ExportColumnPending, ExportColumnPaymentChannel, ExportColumnHiddenReason,
+	ExportColumnItemID, ExportColumnAccountID, ExportColumnTransactionID,
+}
+
+func (c ExportColumn) Valid() bool {
+	for _, column := range AllExportColumns {
+		if c == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExportDateFormat is how the dates of a transaction export are written
+type ExportDateFormat string
+
+const (
+	ExportDateFormatISO ExportDateFormat = "iso"
+	ExportDateFormatUS  ExportDateFormat = "us"
+	ExportDateFormatEU  ExportDateFormat = "eu"
+)
+
+var exportDateLayouts = map[ExportDateFormat]string{
+	ExportDateFormatISO: "2006-01-02",
+	ExportDateFormatUS:  "01/02/2006",
+	ExportDateFormatEU:  "02/01/2006",
+}
+
+func (f ExportDateFormat) Valid() bool {
+	_, ok := exportDateLayouts[f]
+	return ok
+}
+
+// Layout returns the time layout of the format, falling back to ISO 8601 for unknown formats
+func (f ExportDateFormat) Layout() string {
+	if layout, ok := exportDateLayouts[f]; ok {
+		return layout
+	}
+
+	return exportDateLayouts[ExportDateFormatISO]
+}
+
+// TransactionExportOptions are the transactions an export includes and how they are written. Every
+// column is included when Columns is empty
+type TransactionExportOptions struct {
+	Filters    *TransactionFilter `json:"filters"`
+	Columns    []ExportColumn     `json:"columns"`
+	DateFormat ExportDateFormat   `json:"dateFormat"`
+}
+
+// TransactionExport is an export requested ahead of being downloaded. Token authorizes the download
+// in place of a bearer token, so that the export can be fetched by a plain link
+type TransactionExport struct {
+	Token     string                    `json:"token"`
+	UserID    uuid.UUID                 `json:"userID"`
+	Options   *TransactionExportOptions `json:"options"`
+	ExpiresAt time.Time                 `json:"expiresAt"`
+}
+
+// TransactionExportRow is a transaction joined to the names of its merchant, category and account
+type TransactionExportRow struct {
+	ItemID                 string      `db:"item_id"`
+	AccountID              string      `db:"account_id"`
+	TransactionID          string      `db:"transaction_id"`
+	Name                   string      `db:"name"`
+	Date                   time.Time   `db:"date"`
+	AuthorizedDate         null.Time   `db:"authorized_date"`
+	Amount                 float64     `db:"amount"`
+	ISOCurrencyCode        null.String `db:"iso_currency_code"`
+	UnofficialCurrencyCode null.String `db:"unofficial_currency_code"`
+	Pending                bool        `db:"pending"`
+	PaymentChannel         string      `db:"payment_channel"`
+	HiddenReason           null.String `db:"hidden_reason"`
+	CategoryID             null.String `db:"category_id"`
+	MerchantName           null.String `db:"merchant_name"`
+	CategoryName           null.String `db:"category_name"`
+	CategoryHierarchy      SliceString `db:"category_hierarchy"`
+	AccountName            null.String `db:"account_name"`
+	AccountMask            null.String `db:"account_mask"`
+}
+
+// Value returns the value of column for the row as it is written to an export
+func (r *TransactionExportRow) Value(column ExportColumn, dateFormat ExportDateFormat) string {
+	switch column {
+	case ExportColumnDate:
+		return r.Date.Format(dateFormat.Layout())
+	case ExportColumnAuthorizedDate:
+		if !r.AuthorizedDate.Valid {
+			return ""
+		}
+		return r.AuthorizedDate.Time.Format(dateFormat.Layout())
+	case ExportColumnName:
+		return r.Name
+	case ExportColumnAmount:
+		return strconv.FormatFloat(r.Amount, 'f', 2, 64)
+	case ExportColumnCurrency:
+		if r.ISOCurrencyCode.Valid {
+			return r.ISOCurrencyCode.String
+		}
+		return r.UnofficialCurrencyCode.String
+	case ExportColumnMerchant:
+		return r.MerchantName.String
+	case ExportColumnCategory:
+		return r.CategoryName.String
+	case ExportColumnCategoryHierarchy:
+		return strings.Join(r.CategoryHierarchy, " > ")
+	case ExportColumnAccount:
+		return r.AccountName.String
+	case ExportColumnAccountMask:
+		return r.AccountMask.String
+	case ExportColumnPending:
+		return strconv.FormatBool(r.Pending)
+	case ExportColumnPaymentChannel:
+		return r.PaymentChannel
+	case ExportColumnHiddenReason:
+		return r.HiddenReason.String
+	case ExportColumnItemID:
+		return r.ItemID
+	case ExportColumnAccountID:
+		return r.AccountID
+	case ExportColumnTransactionID:
+		return r.TransactionID
+	}
+
+	return ""
+}
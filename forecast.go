@@ -0,0 +1,147 @@
+package ledger
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+type ForecastRepository interface {
+	AccountForecastsByUserID(ctx context.Context, userID uuid.UUID) ([]*AccountForecast, error)
+	SaveAccountForecast(ctx context.Context, forecast *AccountForecast) error
+}
+
+// DiscretionarySpend is the total spent from an account in a category outside of any recurring
+// series. Total is reported as a positive amount
+type DiscretionarySpend struct {
+	ItemID     string      `db:"item_id"`
+	AccountID  string      `db:"account_id"`
+	CategoryID null.String `db:"category_id"`
+	Total      float64     `db:"total"`
+}
+
+// AccountForecast is the expected balance of an account at the end of each of the coming days.
+// Floor is the balance floor of the account, or zero when it does not have one. ShortfallDate is
+// the first day the balance is expected to fall below the floor and is null when it is not
+type AccountForecast struct {
+	ItemID        string             `db:"item_id" json:"itemID"`
+	AccountID     string             `db:"account_id" json:"accountID"`
+	Currency      string             `db:"currency_code" json:"currency"`
+	Current       float64            `db:"current_balance" json:"current"`
+	Floor         float64            `db:"floor" json:"floor"`
+	LowestBalance float64            `db:"lowest_balance" json:"lowestBalance"`
+	LowestDate    time.Time          `db:"lowest_date" json:"lowestDate"`
+	ShortfallDate null.Time          `db:"shortfall_date" json:"shortfallDate"`
+	Days          ForecastDays       `db:"days" json:"days"`
+	Categories    ForecastCategories `db:"categories" json:"categories"`
+	CreatedAt     time.Time          `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time          `db:"updated_at" json:"updatedAt"`
+}
+
+// Summarise sets the lowest balance of the forecast and the day it falls below the floor from its days
+func (f *AccountForecast) Summarise() {
+
+	f.LowestBalance, f.LowestDate = f.Current, time.Time{}
+	f.ShortfallDate = null.NewTime(time.Time{}, false)
+	for _, day := range f.Days {
+		if f.LowestDate.IsZero() || day.Balance < f.LowestBalance {
+			f.LowestBalance, f.LowestDate = day.Balance, day.Date
+		}
+
+		if !f.ShortfallDate.Valid && day.Balance < f.Floor {
+			f.ShortfallDate = null.TimeFrom(day.Date)
+		}
+	}
+
+}
+
+// Truncate returns a copy of the forecast that only covers its first days
+func (f *AccountForecast) Truncate(days int) *AccountForecast {
+
+	truncated := *f
+	if days < len(f.Days) {
+		truncated.Days = f.Days[:days]
+	}
+	truncated.Summarise()
+
+	return &truncated
+
+}
+
+// ForecastDay is the change to the balance of an account expected on Date. Recurring is the net of
+// the recurring series paid from or into the account that day and Discretionary is the average
+// amount spent outside of them, which is negative. Balance is the balance at the end of the day
+type ForecastDay struct {
+	Date          time.Time `json:"date"`
+	Recurring     float64   `json:"recurring"`
+	Discretionary float64   `json:"discretionary"`
+	Balance       float64   `json:"balance"`
+}
+
+type ForecastDays []*ForecastDay
+
+func (d ForecastDays) Value() (driver.Value, error) {
+
+	if len(d) == 0 {
+		return []byte(`[]`), nil
+	}
+
+	return json.Marshal(d)
+
+}
+
+func (d *ForecastDays) Scan(value interface{}) error {
+
+	switch data := value.(type) {
+	case []byte:
+		err := json.Unmarshal(data, d)
+		if err != nil {
+			return fmt.Errorf("failed to scan string into ForecastDays: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to scan value into ForecastDays: unsupported type %T", value)
+	}
+
+	return nil
+
+}
+
+// ForecastCategory is the average amount spent from an account in a category each day, outside of
+// any recurring series. DailyAverage is reported as a positive amount
+type ForecastCategory struct {
+	CategoryID   null.String `json:"categoryID"`
+	DailyAverage float64     `json:"dailyAverage"`
+}
+
+type ForecastCategories []*ForecastCategory
+
+func (c ForecastCategories) Value() (driver.Value, error) {
+
+	if len(c) == 0 {
+		return []byte(`[]`), nil
+	}
+
+	return json.Marshal(c)
+
+}
+
+func (c *ForecastCategories) Scan(value interface{}) error {
+
+	switch data := value.(type) {
+	case []byte:
+		err := json.Unmarshal(data, c)
+		if err != nil {
+			return fmt.Errorf("failed to scan string into ForecastCategories: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to scan value into ForecastCategories: unsupported type %T", value)
+	}
+
+	return nil
+
+}
@@ -2,10 +2,15 @@
 package account
 
 import (
+	"context"
+	"time"
+
 	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
 )
 
 type Service interface {
+	SnapshotAccountBalance(ctx context.Context, account *ledger.Account) error
 	ledger.AccountRepository
 }
 
@@ -21,3 +26,25 @@ func New(account ledger.AccountRepository) Service {
 		AccountRepository: account,
 	}
 }
+
+// SnapshotAccountBalance records the current balance of the account as its balance for today
+func (s *service) SnapshotAccountBalance(ctx context.Context, account *ledger.Account) error {
+
+	if account.Balance == nil {
+		return errors.New("[account.SnapshotAccountBalance] account does not have a balance")
+	}
+
+	err := s.SaveAccountBalanceSnapshot(ctx, &ledger.AccountBalanceSnapshot{
+		ItemID:                 account.ItemID,
+		AccountID:              account.AccountID,
+		Date:                   time.Now(),
+		Available:              account.Balance.Available,
+		Current:                account.Balance.Current,
+		Limit:                  account.Balance.Limit,
+		ISOCurrencyCode:        account.Balance.ISOCurrencyCode,
+		UnofficialCurrencyCode: account.Balance.UnofficialCurrencyCode,
+	})
+
+	return errors.Wrap(err, "[account.SnapshotAccountBalance]")
+
+}
@@ -0,0 +1,429 @@
+// Package alert provides service access to alert rules and the alerts they fire
+package alert
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/budget"
+	"github.com/ddouglas/ledger/internal/currency"
+	"github.com/ddouglas/ledger/internal/event"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/null"
+)
+
+type Service interface {
+	EvaluateImport(ctx context.Context, item *ledger.Item, created []*ledger.Transaction) error
+	ledger.AlertRepository
+}
+
+type service struct {
+	logger       *logrus.Logger
+	budget       budget.Service
+	currency     currency.Service
+	event        event.Service
+	accounts     ledger.AccountRepository
+	transactions ledger.TransactionRepository
+	users        ledger.UserRepository
+
+	ledger.AlertRepository
+}
+
+func New(
+	logger *logrus.Logger,
+	budget budget.Service,
+	currency currency.Service,
+	event event.Service,
+	accounts ledger.AccountRepository,
+	transactions ledger.TransactionRepository,
+	users ledger.UserRepository,
+	alerts ledger.AlertRepository,
+) Service {
+	return &service{
+		logger:          logger,
+		budget:          budget,
+		currency:        currency,
+		event:           event,
+		accounts:        accounts,
+		transactions:    transactions,
+		users:           users,
+		AlertRepository: alerts,
+	}
+}
+
+func (s *service) CreateAlertRule(ctx context.Context, rule *ledger.AlertRule) (*ledger.AlertRule, error) {
+
+	rule.RuleID = uuid.Must(uuid.NewV4()).String()
+
+	err := s.validateAlertRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.AlertRepository.CreateAlertRule(ctx, rule)
+
+}
+
+func (s *service) UpdateAlertRule(ctx context.Context, ruleID string, rule *ledger.AlertRule) (*ledger.AlertRule, error) {
+
+	err := s.validateAlertRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.AlertRepository.UpdateAlertRule(ctx, ruleID, rule)
+
+}
+
+// validateAlertRule checks the rule has what its type needs to be evaluated, and that the account
+// or budget it refers to belongs to the user of the rule
+func (s *service) validateAlertRule(ctx context.Context, rule *ledger.AlertRule) error {
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return errors.New("a name is required")
+	}
+
+	if !rule.Type.Valid() {
+		return errors.Errorf("%s is not a valid rule type, valid types are large_transaction, low_balance, budget and new_merchant", rule.Type)
+	}
+
+	if rule.ItemID.Valid != rule.AccountID.Valid {
+		return errors.New("an item id and an account id must be given together")
+	}
+
+	switch rule.Type {
+	case ledger.AlertRuleTypeLargeTransaction:
+		if rule.Threshold <= 0 {
+			return errors.New("the threshold of a large transaction rule must be greater than zero")
+		}
+	case ledger.AlertRuleTypeLowBalance:
+		if !rule.AccountID.Valid {
+			return errors.New("a low balance rule requires an account")
+		}
+	case ledger.AlertRuleTypeBudget:
+		if !rule.BudgetID.Valid {
+			return errors.New("a budget rule requires a budget")
+		}
+
+		if rule.Threshold <= 0 {
+			return errors.New("the threshold of a budget rule is a percentage and must be greater than zero")
+		}
+
+		_, err := s.budget.Budget(ctx, rule.UserID, rule.BudgetID.String)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.Errorf("budget %s does not exist", rule.BudgetID.String)
+		}
+		if err != nil {
+			return errors.Wrap(err, "[alert.validateAlertRule] failed to fetch budget")
+		}
+	}
+
+	if rule.AccountID.Valid {
+		accounts, err := s.accounts.AccountsByUserID(ctx, rule.UserID)
+		if err != nil {
+			return errors.Wrap(err, "[alert.validateAlertRule] failed to fetch accounts")
+		}
+
+		var found bool
+		for _, account := range accounts {
+			if account.ItemID == rule.ItemID.String && account.AccountID == rule.AccountID.String {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return errors.Errorf("account %s does not exist", rule.AccountID.String)
+		}
+	}
+
+	return nil
+
+}
+
+// EvaluateImport evaluates the enabled alert rules of every user of item once the import of item
+// has finished. created are the transactions the import created. Budget rules are evaluated for
+// every import, as any of the users items can move a budget, while low balance rules are only
+// evaluated for imports of the item of their account. A failure to evaluate one rule is logged
+// and does not stop the others from being evaluated
+func (s *service) EvaluateImport(ctx context.Context, item *ledger.Item, created []*ledger.Transaction) error {
+
+	users, err := s.users.UsersByItemID(ctx, item.ItemID)
+	if err != nil {
+		return errors.Wrap(err, "[alert.EvaluateImport] failed to fetch users of item")
+	}
+
+	now := time.Now()
+	for _, user := range users {
+		entry := s.logger.WithContext(ctx).WithField("user_id", user.ID)
+
+		rules, err := s.AlertRules(ctx, user.ID)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch alert rules")
+			continue
+		}
+
+		var statuses []*ledger.BudgetStatus
+		for _, rule := range rules {
+			if !rule.Enabled {
+				continue
+			}
+
+			var candidates []*ledger.Alert
+			switch rule.Type {
+			case ledger.AlertRuleTypeLargeTransaction:
+				candidates, err = s.largeTransactions(ctx, rule, user, item, created)
+			case ledger.AlertRuleTypeLowBalance:
+				if rule.ItemID.String != item.ItemID {
+					continue
+				}
+				candidates, err = s.lowBalance(ctx, rule, now)
+			case ledger.AlertRuleTypeBudget:
+				if statuses == nil {
+					statuses, err = s.budget.BudgetStatus(ctx, user, now)
+					if err != nil {
+						break
+					}
+				}
+				candidates = s.budgetThreshold(rule, statuses)
+			case ledger.AlertRuleTypeNewMerchant:
+				candidates, err = s.newMerchants(ctx, rule, item, created)
+			}
+			if err != nil {
+				entry.WithError(err).WithField("rule_id", rule.RuleID).Error("failed to evaluate alert rule")
+				continue
+			}
+
+			for _, candidate := range candidates {
+				err = s.fire(ctx, rule, candidate, now)
+				if err != nil {
+					entry.WithError(err).WithField("rule_id", rule.RuleID).Error("failed to fire alert")
+				}
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// fire stores alert unless the rule is cooling down or has already fired for the key of alert
+func (s *service) fire(ctx context.Context, rule *ledger.AlertRule, alert *ledger.Alert, now time.Time) error {
+
+	if rule.CoolingDown(now) {
+		return nil
+	}
+
+	_, err := s.AlertByKey(ctx, rule.RuleID, alert.Key)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	alert.AlertID = uuid.Must(uuid.NewV4()).String()
+	alert.RuleID = rule.RuleID
+	alert.UserID = rule.UserID
+	alert.Type = rule.Type
+
+	alert, err = s.CreateAlert(ctx, alert)
+	if err != nil {
+		return err
+	}
+
+	err = s.UpdateAlertRuleFiredAt(ctx, rule.RuleID, now)
+	if err != nil {
+		return err
+	}
+
+	rule.LastFiredAt = null.TimeFrom(now)
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"user_id": rule.UserID,
+		"rule_id": rule.RuleID,
+		"key":     alert.Key,
+	}).Info(alert.Message)
+
+	s.event.Emit(ctx, rule.UserID, ledger.EventAlertFired, alert)
+
+	return nil
+
+}
+
+// largeTransactions compares transactions to the threshold in the base currency of the user, a
+// transaction that cannot be converted for lack of an exchange rate is left out
+func (s *service) largeTransactions(ctx context.Context, rule *ledger.AlertRule, user *ledger.User, item *ledger.Item, created []*ledger.Transaction) ([]*ledger.Alert, error) {
+
+	var alerts = make([]*ledger.Alert, 0)
+	for _, transaction := range created {
+		if !matchesAccount(rule, item.ItemID, transaction.AccountID) {
+			continue
+		}
+
+		amount, err := s.currency.Convert(ctx, math.Abs(transaction.Amount), transaction.CurrencyCode(), user.BaseCurrency, transaction.Date)
+		if errors.Is(err, currency.ErrExchangeRateNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if amount < rule.Threshold {
+			continue
+		}
+
+		alerts = append(alerts, &ledger.Alert{
+			Key:           fmt.Sprintf("transaction:%s:%s", item.ItemID, transaction.TransactionID),
+			Message:       fmt.Sprintf("%s: %s of %s", rule.Name, payee(transaction), formatAmount(transaction.Amount, transaction.CurrencyCode())),
+			ItemID:        null.StringFrom(item.ItemID),
+			AccountID:     null.StringFrom(transaction.AccountID),
+			TransactionID: null.StringFrom(transaction.TransactionID),
+			Amount:        null.Float64From(transaction.Amount),
+		})
+	}
+
+	return alerts, nil
+
+}
+
+// lowBalance fires at most once a day for as long as the balance stays below the threshold, the
+// cooldown of the rule can space them out further
+func (s *service) lowBalance(ctx context.Context, rule *ledger.AlertRule, now time.Time) ([]*ledger.Alert, error) {
+
+	account, err := s.accounts.Account(ctx, rule.ItemID.String, rule.AccountID.String)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Balance == nil || account.Balance.Current >= rule.Threshold {
+		return nil, nil
+	}
+
+	currency := account.Balance.ISOCurrencyCode
+	if currency == "" {
+		currency = account.Balance.UnofficialCurrencyCode.String
+	}
+
+	return []*ledger.Alert{{
+		Key:       fmt.Sprintf("balance:%s:%s:%s", account.ItemID, account.AccountID, now.Format("2006-01-02")),
+		Message:   fmt.Sprintf("%s: the balance of %s is %s", rule.Name, account.Name.String, formatAmount(account.Balance.Current, currency)),
+		ItemID:    null.StringFrom(account.ItemID),
+		AccountID: null.StringFrom(account.AccountID),
+		Amount:    null.Float64From(account.Balance.Current),
+	}}, nil
+
+}
+
+// budgetThreshold fires once per period of the budget. A budget with nothing left to spend because
+// of overspending in earlier periods counts as fully used as soon as anything is spent
+func (s *service) budgetThreshold(rule *ledger.AlertRule, statuses []*ledger.BudgetStatus) []*ledger.Alert {
+
+	for _, status := range statuses {
+		if status.Budget.BudgetID != rule.BudgetID.String {
+			continue
+		}
+
+		used := status.PercentUsed.Float64
+		if !status.PercentUsed.Valid && status.Spent > 0 {
+			used = math.Inf(1)
+		}
+
+		if used < rule.Threshold {
+			return nil
+		}
+
+		return []*ledger.Alert{{
+			Key:      fmt.Sprintf("budget:%s:%s", status.Budget.BudgetID, status.PeriodStart.Format("2006-01-02")),
+			Message:  fmt.Sprintf("%s: %s has spent %.2f of %.2f", rule.Name, status.Budget.Name, status.Spent, status.Limit),
+			BudgetID: null.StringFrom(status.Budget.BudgetID),
+			Amount:   null.Float64From(status.Spent),
+		}}
+	}
+
+	return nil
+
+}
+
+// newMerchants returns an alert for each merchant of the created transactions that the user has no
+// other transactions with
+func (s *service) newMerchants(ctx context.Context, rule *ledger.AlertRule, item *ledger.Item, created []*ledger.Transaction) ([]*ledger.Alert, error) {
+
+	var byMerchant = make(map[string][]*ledger.Transaction)
+	var merchants = make([]string, 0)
+	var ids = make(map[string]bool, len(created))
+	for _, transaction := range created {
+		ids[transaction.TransactionID] = true
+		if transaction.MerchantID == "" || !matchesAccount(rule, item.ItemID, transaction.AccountID) {
+			continue
+		}
+
+		if _, ok := byMerchant[transaction.MerchantID]; !ok {
+			merchants = append(merchants, transaction.MerchantID)
+		}
+		byMerchant[transaction.MerchantID] = append(byMerchant[transaction.MerchantID], transaction)
+	}
+
+	var alerts = make([]*ledger.Alert, 0)
+	for _, merchantID := range merchants {
+		transactions := byMerchant[merchantID]
+
+		// Fetching one more transaction than were created is enough to find an older one
+		var seen bool
+		err := s.transactions.ExportTransactions(ctx, rule.UserID, &ledger.TransactionFilter{
+			MerchantID: null.StringFrom(merchantID),
+			Limit:      null.Uint64From(uint64(len(created) + 1)),
+		}, func(row *ledger.TransactionExportRow) error {
+			if !ids[row.TransactionID] {
+				seen = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if seen {
+			continue
+		}
+
+		transaction := transactions[0]
+		alerts = append(alerts, &ledger.Alert{
+			Key:           fmt.Sprintf("merchant:%s", merchantID),
+			Message:       fmt.Sprintf("%s: first transaction at %s, %s", rule.Name, payee(transaction), formatAmount(transaction.Amount, transaction.CurrencyCode())),
+			ItemID:        null.StringFrom(item.ItemID),
+			AccountID:     null.StringFrom(transaction.AccountID),
+			TransactionID: null.StringFrom(transaction.TransactionID),
+			Amount:        null.Float64From(transaction.Amount),
+		})
+	}
+
+	return alerts, nil
+
+}
+
+// matchesAccount returns whether a transaction on the account is covered by rule. Rules without an
+// account cover every account
+func matchesAccount(rule *ledger.AlertRule, itemID, accountID string) bool {
+	return !rule.AccountID.Valid || (rule.ItemID.String == itemID && rule.AccountID.String == accountID)
+}
+
+func payee(transaction *ledger.Transaction) string {
+	if transaction.MerchantName.Valid && transaction.MerchantName.String != "" {
+		return transaction.MerchantName.String
+	}
+
+	return transaction.Name
+}
+
+func formatAmount(amount float64, currency string) string {
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", amount, currency))
+}
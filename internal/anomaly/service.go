@@ -0,0 +1,178 @@
+// Package anomaly provides service access to transactions that look unusual for the user that made them
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+type Service interface {
+	ScoreTransaction(ctx context.Context, transaction *ledger.Transaction) (*ledger.TransactionAnomaly, error)
+	ledger.AnomalyRepository
+}
+
+type service struct {
+	threshold float64
+
+	ledger.AnomalyRepository
+}
+
+func New(threshold float64, anomalies ledger.AnomalyRepository) Service {
+	return &service{
+		threshold:         threshold,
+		AnomalyRepository: anomalies,
+	}
+}
+
+const (
+	// baselineMonths is how far back the history a transaction is compared against goes
+	baselineMonths = 12
+	// maxAgeDays keeps the initial import of an item from flagging years of old transactions
+	maxAgeDays = 14
+
+	// minAmountHistory is how many earlier transactions at a merchant are needed before its amounts are
+	// used, below it the amounts of the category are used instead
+	minAmountHistory = 5
+	// minHistory is how many earlier transactions are needed before a merchant is considered new
+	minHistory = 30
+	// minContextHistory is how many earlier transactions with a location are needed before locations
+	// are compared
+	minContextHistory = 20
+
+	newMerchantScore = 0.4
+	newCountryScore  = 0.6
+	newRegionScore   = 0.3
+)
+
+// ScoreTransaction compares a posted outflow against the history of the user that made it and saves
+// it as an anomaly when the combined score of the ways it differs reaches the threshold. It returns
+// nil when the transaction is not flagged. The time of day is not compared, AuthorizedDateTime is
+// never set because the pinned Plaid client does not return the authorized time of a transaction
+func (s *service) ScoreTransaction(ctx context.Context, transaction *ledger.Transaction) (*ledger.TransactionAnomaly, error) {
+
+	if transaction.Pending || transaction.Amount >= 0 || time.Since(transaction.Date) > maxAgeDays*time.Hour*24 {
+		return nil, nil
+	}
+
+	baseline, err := s.AnomalyBaseline(ctx, transaction, transaction.Date.AddDate(0, -baselineMonths, 0))
+	if err != nil {
+		return nil, errors.Wrap(err, "[anomaly.ScoreTransaction] failed to fetch baseline")
+	}
+
+	var reasons = make(ledger.AnomalyReasons, 0)
+	var score float64
+	for _, reason := range []*ledger.AnomalyReason{
+		amountReason(transaction, baseline),
+		newMerchantReason(transaction, baseline),
+		locationReason(transaction, baseline),
+	} {
+		if reason == nil {
+			continue
+		}
+
+		reasons = append(reasons, reason)
+		score += reason.Score
+	}
+
+	score = math.Round(score*100) / 100
+	if len(reasons) == 0 || score < s.threshold {
+		return nil, nil
+	}
+
+	anomaly := &ledger.TransactionAnomaly{
+		ItemID:        transaction.ItemID,
+		TransactionID: transaction.TransactionID,
+		Score:         score,
+		Reasons:       reasons,
+	}
+
+	err = s.SaveTransactionAnomaly(ctx, anomaly)
+	if err != nil {
+		return nil, errors.Wrap(err, "[anomaly.ScoreTransaction] failed to save anomaly")
+	}
+
+	return anomaly, nil
+
+}
+
+// amountReason scores how far the amount is above the usual amount spent at the merchant, or in the
+// category when there is too little history at the merchant. Amounts within two standard deviations
+// are not unusual, the score reaches its maximum of 1 at four
+func amountReason(transaction *ledger.Transaction, baseline *ledger.AnomalyBaseline) *ledger.AnomalyReason {
+
+	var mean, stddev null.Float64
+	var scope string
+	switch {
+	case baseline.MerchantCount >= minAmountHistory:
+		mean, stddev, scope = baseline.MerchantMean, baseline.MerchantStdDev, "this merchant"
+	case baseline.CategoryCount >= minAmountHistory:
+		mean, stddev, scope = baseline.CategoryMean, baseline.CategoryStdDev, "this category"
+	default:
+		return nil
+	}
+
+	if !mean.Valid || !stddev.Valid || stddev.Float64 == 0 {
+		return nil
+	}
+
+	amount := math.Abs(transaction.Amount)
+	z := (amount - mean.Float64) / stddev.Float64
+	score := math.Min(math.Max((z-2)/2, 0), 1)
+	if score == 0 {
+		return nil
+	}
+
+	return &ledger.AnomalyReason{
+		Type:        ledger.AnomalyReasonAmount,
+		Score:       math.Round(score*100) / 100,
+		Description: fmt.Sprintf("%.2f is %.1f times the usual amount of %.2f spent at %s", amount, amount/mean.Float64, mean.Float64, scope),
+	}
+
+}
+
+func newMerchantReason(transaction *ledger.Transaction, baseline *ledger.AnomalyBaseline) *ledger.AnomalyReason {
+
+	if transaction.MerchantID == "" || baseline.HistoryCount < minHistory || baseline.MerchantCount > 0 {
+		return nil
+	}
+
+	return &ledger.AnomalyReason{
+		Type:        ledger.AnomalyReasonNewMerchant,
+		Score:       newMerchantScore,
+		Description: "first transaction at this merchant",
+	}
+
+}
+
+func locationReason(transaction *ledger.Transaction, baseline *ledger.AnomalyBaseline) *ledger.AnomalyReason {
+
+	location := transaction.Location
+	if location == nil || !location.Country.Valid || baseline.LocatedCount < minContextHistory {
+		return nil
+	}
+
+	if baseline.CountryCount == 0 {
+		return &ledger.AnomalyReason{
+			Type:        ledger.AnomalyReasonLocation,
+			Score:       newCountryScore,
+			Description: fmt.Sprintf("first transaction made in %s", location.Country.String),
+		}
+	}
+
+	if location.Region.Valid && baseline.RegionCount == 0 {
+		return &ledger.AnomalyReason{
+			Type:        ledger.AnomalyReasonLocation,
+			Score:       newRegionScore,
+			Description: fmt.Sprintf("first transaction made in %s, %s", location.Region.String, location.Country.String),
+		}
+	}
+
+	return nil
+
+}
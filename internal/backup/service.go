@@ -0,0 +1,310 @@
+// Package backup provides service access to backups of everything stored for a user, and to restoring them
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+const (
+	manifestName = "manifest.json"
+	tablesDir    = "tables/"
+	blobsDir     = "blobs/"
+)
+
+type Service interface {
+	Backup(ctx context.Context, w io.Writer, userID uuid.UUID, includeAccessTokens bool) (*ledger.BackupStats, error)
+	Restore(ctx context.Context, r io.Reader) (*ledger.BackupStats, error)
+}
+
+type service struct {
+	blobs   ledger.BlobStore
+	backups ledger.BackupRepository
+}
+
+func New(blobs ledger.BlobStore, backups ledger.BackupRepository) Service {
+	return &service{
+		blobs:   blobs,
+		backups: backups,
+	}
+}
+
+// Backup writes a gzipped tar archive of the users data to w. The archive starts with a manifest,
+// followed by a file of JSON lines for every table and the receipts and attachments of the user
+// from the blob store. Access tokens of items are left out unless includeAccessTokens is set
+func (s *service) Backup(ctx context.Context, w io.Writer, userID uuid.UUID, includeAccessTokens bool) (*ledger.BackupStats, error) {
+
+	manifest := &ledger.BackupManifest{
+		Version:              ledger.BackupVersion,
+		UserID:               userID,
+		CreatedAt:            time.Now().UTC(),
+		IncludesAccessTokens: includeAccessTokens,
+		Tables:               s.backups.BackupTables(),
+	}
+
+	stats := &ledger.BackupStats{
+		Manifest: manifest,
+		Rows:     make(map[string]int, len(manifest.Tables)),
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "[backup.Backup] failed to encode manifest")
+	}
+
+	err = writeEntry(archive, manifestName, manifest.CreatedAt, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "[backup.Backup] failed to write manifest")
+	}
+
+	var keys = make([]string, 0)
+	var seen = make(map[string]bool)
+	addKey := func(key interface{}) {
+		if k, ok := key.(string); ok && k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	for _, table := range manifest.Tables {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		err = s.backups.BackupRows(ctx, table, userID, includeAccessTokens, func(row ledger.BackupRow) error {
+			stats.Rows[table]++
+
+			switch table {
+			case "receipts", "transaction_attachments":
+				addKey(row["object_key"])
+				addKey(row["thumbnail_key"])
+			case "transactions":
+				if hasReceipt, _ := row["has_receipt"].(int64); hasReceipt == 1 {
+					transaction := &ledger.Transaction{
+						ItemID:        fmt.Sprint(row["item_id"]),
+						TransactionID: fmt.Sprint(row["transaction_id"]),
+					}
+					if receiptType, ok := row["receipt_type"].(string); ok {
+						transaction.ReceiptType = null.StringFrom(receiptType)
+					}
+					addKey(transaction.ReceiptKey())
+				}
+			}
+
+			return encoder.Encode(row)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "[backup.Backup] failed to back up %s", table)
+		}
+
+		err = writeEntry(archive, tablesDir+table+".jsonl", manifest.CreatedAt, buf.Bytes())
+		if err != nil {
+			return nil, errors.Wrapf(err, "[backup.Backup] failed to write %s", table)
+		}
+	}
+
+	for _, key := range keys {
+		data, err := s.readBlob(ctx, key)
+		if errors.Is(err, ledger.ErrBlobNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "[backup.Backup] failed to read blob %s", key)
+		}
+
+		err = writeEntry(archive, blobsDir+key, manifest.CreatedAt, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[backup.Backup] failed to write blob %s", key)
+		}
+
+		stats.Blobs++
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "[backup.Backup] failed to close archive")
+	}
+
+	return stats, errors.Wrap(gz.Close(), "[backup.Backup] failed to close archive")
+
+}
+
+// Restore reads an archive written by Backup and writes its rows and blobs back. Rows that already
+// exist are overwritten with the values in the archive, so restoring an archive more than once, or
+// over the data it was taken from, leaves the same data behind. Access tokens that were left out of
+// the archive are kept as they are
+func (s *service) Restore(ctx context.Context, r io.Reader) (*ledger.BackupStats, error) {
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "[backup.Restore] failed to open archive")
+	}
+	defer gz.Close()
+
+	archive := tar.NewReader(gz)
+
+	header, err := archive.Next()
+	if err != nil {
+		return nil, errors.Wrap(err, "[backup.Restore] failed to read archive")
+	}
+
+	if header.Name != manifestName {
+		return nil, errors.Errorf("[backup.Restore] archive does not start with a manifest, found %s", header.Name)
+	}
+
+	var manifest = new(ledger.BackupManifest)
+	err = json.NewDecoder(archive).Decode(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "[backup.Restore] failed to decode manifest")
+	}
+
+	if manifest.Version != ledger.BackupVersion {
+		return nil, errors.Errorf("[backup.Restore] archive was written with version %d, only version %d can be restored", manifest.Version, ledger.BackupVersion)
+	}
+
+	var known = make(map[string]bool)
+	for _, table := range s.backups.BackupTables() {
+		known[table] = true
+	}
+
+	stats := &ledger.BackupStats{
+		Manifest: manifest,
+		Rows:     make(map[string]int, len(manifest.Tables)),
+	}
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "[backup.Restore] failed to read archive")
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(header.Name, tablesDir):
+			table := strings.TrimSuffix(strings.TrimPrefix(header.Name, tablesDir), ".jsonl")
+			if !known[table] {
+				return nil, errors.Errorf("[backup.Restore] archive contains %s, which is not a table that can be restored", table)
+			}
+
+			rows, err := readRows(archive)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[backup.Restore] failed to read %s", table)
+			}
+
+			err = s.backups.RestoreRows(ctx, table, rows)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[backup.Restore] failed to restore %s", table)
+			}
+
+			stats.Rows[table] = len(rows)
+		case strings.HasPrefix(header.Name, blobsDir):
+			key := strings.TrimPrefix(header.Name, blobsDir)
+			if key == "" || path.Clean(key) != key || strings.HasPrefix(key, "../") {
+				return nil, errors.Errorf("[backup.Restore] archive contains a blob with an invalid key %q", key)
+			}
+
+			data, err := io.ReadAll(archive)
+			if err != nil {
+				return nil, errors.Wrapf(err, "[backup.Restore] failed to read blob %s", key)
+			}
+
+			err = s.blobs.Put(ctx, key, bytes.NewReader(data), int64(len(data)), http.DetectContentType(data))
+			if err != nil {
+				return nil, errors.Wrapf(err, "[backup.Restore] failed to write blob %s", key)
+			}
+
+			stats.Blobs++
+		}
+	}
+
+	return stats, nil
+
+}
+
+func (s *service) readBlob(ctx context.Context, key string) ([]byte, error) {
+
+	body, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+
+}
+
+func writeEntry(archive *tar.Writer, name string, modTime time.Time, data []byte) error {
+
+	err := archive.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0600,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = archive.Write(data)
+
+	return err
+
+}
+
+// readRows decodes a file of JSON lines. Numbers are kept as they were written so that ids and
+// amounts are not rounded through a float
+func readRows(r io.Reader) ([]ledger.BackupRow, error) {
+
+	var rows = make([]ledger.BackupRow, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+
+		var row ledger.BackupRow
+		err := decoder.Decode(&row)
+		if err != nil {
+			return nil, err
+		}
+
+		for column, value := range row {
+			if number, ok := value.(json.Number); ok {
+				row[column] = number.String()
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, scanner.Err()
+
+}
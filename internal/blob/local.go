@@ -0,0 +1,170 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+)
+
+// LocalStore is a BlobStore that writes blobs to a directory on the local disk. Signed urls
+// point back at the API, which serves them through ServeHTTP once the signature is verified
+type LocalStore struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocalStore returns a LocalStore that writes blobs beneath root. baseURL is the url that
+// ServeHTTP is mounted at and secret is the key used to sign download urls
+func NewLocalStore(root, baseURL string, secret []byte) (*LocalStore, error) {
+
+	if len(secret) == 0 {
+		return nil, errors.New("[blob.NewLocalStore] a signing secret is required")
+	}
+
+	err := os.MkdirAll(root, 0o750)
+	if err != nil {
+		return nil, errors.Wrap(err, "[blob.NewLocalStore] failed to create root directory")
+	}
+
+	return &LocalStore{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+	}, nil
+
+}
+
+// filename resolves key to a path beneath the root directory. Cleaning the key as an
+// absolute path first ensures that keys containing .. cannot escape the root
+func (s *LocalStore) filename(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+
+	filename := s.filename(key)
+
+	err := os.MkdirAll(filepath.Dir(filename), 0o750)
+	if err != nil {
+		return errors.Wrap(err, "[blob.LocalStore.Put] failed to create directory")
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return errors.Wrap(err, "[blob.LocalStore.Put] failed to create temporary file")
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, body)
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "[blob.LocalStore.Put] failed to write blob")
+	}
+
+	err = f.Close()
+	if err != nil {
+		return errors.Wrap(err, "[blob.LocalStore.Put] failed to close blob")
+	}
+
+	return errors.Wrap(os.Rename(f.Name(), filename), "[blob.LocalStore.Put] failed to move blob into place")
+
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	f, err := os.Open(s.filename(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ledger.ErrBlobNotFound
+	}
+
+	return f, errors.Wrap(err, "[blob.LocalStore.Get]")
+
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+
+	err := os.Remove(s.filename(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return errors.Wrap(err, "[blob.LocalStore.Delete]")
+
+}
+
+func (s *LocalStore) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+
+	values := url.Values{}
+	values.Set("expires", expiresAt)
+	values.Set("signature", s.sign(key, expiresAt))
+
+	return fmt.Sprintf("%s/%s?%s", s.baseURL, strings.TrimPrefix(key, "/"), values.Encode()), nil
+
+}
+
+func (s *LocalStore) sign(key, expiresAt string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	_, _ = mac.Write([]byte(key + "\n" + expiresAt))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP serves the blob named by the request path once the expiry and signature
+// generated by SignedURL have been verified. It should be mounted at baseURL with any
+// prefix stripped from the request path
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	expiresAt := r.URL.Query().Get("expires")
+	signature := r.URL.Query().Get("signature")
+
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		http.Error(w, "url has expired", http.StatusForbidden)
+		return
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expiresAt))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	f, err := os.Open(s.filename(key))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, path.Base(key), info.ModTime(), f)
+
+}
@@ -0,0 +1,87 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+)
+
+type s3Store struct {
+	client *s3.Client
+	bucket string
+}
+
+// NewS3Store returns a BlobStore backed by an S3 compatible bucket
+func NewS3Store(client *s3.Client, bucket string) ledger.BlobStore {
+	return &s3Store{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	}
+	if size > 0 {
+		input.ContentLength = size
+	}
+
+	_, err := s.client.PutObject(ctx, input)
+
+	return errors.Wrap(err, "[blob.s3Store.Put]")
+
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NoSuchKey
+		if errors.As(err, &notFound) {
+			return nil, ledger.ErrBlobNotFound
+		}
+		return nil, errors.Wrap(err, "[blob.s3Store.Get]")
+	}
+
+	return output.Body, nil
+
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+
+	return errors.Wrap(err, "[blob.s3Store.Delete]")
+
+}
+
+func (s *s3Store) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+
+	get, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", errors.Wrap(err, "[blob.s3Store.SignedURL]")
+	}
+
+	return get.URL, nil
+
+}
@@ -0,0 +1,195 @@
+// Package budget provides service access to budgets and the progress made against them
+package budget
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/report"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+type Service interface {
+	BudgetStatus(ctx context.Context, user *ledger.User, date time.Time) ([]*ledger.BudgetStatus, error)
+	ledger.BudgetRepository
+}
+
+type service struct {
+	report report.Service
+
+	ledger.BudgetRepository
+}
+
+func New(report report.Service, budgets ledger.BudgetRepository) Service {
+	return &service{
+		report:           report,
+		BudgetRepository: budgets,
+	}
+}
+
+func (s *service) CreateBudget(ctx context.Context, budget *ledger.Budget) (*ledger.Budget, error) {
+
+	budget.BudgetID = uuid.Must(uuid.NewV4()).String()
+	if budget.StartDate.IsZero() {
+		budget.StartDate = time.Now()
+	}
+
+	err := validateBudget(budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.BudgetRepository.CreateBudget(ctx, budget)
+
+}
+
+func (s *service) UpdateBudget(ctx context.Context, budgetID string, budget *ledger.Budget) (*ledger.Budget, error) {
+
+	err := validateBudget(budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.BudgetRepository.UpdateBudget(ctx, budgetID, budget)
+
+}
+
+// validateBudget checks the budget can be tracked and moves its start date to the start of the
+// period it falls in, so that every period of the budget is a whole one
+func validateBudget(budget *ledger.Budget) error {
+
+	budget.Name = strings.TrimSpace(budget.Name)
+	if budget.Name == "" {
+		return errors.New("a name is required")
+	}
+
+	if !budget.Scope.Valid() {
+		return errors.Errorf("%s is not a valid budget scope, valid scopes are category and merchant", budget.Scope)
+	}
+
+	if budget.ScopeID == "" {
+		return errors.Errorf("the id of the %s the budget applies to is required", budget.Scope)
+	}
+
+	switch budget.Period {
+	case ledger.IntervalWeek, ledger.IntervalMonth, ledger.IntervalYear:
+	default:
+		return errors.Errorf("%s is not a valid budget period, valid periods are week, month and year", budget.Period)
+	}
+
+	if budget.Amount <= 0 {
+		return errors.New("amount must be greater than zero")
+	}
+
+	budget.StartDate = budget.Period.Start(budget.StartDate)
+
+	return nil
+
+}
+
+// BudgetStatus returns the progress of each of the users budgets through the period that date falls in
+func (s *service) BudgetStatus(ctx context.Context, user *ledger.User, date time.Time) ([]*ledger.BudgetStatus, error) {
+
+	budgets, err := s.Budgets(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[budget.BudgetStatus] failed to fetch budgets")
+	}
+
+	var statuses = make([]*ledger.BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		status, err := s.budgetStatus(ctx, user, budget, date)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[budget.BudgetStatus] failed to calculate status of budget %s", budget.BudgetID)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+
+}
+
+func (s *service) budgetStatus(ctx context.Context, user *ledger.User, budget *ledger.Budget, date time.Time) (*ledger.BudgetStatus, error) {
+
+	start := budget.Period.Start(date)
+	status := &ledger.BudgetStatus{
+		Budget:      budget,
+		PeriodStart: start,
+		PeriodEnd:   budget.Period.Next(start).AddDate(0, 0, -1),
+	}
+
+	spent, unconverted, err := s.spent(ctx, user, budget, status.PeriodStart, status.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each earlier period contributes whatever was left of it, or takes away whatever it was
+	// overspent by. That is the same as the budget for all of them less everything spent in them
+	if budget.Rollover && budget.StartDate.Before(start) {
+		var periods int
+		for period := budget.StartDate; period.Before(start); period = budget.Period.Next(period) {
+			periods++
+		}
+
+		previouslySpent, _, err := s.spent(ctx, user, budget, budget.StartDate, start.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+
+		status.Rollover = math.Round((float64(periods)*budget.Amount-previouslySpent)*100) / 100
+	}
+
+	status.Limit = budget.Amount + status.Rollover
+	status.Spent = spent
+	status.Unconverted = unconverted
+	status.Remaining = math.Round((status.Limit-spent)*100) / 100
+	if status.Limit > 0 {
+		status.PercentUsed = null.Float64From(math.Round(spent/status.Limit*10000) / 100)
+	}
+
+	return status, nil
+
+}
+
+// spent returns the net amount spent against the budget between from and to, inclusive, and the
+// number of transactions that could not be converted into the base currency of the user. Refunds
+// reduce the amount spent and each transaction is converted at the rate for the day it posted
+func (s *service) spent(ctx context.Context, user *ledger.User, budget *ledger.Budget, from, to time.Time) (float64, int64, error) {
+
+	filters := &ledger.TransactionFilter{
+		StartDate:     null.TimeFrom(from),
+		EndDate:       null.TimeFrom(to),
+		DateInclusive: null.BoolFrom(true),
+	}
+
+	var groupBy ledger.SpendingGroupBy
+	switch budget.Scope {
+	case ledger.BudgetScopeCategory:
+		groupBy = ledger.SpendingGroupByCategory
+		filters.CategoryID = null.StringFrom(budget.ScopeID)
+	case ledger.BudgetScopeMerchant:
+		groupBy = ledger.SpendingGroupByMerchant
+		filters.MerchantID = null.StringFrom(budget.ScopeID)
+	}
+
+	groups, err := s.report.Spending(ctx, user, groupBy, filters)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total float64
+	var unconverted int64
+	for _, group := range groups {
+		total += group.Total
+		unconverted += group.Unconverted
+	}
+
+	// Outflows are negative, spending is reported as a positive amount
+	return math.Round(-total*100) / 100, unconverted, nil
+
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+type exportService interface {
+	FetchTransactionExport(ctx context.Context, token string) (*ledger.TransactionExport, error)
+	SaveTransactionExport(ctx context.Context, export *ledger.TransactionExport, duration time.Duration) error
+}
+
+func transactionExportKey(token string) string {
+	return fmt.Sprintf("ledger::exports::transactions::%s", token)
+}
+
+// FetchTransactionExport returns nil when there is no export for the token or it has expired
+func (s *service) FetchTransactionExport(ctx context.Context, token string) (*ledger.TransactionExport, error) {
+
+	result, err := s.client.Get(ctx, transactionExportKey(token)).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, errors.Wrap(err, "[cache.FetchTransactionExport]")
+	}
+
+	if err != nil && errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	var export = new(ledger.TransactionExport)
+	err = json.Unmarshal(result, export)
+	if err != nil {
+		return nil, errors.Wrap(err, "[cache.FetchTransactionExport]")
+	}
+
+	return export, nil
+
+}
+
+func (s *service) SaveTransactionExport(ctx context.Context, export *ledger.TransactionExport, duration time.Duration) error {
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return errors.Wrap(err, "[cache.SaveTransactionExport]")
+	}
+
+	_, err = s.client.Set(ctx, transactionExportKey(export.Token), string(data), duration).Result()
+
+	return errors.Wrap(err, "[cache.SaveTransactionExport]")
+
+}
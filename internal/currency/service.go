@@ -0,0 +1,135 @@
+// Package currency provides service access to exchange rates and currency conversion
+package currency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+)
+
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+type Service interface {
+	Convert(ctx context.Context, amount float64, from, to string, date time.Time) (float64, error)
+	ImportExchangeRates(ctx context.Context, r io.Reader) (int, error)
+	ledger.ExchangeRateRepository
+}
+
+type service struct {
+	ledger.ExchangeRateRepository
+}
+
+func New(rates ledger.ExchangeRateRepository) Service {
+	return &service{
+		ExchangeRateRepository: rates,
+	}
+}
+
+// Convert converts amount from one currency to another using the most recent rate published
+// on or before date. If only the inverse pair has been loaded, its reciprocal is used.
+// ErrExchangeRateNotFound is returned when neither pair has a rate
+func (s *service) Convert(ctx context.Context, amount float64, from, to string, date time.Time) (float64, error) {
+
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == "" || from == to {
+		return amount, nil
+	}
+
+	rate, err := s.ExchangeRate(ctx, from, to, date)
+	if err == nil {
+		return amount * rate.Rate, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.Wrap(err, "[currency.Convert] failed to fetch exchange rate")
+	}
+
+	rate, err = s.ExchangeRate(ctx, to, from, date)
+	if err == nil {
+		return amount / rate.Rate, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.Wrap(err, "[currency.Convert] failed to fetch inverse exchange rate")
+	}
+
+	return 0, errors.Wrapf(ErrExchangeRateNotFound, "%s to %s on %s", from, to, date.Format("2006-01-02"))
+
+}
+
+// ImportExchangeRates reads rates from a CSV document with the columns date, from, to, rate.
+// The date column is formatted as YYYY-MM-DD and a leading header row is optional
+func (s *service) ImportExchangeRates(ctx context.Context, r io.Reader) (int, error) {
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return 0, errors.Wrap(err, "[currency.ImportExchangeRates] failed to read csv")
+	}
+
+	if len(records) > 0 && strings.EqualFold(records[0][0], "date") {
+		records = records[1:]
+	}
+
+	var rates = make([]*ledger.ExchangeRate, 0, len(records))
+	for i, record := range records {
+		date, err := time.Parse("2006-01-02", record[0])
+		if err != nil {
+			return 0, errors.Wrapf(err, "[currency.ImportExchangeRates] invalid date on row %d", i+1)
+		}
+
+		rate, err := strconv.ParseFloat(record[3], 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "[currency.ImportExchangeRates] invalid rate on row %d", i+1)
+		}
+
+		rates = append(rates, &ledger.ExchangeRate{
+			Date:         date,
+			FromCurrency: record[1],
+			ToCurrency:   record[2],
+			Rate:         rate,
+		})
+	}
+
+	err = s.SaveExchangeRates(ctx, rates)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+
+}
+
+// SaveExchangeRates validates and normalizes the provided rates before persisting them
+func (s *service) SaveExchangeRates(ctx context.Context, rates []*ledger.ExchangeRate) error {
+
+	for _, rate := range rates {
+		rate.FromCurrency = strings.ToUpper(strings.TrimSpace(rate.FromCurrency))
+		rate.ToCurrency = strings.ToUpper(strings.TrimSpace(rate.ToCurrency))
+
+		if rate.FromCurrency == "" || rate.ToCurrency == "" {
+			return errors.New("[currency.SaveExchangeRates] currency codes must not be empty")
+		}
+
+		if rate.FromCurrency == rate.ToCurrency {
+			return errors.Errorf("[currency.SaveExchangeRates] cannot store a rate from %s to itself", rate.FromCurrency)
+		}
+
+		if rate.Rate <= 0 {
+			return errors.Errorf("[currency.SaveExchangeRates] rate for %s to %s must be greater than zero", rate.FromCurrency, rate.ToCurrency)
+		}
+	}
+
+	return s.ExchangeRateRepository.SaveExchangeRates(ctx, rates)
+
+}
@@ -0,0 +1,88 @@
+// Package event provides the emitter that services announce changes to the data of a user through, and
+// that delivery channels such as webhooks subscribe to
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Service interface {
+	Subscribe(handler ledger.EventHandler)
+	Emit(ctx context.Context, userID uuid.UUID, eventType ledger.EventType, data interface{})
+	EmitForItem(ctx context.Context, itemID string, eventType ledger.EventType, data interface{}) error
+}
+
+type service struct {
+	logger *logrus.Logger
+	users  ledger.UserRepository
+
+	mu       sync.RWMutex
+	handlers []ledger.EventHandler
+}
+
+func New(logger *logrus.Logger, users ledger.UserRepository) Service {
+	return &service{
+		logger: logger,
+		users:  users,
+	}
+}
+
+// Subscribe registers handler to be called with every event emitted after it
+func (s *service) Subscribe(handler ledger.EventHandler) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers = append(s.handlers, handler)
+
+}
+
+// Emit hands an event of eventType for the user to every subscribed handler
+func (s *service) Emit(ctx context.Context, userID uuid.UUID, eventType ledger.EventType, data interface{}) {
+
+	event := &ledger.Event{
+		EventID:   uuid.Must(uuid.NewV4()).String(),
+		Type:      eventType,
+		UserID:    userID,
+		Data:      data,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"event_id":   event.EventID,
+		"event_type": event.Type,
+		"user_id":    userID,
+	}).Debug("emitting event")
+
+	s.mu.RLock()
+	handlers := s.handlers
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, event)
+	}
+
+}
+
+// EmitForItem emits an event of eventType to every user of the item
+func (s *service) EmitForItem(ctx context.Context, itemID string, eventType ledger.EventType, data interface{}) error {
+
+	users, err := s.users.UsersByItemID(ctx, itemID)
+	if err != nil {
+		return errors.Wrap(err, "[event.EmitForItem] failed to fetch users of item")
+	}
+
+	for _, user := range users {
+		s.Emit(ctx, user.ID, eventType, data)
+	}
+
+	return nil
+
+}
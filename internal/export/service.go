@@ -0,0 +1,165 @@
+// Package export provides service access to exports of a users transactions
+package export
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/cache"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+type Service interface {
+	ValidateTransactionExportOptions(options *ledger.TransactionExportOptions) error
+	WriteTransactionsCSV(ctx context.Context, w io.Writer, userID uuid.UUID, options *ledger.TransactionExportOptions) error
+	RequestTransactionExport(ctx context.Context, user *ledger.User, options *ledger.TransactionExportOptions) (*ledger.TransactionExport, error)
+	TransactionExport(ctx context.Context, token string) (*ledger.TransactionExport, error)
+}
+
+type service struct {
+	cache cache.Service
+
+	transactions ledger.TransactionRepository
+}
+
+func New(cache cache.Service, transactions ledger.TransactionRepository) Service {
+	return &service{
+		cache:        cache,
+		transactions: transactions,
+	}
+}
+
+// exportTTL is how long a requested export can be downloaded for
+const exportTTL = time.Minute * 15
+
+// ValidateTransactionExportOptions checks the selected columns and date format, defaulting to every
+// column and ISO 8601 dates when they are not set
+func (s *service) ValidateTransactionExportOptions(options *ledger.TransactionExportOptions) error {
+
+	if len(options.Columns) == 0 {
+		options.Columns = ledger.AllExportColumns
+	}
+
+	for _, column := range options.Columns {
+		if !column.Valid() {
+			return errors.Errorf("%s is not a valid export column", column)
+		}
+	}
+
+	if options.DateFormat == "" {
+		options.DateFormat = ledger.ExportDateFormatISO
+	}
+
+	if !options.DateFormat.Valid() {
+		return errors.Errorf("%s is not a valid date format, valid formats are iso, us and eu", options.DateFormat)
+	}
+
+	return nil
+
+}
+
+// WriteTransactionsCSV writes the users transactions that match the options to w as CSV, with a
+// header row of the selected columns. Transactions are written as they are read from the database
+func (s *service) WriteTransactionsCSV(ctx context.Context, w io.Writer, userID uuid.UUID, options *ledger.TransactionExportOptions) error {
+
+	err := s.ValidateTransactionExportOptions(options)
+	if err != nil {
+		return errors.Wrap(err, "[export.WriteTransactionsCSV]")
+	}
+
+	writer := csv.NewWriter(w)
+
+	var record = make([]string, len(options.Columns))
+	for i, column := range options.Columns {
+		record[i] = string(column)
+	}
+
+	err = writer.Write(record)
+	if err != nil {
+		return errors.Wrap(err, "[export.WriteTransactionsCSV] failed to write header")
+	}
+
+	err = s.transactions.ExportTransactions(ctx, userID, options.Filters, func(row *ledger.TransactionExportRow) error {
+		for i, column := range options.Columns {
+			record[i] = escapeFormula(column, row.Value(column, options.DateFormat))
+		}
+
+		return writer.Write(record)
+	})
+	if err != nil {
+		return errors.Wrap(err, "[export.WriteTransactionsCSV] failed to write transactions")
+	}
+
+	writer.Flush()
+
+	return errors.Wrap(writer.Error(), "[export.WriteTransactionsCSV]")
+
+}
+
+// textColumns are the columns that hold text that came from an institution or the user
+var textColumns = map[ledger.ExportColumn]bool{
+	ledger.ExportColumnName:              true,
+	ledger.ExportColumnMerchant:          true,
+	ledger.ExportColumnCategory:          true,
+	ledger.ExportColumnCategoryHierarchy: true,
+	ledger.ExportColumnAccount:           true,
+	ledger.ExportColumnHiddenReason:      true,
+}
+
+// escapeFormula stops spreadsheets from evaluating text that starts like a formula by prefixing it
+// with a quote. Amounts are left alone so that negative amounts are still read as numbers
+func escapeFormula(column ledger.ExportColumn, value string) string {
+	if textColumns[column] && value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// RequestTransactionExport stores the options under a new token that can be used to download the
+// export without a bearer token until it expires
+func (s *service) RequestTransactionExport(ctx context.Context, user *ledger.User, options *ledger.TransactionExportOptions) (*ledger.TransactionExport, error) {
+
+	err := s.ValidateTransactionExportOptions(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "[export.RequestTransactionExport]")
+	}
+
+	var token = make([]byte, 32)
+	_, err = rand.Read(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "[export.RequestTransactionExport] failed to generate token")
+	}
+
+	export := &ledger.TransactionExport{
+		Token:     hex.EncodeToString(token),
+		UserID:    user.ID,
+		Options:   options,
+		ExpiresAt: time.Now().Add(exportTTL),
+	}
+
+	err = s.cache.SaveTransactionExport(ctx, export, exportTTL)
+	if err != nil {
+		return nil, errors.Wrap(err, "[export.RequestTransactionExport] failed to save export")
+	}
+
+	return export, nil
+
+}
+
+// TransactionExport returns the export requested with token, or nil when there is no such export or
+// it has expired
+func (s *service) TransactionExport(ctx context.Context, token string) (*ledger.TransactionExport, error) {
+
+	export, err := s.cache.FetchTransactionExport(ctx, token)
+
+	return export, errors.Wrap(err, "[export.TransactionExport]")
+
+}
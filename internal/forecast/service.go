@@ -0,0 +1,174 @@
+// Package forecast provides service access to the expected balances of a users accounts over the coming days
+package forecast
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/recurring"
+	"github.com/pkg/errors"
+)
+
+type Service interface {
+	Forecast(ctx context.Context, user *ledger.User, days int) ([]*ledger.AccountForecast, error)
+	RunForecast(ctx context.Context, user *ledger.User) ([]*ledger.AccountForecast, error)
+	ledger.ForecastRepository
+}
+
+type service struct {
+	recurring recurring.Service
+
+	accounts     ledger.AccountRepository
+	transactions ledger.TransactionRepository
+
+	ledger.ForecastRepository
+}
+
+func New(recurring recurring.Service, accounts ledger.AccountRepository, transactions ledger.TransactionRepository, forecasts ledger.ForecastRepository) Service {
+	return &service{
+		recurring:          recurring,
+		accounts:           accounts,
+		transactions:       transactions,
+		ForecastRepository: forecasts,
+	}
+}
+
+// maxForecastDays is how far ahead forecasts are run, shorter forecasts are truncated from it
+const maxForecastDays = 90
+
+// discretionaryDays is how much history the average daily discretionary spend is taken from
+const discretionaryDays = 90
+
+// Forecast returns the forecast of each of the users accounts over the next 30, 60 or 90 days. Forecasts
+// are re-run when they were last run before today
+func (s *service) Forecast(ctx context.Context, user *ledger.User, days int) ([]*ledger.AccountForecast, error) {
+
+	if days != 30 && days != 60 && days != 90 {
+		return nil, errors.New("[forecast.Forecast] days must be 30, 60 or 90")
+	}
+
+	forecasts, err := s.AccountForecastsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[forecast.Forecast] failed to fetch forecasts")
+	}
+
+	today := ledger.IntervalDay.Start(time.Now())
+	stale := len(forecasts) == 0
+	for _, forecast := range forecasts {
+		if forecast.UpdatedAt.Before(today) {
+			stale = true
+		}
+	}
+
+	if stale {
+		forecasts, err = s.RunForecast(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var truncated = make([]*ledger.AccountForecast, 0, len(forecasts))
+	for _, forecast := range forecasts {
+		truncated = append(truncated, forecast.Truncate(days))
+	}
+
+	return truncated, nil
+
+}
+
+// RunForecast projects the balance of each of the users accounts day by day from its current balance,
+// applying the transactions expected from recurring series and the average daily discretionary spend
+// of the account over the last 90 days, and stores the result. Credit and loan accounts are left out
+func (s *service) RunForecast(ctx context.Context, user *ledger.User) ([]*ledger.AccountForecast, error) {
+
+	accounts, err := s.accounts.AccountsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[forecast.RunForecast] failed to fetch accounts")
+	}
+
+	projections, err := s.recurring.Upcoming(ctx, user, maxForecastDays)
+	if err != nil {
+		return nil, errors.Wrap(err, "[forecast.RunForecast] failed to project recurring series")
+	}
+
+	var recurringByAccount = make(map[string]map[string]float64, len(projections))
+	for _, projection := range projections {
+		amounts := make(map[string]float64)
+		for _, transaction := range projection.Transactions {
+			amounts[transaction.Date.Format("2006-01-02")] += transaction.Amount
+		}
+		recurringByAccount[projection.ItemID+":"+projection.AccountID] = amounts
+	}
+
+	today := ledger.IntervalDay.Start(time.Now())
+	spending, err := s.transactions.DiscretionarySpending(ctx, user.ID, today.AddDate(0, 0, -discretionaryDays), today.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, errors.Wrap(err, "[forecast.RunForecast] failed to fetch discretionary spending")
+	}
+
+	var categoriesByAccount = make(map[string]ledger.ForecastCategories)
+	for _, spend := range spending {
+		key := spend.ItemID + ":" + spend.AccountID
+		categoriesByAccount[key] = append(categoriesByAccount[key], &ledger.ForecastCategory{
+			CategoryID:   spend.CategoryID,
+			DailyAverage: math.Round(spend.Total/discretionaryDays*100) / 100,
+		})
+	}
+
+	var forecasts = make([]*ledger.AccountForecast, 0, len(accounts))
+	for _, account := range accounts {
+		if account.Balance == nil || ledger.IsLiability(account.Type.String) {
+			continue
+		}
+
+		key := account.ItemID + ":" + account.AccountID
+		categories := categoriesByAccount[key]
+		sort.SliceStable(categories, func(i, j int) bool {
+			return categories[i].DailyAverage > categories[j].DailyAverage
+		})
+
+		var discretionary float64
+		for _, category := range categories {
+			discretionary += category.DailyAverage
+		}
+
+		forecast := &ledger.AccountForecast{
+			ItemID:     account.ItemID,
+			AccountID:  account.AccountID,
+			Currency:   account.Balance.CurrencyCode(),
+			Current:    account.Balance.Current,
+			Floor:      account.BalanceFloor.Float64,
+			Days:       make(ledger.ForecastDays, 0, maxForecastDays),
+			Categories: categories,
+		}
+
+		balance := forecast.Current
+		for i := 0; i < maxForecastDays; i++ {
+			date := today.AddDate(0, 0, i)
+			day := &ledger.ForecastDay{
+				Date:          date,
+				Recurring:     math.Round(recurringByAccount[key][date.Format("2006-01-02")]*100) / 100,
+				Discretionary: -math.Round(discretionary*100) / 100,
+			}
+
+			balance = math.Round((balance+day.Recurring+day.Discretionary)*100) / 100
+			day.Balance = balance
+			forecast.Days = append(forecast.Days, day)
+		}
+
+		forecast.Summarise()
+
+		err = s.SaveAccountForecast(ctx, forecast)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[forecast.RunForecast] failed to save forecast for account %s", account.AccountID)
+		}
+
+		forecasts = append(forecasts, forecast)
+	}
+
+	return forecasts, nil
+
+}
@@ -2,6 +2,7 @@ package importer
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -10,13 +11,18 @@ import (
 
 	"github.com/ddouglas/ledger"
 	"github.com/ddouglas/ledger/internal/account"
+	"github.com/ddouglas/ledger/internal/alert"
+	"github.com/ddouglas/ledger/internal/event"
+	"github.com/ddouglas/ledger/internal/forecast"
 	"github.com/ddouglas/ledger/internal/gateway"
 	"github.com/ddouglas/ledger/internal/item"
 	"github.com/ddouglas/ledger/internal/transaction"
+	"github.com/ddouglas/ledger/internal/user"
 	"github.com/go-redis/redis/v8"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/sirupsen/logrus"
 	"github.com/ulule/deepcopier"
+	"github.com/volatiletech/null"
 )
 
 type Service interface {
@@ -28,8 +34,12 @@ type Service interface {
 
 type service struct {
 	account     account.Service
+	alert       alert.Service
+	event       event.Service
+	forecast    forecast.Service
 	item        item.Service
 	transaction transaction.Service
+	user        user.Service
 
 	redis    *redis.Client
 	gateway  gateway.Service
@@ -45,8 +55,12 @@ func New(
 	client *redis.Client,
 	gateway gateway.Service,
 	account account.Service,
+	alert alert.Service,
+	event event.Service,
+	forecast forecast.Service,
 	item item.Service,
 	transaction transaction.Service,
+	user user.Service,
 	webhook ledger.WebhookRepository,
 ) Service {
 	return &service{
@@ -56,8 +70,12 @@ func New(
 		redis:             client,
 		gateway:           gateway,
 		account:           account,
+		alert:             alert,
+		event:             event,
+		forecast:          forecast,
 		item:              item,
 		transaction:       transaction,
+		user:              user,
 	}
 }
 
@@ -116,6 +134,8 @@ func (s *service) processMessage(ctx context.Context, message *ledger.WebhookMes
 	switch message.WebhookType {
 	case "TRANSACTIONS":
 		s.processTransactionUpdate(ctx, message)
+	case "ITEM":
+		s.processItemUpdate(ctx, message)
 	default:
 		s.logger.WithContext(ctx).WithField("message", message).Error("recieved message with unhandled webhook type")
 	}
@@ -139,10 +159,15 @@ func (s *service) processTransactionUpdate(ctx context.Context, message *ledger.
 	item, err := s.gateway.Item(ctx, existingItem.AccessToken)
 	if err != nil {
 		entry.WithError(err).Error("failed to fetch plaid item with accessToken")
+		s.emitItemError(ctx, existingItem.ItemID, err.Error())
 		return
 	}
 	seg.End()
 
+	if item.Error.Valid && !existingItem.Error.Valid {
+		s.emitItemError(ctx, existingItem.ItemID, item.Error.String)
+	}
+
 	err = deepcopier.Copy(item).To(existingItem)
 	if err != nil {
 		entry.WithError(err).Error("failed to copy plaid item to ledger item")
@@ -161,16 +186,23 @@ func (s *service) processTransactionUpdate(ctx context.Context, message *ledger.
 	accounts, err := s.gateway.Accounts(ctx, existingItem.AccessToken)
 	if err != nil {
 		entry.WithError(err).Error("failed to update item")
+		s.emitItemError(ctx, existingItem.ItemID, err.Error())
 		return
 	}
 
 	for _, account := range accounts {
 		account.ItemID = existingItem.ItemID
-		_, err = s.account.UpdateAccount(ctx, existingItem.ItemID, account.AccountID, account)
+		updated, err := s.account.UpdateAccount(ctx, existingItem.ItemID, account.AccountID, account)
 		if err != nil {
 			entry.WithError(err).WithField("account_id", account.AccountID).Error("failed to update account")
 			return
 		}
+
+		// A missing snapshot only leaves a gap in the balance history, it should not stop the import
+		err = s.account.SnapshotAccountBalance(ctx, updated)
+		if err != nil {
+			entry.WithError(err).WithField("account_id", account.AccountID).Error("failed to snapshot account balance")
+		}
 	}
 	seg.End()
 
@@ -207,11 +239,20 @@ func (s *service) processTransactionUpdate(ctx context.Context, message *ledger.
 	transactions, err := s.gateway.Transactions(ctx, item.AccessToken, start, end, accountIDs)
 	if err != nil {
 		entry.WithError(err).Error("failed to fetch transactions")
+		s.emitItemError(ctx, existingItem.ItemID, err.Error())
 		return
 	}
 	seg.AddAttribute("transactionCount", len(transactions))
 	seg.End()
 
+	// Transactions that are new are found before processing, which creates them
+	seg = txn.StartSegment("finding new transactions")
+	created, err := s.newTransactions(ctx, item.ItemID, transactions)
+	if err != nil {
+		entry.WithError(err).Error("failed to find new transactions")
+	}
+	seg.End()
+
 	seg = txn.StartSegment("processing transactions")
 	err = s.transaction.ProcessTransactions(ctx, item, transactions)
 	if err != nil {
@@ -220,6 +261,27 @@ func (s *service) processTransactionUpdate(ctx context.Context, message *ledger.
 	}
 	seg.End()
 
+	// Alerts are evaluated once both the balances and the transactions of the item are up to date.
+	// Initial, historical and custom updates backfill transactions that can be months old, only
+	// those of a default update are new enough to alert on
+	var recent []*ledger.Transaction
+	if message.WebhookCode == string(ledger.CodeDefaultUpdate) {
+		recent = created
+	}
+
+	seg = txn.StartSegment("evaluating alert rules")
+	err = s.alert.EvaluateImport(ctx, item, recent)
+	if err != nil {
+		entry.WithError(err).Error("failed to evaluate alert rules")
+	}
+	seg.End()
+
+	// A forecast that is not re-run here is re-run the next day it is requested, so a failure
+	// should not stop the import
+	seg = txn.StartSegment("running forecasts")
+	s.runForecasts(ctx, existingItem.ItemID)
+	seg.End()
+
 	if existingItem.IsRefreshing {
 		entry.Info("Item is in refreshing state, updating to false")
 		existingItem.IsRefreshing = false
@@ -230,6 +292,113 @@ func (s *service) processTransactionUpdate(ctx context.Context, message *ledger.
 		}
 	}
 
+	err = s.event.EmitForItem(ctx, existingItem.ItemID, ledger.EventImportCompleted, &ledger.ImportCompletedEvent{
+		ItemID:          existingItem.ItemID,
+		Source:          ledger.ImportSourcePlaid,
+		Transactions:    len(transactions),
+		NewTransactions: len(created),
+		Created:         created,
+		WebhookCode:     ledger.WebhookCode(message.WebhookCode),
+	})
+	if err != nil {
+		entry.WithError(err).Error("failed to emit import completed event")
+	}
+
 	entry.Info("transactions processed successfully")
 
 }
+
+// processItemUpdate records the error Plaid reports for an item, which usually means the user has to
+// log in to their institution again before it can be imported
+func (s *service) processItemUpdate(ctx context.Context, message *ledger.WebhookMessage) {
+
+	entry := s.logger.WithContext(ctx).WithField("item_id", message.ItemID)
+
+	if message.WebhookCode != "ERROR" || message.Error == nil {
+		entry.WithField("webhook_code", message.WebhookCode).Debug("ignoring item webhook")
+		return
+	}
+
+	item, err := s.item.Item(ctx, message.ItemID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch item with itemID provided by message")
+		return
+	}
+
+	item.Error = null.StringFrom(message.Error.Error())
+	_, err = s.item.UpdateItem(ctx, item.ItemID, item)
+	if err != nil {
+		entry.WithError(err).Error("failed to update item")
+		return
+	}
+
+	s.emitItemError(ctx, item.ItemID, item.Error.String)
+
+}
+
+// emitItemError announces that the item could not be imported to every user of the item
+func (s *service) emitItemError(ctx context.Context, itemID, message string) {
+
+	err := s.event.EmitForItem(ctx, itemID, ledger.EventItemError, &ledger.ItemErrorEvent{
+		ItemID: itemID,
+		Error:  message,
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).WithField("item_id", itemID).Error("failed to emit item error event")
+	}
+
+}
+
+// runForecasts re-runs the forecasts of every user of the item now that its balances and transactions
+// have changed, and logs the accounts that are expected to fall below their floor
+func (s *service) runForecasts(ctx context.Context, itemID string) {
+
+	entry := s.logger.WithContext(ctx).WithField("item_id", itemID)
+
+	users, err := s.user.UsersByItemID(ctx, itemID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch users of item")
+		return
+	}
+
+	for _, user := range users {
+		forecasts, err := s.forecast.RunForecast(ctx, user)
+		if err != nil {
+			entry.WithError(err).WithField("user_id", user.ID).Error("failed to run forecast")
+			continue
+		}
+
+		for _, forecast := range forecasts {
+			if !forecast.ShortfallDate.Valid {
+				continue
+			}
+
+			entry.WithFields(logrus.Fields{
+				"user_id":        user.ID,
+				"account_id":     forecast.AccountID,
+				"floor":          forecast.Floor,
+				"shortfall_date": forecast.ShortfallDate.Time.Format("2006-01-02"),
+			}).Warn("account is forecast to fall below its floor")
+		}
+	}
+
+}
+
+// newTransactions returns the transactions that have not been imported before
+func (s *service) newTransactions(ctx context.Context, itemID string, transactions []*ledger.Transaction) ([]*ledger.Transaction, error) {
+
+	var created = make([]*ledger.Transaction, 0)
+	for _, transaction := range transactions {
+		_, err := s.transaction.Transaction(ctx, itemID, transaction.TransactionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			created = append(created, transaction)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return created, nil
+
+}
@@ -3,7 +3,9 @@ package item
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/ddouglas/ledger"
 	"github.com/ddouglas/ledger/internal/account"
@@ -16,6 +18,7 @@ import (
 type Service interface {
 	ItemAccountsByUserID(ctx context.Context, userID uuid.UUID, itemID string) ([]*ledger.Account, error)
 	RegisterItem(ctx context.Context, request *ledger.RegisterItemRequest) (*ledger.Item, error)
+	CreateManualAccount(ctx context.Context, userID uuid.UUID, account *ledger.Account) (*ledger.Account, error)
 	ledger.ItemRepository
 	ledger.PlaidRepository
 }
@@ -150,3 +153,56 @@ func (s *service) RegisterItem(ctx context.Context, request *ledger.RegisterItem
 
 	return item, nil
 }
+
+// manualAccountTypes are the Plaid account types a manual account can be created with
+var manualAccountTypes = map[string]bool{
+	"depository": true,
+	"credit":     true,
+	"loan":       true,
+	"investment": true,
+	"other":      true,
+}
+
+// CreateManualAccount creates an account that is not linked through Plaid, for institutions Plaid
+// does not support. Manual accounts belong to a single item per user that is created along with
+// the first of them, and their transactions are imported from statement files
+func (s *service) CreateManualAccount(ctx context.Context, userID uuid.UUID, account *ledger.Account) (*ledger.Account, error) {
+
+	account.Name.String = strings.TrimSpace(account.Name.String)
+	if account.Name.String == "" {
+		return nil, errors.New("a name is required")
+	}
+
+	if !manualAccountTypes[account.Type.String] {
+		return nil, errors.Errorf("%s is not a valid account type, valid types are depository, credit, loan, investment and other", account.Type.String)
+	}
+
+	if account.Balance == nil || account.Balance.ISOCurrencyCode == "" {
+		return nil, errors.New("a currency is required")
+	}
+
+	itemID := ledger.ManualItemID(userID)
+	_, err := s.ItemByUserID(ctx, userID, itemID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "[item.CreateManualAccount] failed to fetch manual item")
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = s.CreateItem(ctx, &ledger.Item{
+			ItemID: itemID,
+			UserID: userID,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "[item.CreateManualAccount] failed to create manual item")
+		}
+	}
+
+	account.ItemID = itemID
+	account.AccountID = uuid.Must(uuid.NewV4()).String()
+	account.Balance.ISOCurrencyCode = strings.ToUpper(account.Balance.ISOCurrencyCode)
+
+	account, err = s.account.CreateAccount(ctx, account)
+
+	return account, errors.Wrap(err, "[item.CreateManualAccount] failed to create account")
+
+}
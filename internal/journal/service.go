@@ -0,0 +1,303 @@
+// Package journal provides service access to plain text accounting journals of a users transactions
+package journal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidAccountMapping is returned when a journal account mapping cannot be saved because of what
+// is in it. The errors it is wrapped in are safe to show to the user
+var ErrInvalidAccountMapping = errors.New("invalid journal account mapping")
+
+type Service interface {
+	WriteJournal(ctx context.Context, w io.Writer, userID uuid.UUID, format ledger.JournalFormat, filters *ledger.TransactionFilter) error
+	ledger.JournalRepository
+}
+
+type service struct {
+	accounts     ledger.AccountRepository
+	transactions ledger.TransactionRepository
+
+	ledger.JournalRepository
+}
+
+func New(accounts ledger.AccountRepository, transactions ledger.TransactionRepository, journal ledger.JournalRepository) Service {
+	return &service{
+		accounts:          accounts,
+		transactions:      transactions,
+		JournalRepository: journal,
+	}
+}
+
+// rootAccounts are the top level accounts every journal account name must start with. Beancount
+// rejects any other, so they are enforced for every format
+var rootAccounts = map[string]bool{
+	"Assets":      true,
+	"Liabilities": true,
+	"Equity":      true,
+	"Income":      true,
+	"Expenses":    true,
+}
+
+const uncategorized = "Uncategorized"
+
+func (s *service) SaveJournalAccountMapping(ctx context.Context, mapping *ledger.JournalAccountMapping) (*ledger.JournalAccountMapping, error) {
+
+	if !mapping.SourceType.Valid() {
+		return nil, errors.Wrapf(ErrInvalidAccountMapping, "%s is not a valid source type, valid types are account and category", mapping.SourceType)
+	}
+
+	if mapping.SourceID == "" {
+		return nil, errors.Wrapf(ErrInvalidAccountMapping, "the id of the %s being mapped is required", mapping.SourceType)
+	}
+
+	mapping.AccountName = strings.TrimSpace(mapping.AccountName)
+	components := strings.Split(mapping.AccountName, ":")
+	if len(components) < 2 || !rootAccounts[components[0]] {
+		return nil, errors.Wrap(ErrInvalidAccountMapping, "account name must start with one of Assets, Liabilities, Equity, Income or Expenses followed by at least one more component, such as Assets:Checking")
+	}
+
+	for _, component := range components {
+		if strings.TrimSpace(component) != component || component == "" {
+			return nil, errors.Wrapf(ErrInvalidAccountMapping, "account name %q has an empty component or a component with leading or trailing whitespace", mapping.AccountName)
+		}
+
+		if strings.Contains(component, "  ") || strings.ContainsAny(component, "\t\r\n;") {
+			return nil, errors.Wrapf(ErrInvalidAccountMapping, "account name %q cannot contain tabs, new lines, semicolons or two spaces in a row", mapping.AccountName)
+		}
+	}
+
+	return s.JournalRepository.SaveJournalAccountMapping(ctx, mapping)
+
+}
+
+// WriteJournal writes the users transactions that match filters to w as a journal in format. Each
+// transaction balances a posting to the account it was made on against a posting to an income or
+// expense account named after its category. Account names can be replaced with the users journal
+// account mappings. Transactions are written as they are read from the database
+func (s *service) WriteJournal(ctx context.Context, w io.Writer, userID uuid.UUID, format ledger.JournalFormat, filters *ledger.TransactionFilter) error {
+
+	if !format.Valid() {
+		return errors.Errorf("[journal.WriteJournal] %s is not a valid format, valid formats are ledger, hledger and beancount", format)
+	}
+
+	accounts, err := s.accounts.AccountsByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "[journal.WriteJournal] failed to fetch accounts")
+	}
+
+	var liabilities = make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		liabilities[account.AccountID] = ledger.IsLiability(account.Type.String)
+	}
+
+	mappings, err := s.JournalAccountMappings(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "[journal.WriteJournal] failed to fetch account mappings")
+	}
+
+	writer := &journalWriter{
+		Writer: bufio.NewWriter(w),
+		format: format,
+	}
+
+	// Mapped names are rewritten for the format too, so that a name that is valid in ledger does
+	// not produce a beancount journal that fails to load
+	var names = make(map[ledger.JournalSourceType]map[string]string)
+	for _, mapping := range mappings {
+		if names[mapping.SourceType] == nil {
+			names[mapping.SourceType] = make(map[string]string)
+		}
+
+		components := strings.Split(mapping.AccountName, ":")
+		names[mapping.SourceType][mapping.SourceID] = writer.accountName(components[0], components[1:]...)
+	}
+
+	if format == ledger.JournalFormatBeancount {
+		// Beancount needs every account opened before it is posted to, the plugin opens them
+		// on first use so the journal does not have to
+		writer.printf("plugin \"beancount.plugins.auto_accounts\"\n\n")
+	}
+
+	err = s.transactions.ExportTransactions(ctx, userID, filters, func(row *ledger.TransactionExportRow) error {
+
+		account, ok := names[ledger.JournalSourceTypeAccount][row.AccountID]
+		if !ok {
+			root := "Assets"
+			if liabilities[row.AccountID] {
+				root = "Liabilities"
+			}
+
+			name := row.AccountName.String
+			if row.AccountMask.Valid {
+				name = strings.TrimSpace(name + " " + row.AccountMask.String)
+			}
+
+			account = writer.accountName(root, name)
+		}
+
+		counter, ok := names[ledger.JournalSourceTypeCategory][row.CategoryID.String]
+		if !ok || !row.CategoryID.Valid {
+			root := "Income"
+			if row.Amount < 0 {
+				root = "Expenses"
+			}
+
+			hierarchy := []string(row.CategoryHierarchy)
+			if len(hierarchy) == 0 {
+				hierarchy = []string{uncategorized}
+			}
+
+			counter = writer.accountName(root, hierarchy...)
+		}
+
+		writer.writeTransaction(row, account, counter)
+
+		return writer.err
+	})
+	if err != nil {
+		return errors.Wrap(err, "[journal.WriteJournal] failed to write transactions")
+	}
+
+	return errors.Wrap(writer.Flush(), "[journal.WriteJournal]")
+
+}
+
+// journalWriter writes transactions in the syntax of a journal format. The first error is kept in
+// err and every write after it is skipped
+type journalWriter struct {
+	*bufio.Writer
+	format ledger.JournalFormat
+	err    error
+}
+
+func (w *journalWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+
+	_, w.err = fmt.Fprintf(w.Writer, format, args...)
+}
+
+func (w *journalWriter) writeTransaction(row *ledger.TransactionExportRow, account, counter string) {
+
+	flag := "*"
+	if row.Pending {
+		flag = "!"
+	}
+
+	payee := row.Name
+	if row.MerchantName.Valid && row.MerchantName.String != "" {
+		payee = row.MerchantName.String
+	}
+
+	currency := row.ISOCurrencyCode.String
+	if !row.ISOCurrencyCode.Valid {
+		currency = row.UnofficialCurrencyCode.String
+	}
+
+	switch w.format {
+	case ledger.JournalFormatBeancount:
+		w.printf("%s %s %s %s\n", row.Date.Format("2006-01-02"), flag, quote(payee), quote(row.Name))
+		w.printf("    transaction_id: %s\n", quote(row.TransactionID))
+	default:
+		layout := "2006-01-02"
+		if w.format == ledger.JournalFormatLedger {
+			layout = "2006/01/02"
+		}
+
+		w.printf("%s %s %s\n", row.Date.Format(layout), flag, singleLine(payee))
+		if row.Name != payee {
+			w.printf("    ; %s\n", singleLine(row.Name))
+		}
+		w.printf("    ; transaction_id: %s\n", row.TransactionID)
+	}
+
+	// Amounts are negative when money leaves the account, which is the sign the account posting
+	// takes in double entry. The counter posting balances it
+	w.printf("    %s  %s %s\n", account, strconv.FormatFloat(row.Amount, 'f', 2, 64), currency)
+	w.printf("    %s  %s %s\n\n", counter, strconv.FormatFloat(-row.Amount, 'f', 2, 64), currency)
+
+}
+
+// accountName joins root and components into an account name that is valid in the format of the writer
+func (w *journalWriter) accountName(root string, components ...string) string {
+
+	var parts = make([]string, 0, len(components)+1)
+	parts = append(parts, root)
+	for _, component := range components {
+		if w.format == ledger.JournalFormatBeancount {
+			component = beancountComponent(component)
+		} else {
+			component = ledgerComponent(component)
+		}
+
+		if component != "" {
+			parts = append(parts, component)
+		}
+	}
+
+	if len(parts) == 1 {
+		parts = append(parts, uncategorized)
+	}
+
+	return strings.Join(parts, ":")
+
+}
+
+// ledgerComponent removes the characters that would end an account name or split it into another
+// component in ledger and hledger. Two spaces in a row end an account name in a posting
+func ledgerComponent(component string) string {
+	component = strings.Map(func(r rune) rune {
+		if r == ':' || r == ';' || unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, component)
+
+	return strings.Join(strings.Fields(component), " ")
+}
+
+// beancountComponent reduces a component to the letters, digits and dashes beancount allows, with
+// runs of anything else replaced by a single dash. Components must start with a capital letter or digit
+func beancountComponent(component string) string {
+	var b strings.Builder
+	var dash bool
+	for _, r := range component {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+
+	component = b.String()
+	if component == "" {
+		return ""
+	}
+
+	return strings.ToUpper(component[:1]) + component[1:]
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// quote returns s as a beancount string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(singleLine(s)) + `"`
+}
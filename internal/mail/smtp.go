@@ -0,0 +1,203 @@
+// Package mail provides Mailer implementations for delivering email
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+)
+
+// SMTPMailer is a Mailer that delivers messages to an SMTP server. The connection is upgraded with
+// STARTTLS whenever the server offers it, and authentication is only attempted when a username is set,
+// so that local sinks such as MailHog can be used without either
+type SMTPMailer struct {
+	host     string
+	port     uint
+	username string
+	password string
+	from     mail.Address
+}
+
+// NewSMTPMailer returns an SMTPMailer that sends messages through host:port from the address in from,
+// which may include a display name such as Ledger <ledger@example.com>
+func NewSMTPMailer(host string, port uint, username, password, from string) (*SMTPMailer, error) {
+
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mail.NewSMTPMailer] invalid from address")
+	}
+
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     *address,
+	}, nil
+
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message *ledger.EmailMessage) error {
+
+	if len(message.To) == 0 {
+		return errors.New("[mail.Send] message does not have any recipients")
+	}
+
+	data, err := m.build(message)
+	if err != nil {
+		return errors.Wrap(err, "[mail.Send] failed to build message")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, strconv.FormatUint(uint64(m.port), 10)))
+	if err != nil {
+		return errors.Wrap(err, "[mail.Send] failed to connect to smtp server")
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return errors.Wrap(err, "[mail.Send] failed to greet smtp server")
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: m.host})
+		if err != nil {
+			return errors.Wrap(err, "[mail.Send] failed to start tls")
+		}
+	}
+
+	if m.username != "" {
+		err = client.Auth(smtp.PlainAuth("", m.username, m.password, m.host))
+		if err != nil {
+			return errors.Wrap(err, "[mail.Send] failed to authenticate")
+		}
+	}
+
+	err = client.Mail(m.from.Address)
+	if err != nil {
+		return errors.Wrap(err, "[mail.Send] failed to set sender")
+	}
+
+	for _, to := range message.To {
+		err = client.Rcpt(to)
+		if err != nil {
+			return errors.Wrapf(err, "[mail.Send] failed to add recipient %s", to)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "[mail.Send] failed to start message")
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return errors.Wrap(err, "[mail.Send] failed to write message")
+	}
+
+	err = w.Close()
+	if err != nil {
+		return errors.Wrap(err, "[mail.Send] failed to send message")
+	}
+
+	return errors.Wrap(client.Quit(), "[mail.Send]")
+
+}
+
+// build renders message as a MIME message. Messages with an HTML body are sent as multipart/alternative
+// with the plain text body first, so that clients which cannot display HTML fall back to it
+func (m *SMTPMailer) build(message *ledger.EmailMessage) ([]byte, error) {
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	header("From", m.from.String())
+	header("To", strings.Join(message.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", m.messageID())
+	header("MIME-Version", "1.0")
+
+	if message.HTMLBody == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+
+		return buf.Bytes(), writeQuotedPrintable(&buf, message.TextBody)
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", message.TextBody},
+		{"text/html; charset=utf-8", message.HTMLBody},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeQuotedPrintable(w, part.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := parts.Close()
+
+	return buf.Bytes(), err
+
+}
+
+func (m *SMTPMailer) messageID() string {
+
+	var id = make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	domain := m.from.Address[strings.LastIndexByte(m.from.Address, '@')+1:]
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain)
+
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+
+	qp := quotedprintable.NewWriter(w)
+	_, err := qp.Write([]byte(body))
+	if err != nil {
+		return err
+	}
+
+	return qp.Close()
+
+}
@@ -33,6 +33,7 @@ var accountColumns = []string{
 	"unofficial_currency_code",
 	"subtype",
 	"type",
+	"balance_floor",
 	// "recalculate_balance",
 	"created_at",
 	"updated_at",
@@ -151,6 +152,7 @@ func scanAccountFromRows(rows *sqlx.Rows) ([]*ledger.Account, error) {
 			unofficial_currency_code null.String
 			subtype                  null.String
 			accountType              null.String
+			balance_floor            null.Float64
 			created_at               time.Time
 			updated_at               time.Time
 		)
@@ -159,7 +161,7 @@ func scanAccountFromRows(rows *sqlx.Rows) ([]*ledger.Account, error) {
 			&item_id, &account_id, &mask, &name,
 			&official_name, &balance_available, &balance_current, &balance_limit,
 			&balance_last_updated, &iso_currency_code, &unofficial_currency_code, &subtype,
-			&accountType, &created_at, &updated_at,
+			&accountType, &balance_floor, &created_at, &updated_at,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "[scanAccountFromRows]")
@@ -173,6 +175,7 @@ func scanAccountFromRows(rows *sqlx.Rows) ([]*ledger.Account, error) {
 			OfficialName: official_name,
 			Subtype:      subtype,
 			Type:         accountType,
+			BalanceFloor: balance_floor,
 			CreatedAt:    created_at,
 			UpdatedAt:    updated_at,
 			Balance: &ledger.AccountBalance{
@@ -207,6 +210,7 @@ func scanAccountFromRow(row *sqlx.Row) (*ledger.Account, error) {
 		unofficial_currency_code null.String
 		subtype                  null.String
 		accountType              null.String
+		balance_floor            null.Float64
 		created_at               time.Time
 		updated_at               time.Time
 	)
@@ -215,7 +219,7 @@ func scanAccountFromRow(row *sqlx.Row) (*ledger.Account, error) {
 		&item_id, &account_id, &mask, &name,
 		&official_name, &balance_available, &balance_current, &balance_limit,
 		&balance_last_updated, &iso_currency_code, &unofficial_currency_code, &subtype,
-		&accountType, &created_at, &updated_at,
+		&accountType, &balance_floor, &created_at, &updated_at,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "[scanAccountFromRow]")
@@ -229,6 +233,7 @@ func scanAccountFromRow(row *sqlx.Row) (*ledger.Account, error) {
 		OfficialName: official_name,
 		Subtype:      subtype,
 		Type:         accountType,
+		BalanceFloor: balance_floor,
 		CreatedAt:    created_at,
 		UpdatedAt:    updated_at,
 		Balance: &ledger.AccountBalance{
@@ -299,6 +304,24 @@ func (r *accountRepository) DeleteAccount(ctx context.Context, itemID, accountID
 	return nil
 }
 
+// UpdateAccountBalanceFloor sets the balance the account is not expected to fall below. Plaid does not
+// know about the floor, so it is kept out of mapAccount to stop imports from clearing it
+func (r *accountRepository) UpdateAccountBalanceFloor(ctx context.Context, itemID, accountID string, floor null.Float64) error {
+
+	query, args, err := sq.Update(accountTable).SetMap(map[string]interface{}{
+		"balance_floor": floor,
+		"updated_at":    sq.Expr(`NOW()`),
+	}).Where(sq.Eq{"account_id": accountID, "item_id": itemID}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.UpdateAccountBalanceFloor]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.UpdateAccountBalanceFloor]")
+
+}
+
 func mapAccount(account *ledger.Account) map[string]interface{} {
 
 	mapColValues := map[string]interface{}{
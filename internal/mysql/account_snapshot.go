@@ -0,0 +1,136 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+const accountBalanceSnapshotsTable = "account_balance_snapshots"
+
+var accountBalanceSnapshotColumns = []string{
+	"item_id",
+	"account_id",
+	"snapshot_date",
+	"balance_available",
+	"balance_current",
+	"balance_limit",
+	"iso_currency_code",
+	"unofficial_currency_code",
+	"created_at",
+	"updated_at",
+}
+
+// snapshotUpsertSuffix replaces a snapshot that has already been taken on the same day
+const snapshotUpsertSuffix = `ON DUPLICATE KEY UPDATE
+	balance_available = VALUES(balance_available),
+	balance_current = VALUES(balance_current),
+	balance_limit = VALUES(balance_limit),
+	iso_currency_code = VALUES(iso_currency_code),
+	unofficial_currency_code = VALUES(unofficial_currency_code),
+	updated_at = VALUES(updated_at)`
+
+func (r *accountRepository) AccountBalanceSnapshots(ctx context.Context, itemID, accountID string, from, to time.Time) ([]*ledger.AccountBalanceSnapshot, error) {
+
+	query, args, err := sq.Select(accountBalanceSnapshotColumns...).
+		From(accountBalanceSnapshotsTable).
+		Where(sq.Eq{
+			"item_id":    itemID,
+			"account_id": accountID,
+		}).
+		Where(sq.GtOrEq{"snapshot_date": from.Format("2006-01-02")}).
+		Where(sq.LtOrEq{"snapshot_date": to.Format("2006-01-02")}).
+		OrderBy("snapshot_date asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.AccountBalanceSnapshots]")
+	}
+
+	var snapshots = make([]*ledger.AccountBalanceSnapshot, 0)
+	err = r.db.SelectContext(ctx, &snapshots, query, args...)
+
+	return snapshots, errors.Wrap(err, "[mysql.AccountBalanceSnapshots]")
+
+}
+
+// AccountBalanceSnapshotsByUserID returns every snapshot taken on or before to of the accounts
+// belonging to the user, oldest first
+func (r *accountRepository) AccountBalanceSnapshotsByUserID(ctx context.Context, userID uuid.UUID, to time.Time) ([]*ledger.AccountBalanceSnapshot, error) {
+
+	query, args, err := sq.Select(accountBalanceSnapshotColumns...).
+		From(accountBalanceSnapshotsTable).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		Where(sq.LtOrEq{"snapshot_date": to.Format("2006-01-02")}).
+		OrderBy("snapshot_date asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.AccountBalanceSnapshotsByUserID]")
+	}
+
+	var snapshots = make([]*ledger.AccountBalanceSnapshot, 0)
+	err = r.db.SelectContext(ctx, &snapshots, query, args...)
+
+	return snapshots, errors.Wrap(err, "[mysql.AccountBalanceSnapshotsByUserID]")
+
+}
+
+// SaveAccountBalanceSnapshot writes the snapshot, replacing the snapshot already taken of the account
+// on the same day
+func (r *accountRepository) SaveAccountBalanceSnapshot(ctx context.Context, snapshot *ledger.AccountBalanceSnapshot) error {
+
+	query, args, err := sq.Insert(accountBalanceSnapshotsTable).SetMap(map[string]interface{}{
+		"item_id":                  snapshot.ItemID,
+		"account_id":               snapshot.AccountID,
+		"snapshot_date":            snapshot.Date.Format("2006-01-02"),
+		"balance_available":        snapshot.Available,
+		"balance_current":          snapshot.Current,
+		"balance_limit":            snapshot.Limit,
+		"iso_currency_code":        snapshot.ISOCurrencyCode,
+		"unofficial_currency_code": snapshot.UnofficialCurrencyCode,
+		"created_at":               sq.Expr(`NOW()`),
+		"updated_at":               sq.Expr(`NOW()`),
+	}).Suffix(snapshotUpsertSuffix).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.SaveAccountBalanceSnapshot]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.SaveAccountBalanceSnapshot]")
+
+}
+
+// SnapshotAccountBalances copies the current balance of every account into a snapshot for date
+func (r *accountRepository) SnapshotAccountBalances(ctx context.Context, date time.Time) error {
+
+	balances := sq.Select("item_id", "account_id").
+		Column(sq.Expr("?", date.Format("2006-01-02"))).
+		Columns(
+			"balance_available",
+			"balance_current",
+			"balance_limit",
+			"iso_currency_code",
+			"unofficial_currency_code",
+			"NOW()",
+			"NOW()",
+		).
+		From(accountTable)
+
+	query, args, err := sq.Insert(accountBalanceSnapshotsTable).
+		Columns(accountBalanceSnapshotColumns...).
+		Select(balances).
+		Suffix(snapshotUpsertSuffix).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.SnapshotAccountBalances]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.SnapshotAccountBalances]")
+
+}
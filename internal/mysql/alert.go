@@ -0,0 +1,263 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type alertRepository struct {
+	db *sqlx.DB
+}
+
+const (
+	alertRulesTable = "alert_rules"
+	alertsTable     = "alerts"
+)
+
+var alertRuleColumns = []string{
+	"rule_id",
+	"user_id",
+	"name",
+	"type",
+	"threshold",
+	"item_id",
+	"account_id",
+	"budget_id",
+	"cooldown_minutes",
+	"enabled",
+	"last_fired_at",
+	"created_at",
+	"updated_at",
+}
+
+var alertColumns = []string{
+	"alert_id",
+	"rule_id",
+	"user_id",
+	"type",
+	"alert_key",
+	"message",
+	"item_id",
+	"account_id",
+	"transaction_id",
+	"budget_id",
+	"amount",
+	"created_at",
+}
+
+func NewAlertRepository(db *sqlx.DB) ledger.AlertRepository {
+	return &alertRepository{db: db}
+}
+
+func (r *alertRepository) AlertRule(ctx context.Context, userID uuid.UUID, ruleID string) (*ledger.AlertRule, error) {
+
+	query, args, err := sq.Select(alertRuleColumns...).From(alertRulesTable).Where(sq.Eq{
+		"user_id": userID,
+		"rule_id": ruleID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.AlertRule]")
+	}
+
+	var rule = new(ledger.AlertRule)
+	err = r.db.GetContext(ctx, rule, query, args...)
+
+	return rule, errors.Wrap(err, "[mysql.AlertRule]")
+
+}
+
+func (r *alertRepository) AlertRules(ctx context.Context, userID uuid.UUID) ([]*ledger.AlertRule, error) {
+
+	query, args, err := sq.Select(alertRuleColumns...).
+		From(alertRulesTable).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("name asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.AlertRules]")
+	}
+
+	var rules = make([]*ledger.AlertRule, 0)
+	err = r.db.SelectContext(ctx, &rules, query, args...)
+
+	return rules, errors.Wrap(err, "[mysql.AlertRules]")
+
+}
+
+func (r *alertRepository) CreateAlertRule(ctx context.Context, rule *ledger.AlertRule) (*ledger.AlertRule, error) {
+
+	query, args, err := sq.Insert(alertRulesTable).SetMap(map[string]interface{}{
+		"rule_id":          rule.RuleID,
+		"user_id":          rule.UserID,
+		"name":             rule.Name,
+		"type":             rule.Type,
+		"threshold":        rule.Threshold,
+		"item_id":          rule.ItemID,
+		"account_id":       rule.AccountID,
+		"budget_id":        rule.BudgetID,
+		"cooldown_minutes": rule.CooldownMinutes,
+		"enabled":          rule.Enabled,
+		"created_at":       sq.Expr(`NOW()`),
+		"updated_at":       sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateAlertRule]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateAlertRule]")
+	}
+
+	return r.AlertRule(ctx, rule.UserID, rule.RuleID)
+
+}
+
+func (r *alertRepository) UpdateAlertRule(ctx context.Context, ruleID string, rule *ledger.AlertRule) (*ledger.AlertRule, error) {
+
+	query, args, err := sq.Update(alertRulesTable).SetMap(map[string]interface{}{
+		"name":             rule.Name,
+		"type":             rule.Type,
+		"threshold":        rule.Threshold,
+		"item_id":          rule.ItemID,
+		"account_id":       rule.AccountID,
+		"budget_id":        rule.BudgetID,
+		"cooldown_minutes": rule.CooldownMinutes,
+		"enabled":          rule.Enabled,
+		"updated_at":       sq.Expr(`NOW()`),
+	}).Where(sq.Eq{
+		"user_id": rule.UserID,
+		"rule_id": ruleID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateAlertRule]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateAlertRule]")
+	}
+
+	return r.AlertRule(ctx, rule.UserID, ruleID)
+
+}
+
+func (r *alertRepository) UpdateAlertRuleFiredAt(ctx context.Context, ruleID string, firedAt time.Time) error {
+
+	query, args, err := sq.Update(alertRulesTable).
+		Set("last_fired_at", firedAt).
+		Where(sq.Eq{"rule_id": ruleID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.UpdateAlertRuleFiredAt]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.UpdateAlertRuleFiredAt]")
+
+}
+
+func (r *alertRepository) DeleteAlertRule(ctx context.Context, userID uuid.UUID, ruleID string) error {
+
+	query, args, err := sq.Delete(alertRulesTable).Where(sq.Eq{
+		"user_id": userID,
+		"rule_id": ruleID,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.DeleteAlertRule]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.DeleteAlertRule]")
+
+}
+
+func (r *alertRepository) Alert(ctx context.Context, userID uuid.UUID, alertID string) (*ledger.Alert, error) {
+
+	query, args, err := sq.Select(alertColumns...).From(alertsTable).Where(sq.Eq{
+		"user_id":  userID,
+		"alert_id": alertID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.Alert]")
+	}
+
+	var alert = new(ledger.Alert)
+	err = r.db.GetContext(ctx, alert, query, args...)
+
+	return alert, errors.Wrap(err, "[mysql.Alert]")
+
+}
+
+func (r *alertRepository) AlertByKey(ctx context.Context, ruleID, key string) (*ledger.Alert, error) {
+
+	query, args, err := sq.Select(alertColumns...).From(alertsTable).Where(sq.Eq{
+		"rule_id":   ruleID,
+		"alert_key": key,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.AlertByKey]")
+	}
+
+	var alert = new(ledger.Alert)
+	err = r.db.GetContext(ctx, alert, query, args...)
+
+	return alert, errors.Wrap(err, "[mysql.AlertByKey]")
+
+}
+
+func (r *alertRepository) Alerts(ctx context.Context, userID uuid.UUID, limit uint64) ([]*ledger.Alert, error) {
+
+	query, args, err := sq.Select(alertColumns...).
+		From(alertsTable).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at desc", "alert_id asc").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.Alerts]")
+	}
+
+	var alerts = make([]*ledger.Alert, 0)
+	err = r.db.SelectContext(ctx, &alerts, query, args...)
+
+	return alerts, errors.Wrap(err, "[mysql.Alerts]")
+
+}
+
+func (r *alertRepository) CreateAlert(ctx context.Context, alert *ledger.Alert) (*ledger.Alert, error) {
+
+	query, args, err := sq.Insert(alertsTable).SetMap(map[string]interface{}{
+		"alert_id":       alert.AlertID,
+		"rule_id":        alert.RuleID,
+		"user_id":        alert.UserID,
+		"type":           alert.Type,
+		"alert_key":      alert.Key,
+		"message":        alert.Message,
+		"item_id":        alert.ItemID,
+		"account_id":     alert.AccountID,
+		"transaction_id": alert.TransactionID,
+		"budget_id":      alert.BudgetID,
+		"amount":         alert.Amount,
+		"created_at":     sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateAlert]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateAlert]")
+	}
+
+	return r.Alert(ctx, alert.UserID, alert.AlertID)
+
+}
@@ -0,0 +1,164 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+type anomalyRepository struct {
+	db *sqlx.DB
+}
+
+const transactionAnomaliesTable = "transaction_anomalies"
+
+var transactionAnomalyColumns = []string{
+	"item_id",
+	"transaction_id",
+	"score",
+	"reasons",
+	"acknowledged_at",
+	"created_at",
+	"updated_at",
+}
+
+func NewAnomalyRepository(db *sqlx.DB) ledger.AnomalyRepository {
+	return &anomalyRepository{db: db}
+}
+
+func (r *anomalyRepository) TransactionAnomaly(ctx context.Context, itemID, transactionID string) (*ledger.TransactionAnomaly, error) {
+
+	query, args, err := sq.Select(transactionAnomalyColumns...).From(transactionAnomaliesTable).Where(sq.Eq{
+		"item_id":        itemID,
+		"transaction_id": transactionID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.TransactionAnomaly]")
+	}
+
+	var anomaly = new(ledger.TransactionAnomaly)
+	err = r.db.GetContext(ctx, anomaly, query, args...)
+
+	return anomaly, errors.Wrap(err, "[mysql.TransactionAnomaly]")
+
+}
+
+// TransactionAnomaliesByUserID returns the anomalies across all of the users items, highest score
+// first. acknowledged limits them to those that have or have not been acknowledged when it is set
+func (r *anomalyRepository) TransactionAnomaliesByUserID(ctx context.Context, userID uuid.UUID, acknowledged null.Bool) ([]*ledger.TransactionAnomaly, error) {
+
+	stmt := sq.Select(transactionAnomalyColumns...).
+		From(transactionAnomaliesTable).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		OrderBy("score desc", "created_at desc")
+	if acknowledged.Valid && acknowledged.Bool {
+		stmt = stmt.Where(sq.NotEq{"acknowledged_at": nil})
+	} else if acknowledged.Valid {
+		stmt = stmt.Where(sq.Eq{"acknowledged_at": nil})
+	}
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.TransactionAnomaliesByUserID]")
+	}
+
+	var anomalies = make([]*ledger.TransactionAnomaly, 0)
+	err = r.db.SelectContext(ctx, &anomalies, query, args...)
+
+	return anomalies, errors.Wrap(err, "[mysql.TransactionAnomaliesByUserID]")
+
+}
+
+// SaveTransactionAnomaly creates the anomaly, or replaces the score and reasons of the anomaly that
+// already exists for the transaction without changing whether it has been acknowledged
+func (r *anomalyRepository) SaveTransactionAnomaly(ctx context.Context, anomaly *ledger.TransactionAnomaly) error {
+
+	query, args, err := sq.Insert(transactionAnomaliesTable).SetMap(map[string]interface{}{
+		"item_id":         anomaly.ItemID,
+		"transaction_id":  anomaly.TransactionID,
+		"score":           anomaly.Score,
+		"reasons":         anomaly.Reasons,
+		"acknowledged_at": anomaly.AcknowledgedAt,
+		"created_at":      sq.Expr(`NOW()`),
+		"updated_at":      sq.Expr(`NOW()`),
+	}).Suffix(`ON DUPLICATE KEY UPDATE
+		score = VALUES(score),
+		reasons = VALUES(reasons),
+		updated_at = VALUES(updated_at)`).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.SaveTransactionAnomaly]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.SaveTransactionAnomaly]")
+
+}
+
+func (r *anomalyRepository) AcknowledgeTransactionAnomaly(ctx context.Context, itemID, transactionID string) error {
+
+	query, args, err := sq.Update(transactionAnomaliesTable).SetMap(map[string]interface{}{
+		"acknowledged_at": sq.Expr(`NOW()`),
+		"updated_at":      sq.Expr(`NOW()`),
+	}).Where(sq.Eq{
+		"item_id":        itemID,
+		"transaction_id": transactionID,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.AcknowledgeTransactionAnomaly]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.AcknowledgeTransactionAnomaly]")
+
+}
+
+// AnomalyBaseline summarises the posted outflows of the users that own the item of transaction,
+// dated from from up to and including the date of the transaction, excluding the transaction itself
+func (r *anomalyRepository) AnomalyBaseline(ctx context.Context, transaction *ledger.Transaction, from time.Time) (*ledger.AnomalyBaseline, error) {
+
+	var country, region null.String
+	if transaction.Location != nil {
+		country, region = transaction.Location.Country, transaction.Location.Region
+	}
+
+	query, args, err := sq.Select().
+		Column("COUNT(*) AS history_count").
+		Column(sq.Expr("COALESCE(SUM(t.merchant_id = ?), 0) AS merchant_count", transaction.MerchantID)).
+		Column(sq.Expr("AVG(CASE WHEN t.merchant_id = ? THEN ABS(t.amount) END) AS merchant_mean", transaction.MerchantID)).
+		Column(sq.Expr("STDDEV_SAMP(CASE WHEN t.merchant_id = ? THEN ABS(t.amount) END) AS merchant_stddev", transaction.MerchantID)).
+		Column(sq.Expr("COALESCE(SUM(t.category_id = ?), 0) AS category_count", transaction.CategoryID)).
+		Column(sq.Expr("AVG(CASE WHEN t.category_id = ? THEN ABS(t.amount) END) AS category_mean", transaction.CategoryID)).
+		Column(sq.Expr("STDDEV_SAMP(CASE WHEN t.category_id = ? THEN ABS(t.amount) END) AS category_stddev", transaction.CategoryID)).
+		Column("COALESCE(SUM(l.country IS NOT NULL), 0) AS located_count").
+		Column(sq.Expr("COALESCE(SUM(l.country = ?), 0) AS country_count", country)).
+		Column(sq.Expr("COALESCE(SUM(l.country = ? AND l.region = ?), 0) AS region_count", country, region)).
+		From(transactionsTableName + " t").
+		LeftJoin(transactionLocationsTableName + " l ON l.item_id = t.item_id AND l.transaction_id = t.transaction_id").
+		Where(sq.Expr("t.item_id IN (SELECT item_id FROM user_items WHERE user_id IN (SELECT user_id FROM user_items WHERE item_id = ?))", transaction.ItemID)).
+		Where(sq.Eq{
+			"t.pending":    false,
+			"t.deleted_at": nil,
+		}).
+		Where(sq.Lt{"t.amount": 0}).
+		Where(sq.GtOrEq{"t.date": from.Format("2006-01-02")}).
+		Where(sq.LtOrEq{"t.date": transaction.Date.Format("2006-01-02")}).
+		Where(sq.NotEq{"t.transaction_id": transaction.TransactionID}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.AnomalyBaseline]")
+	}
+
+	var baseline = new(ledger.AnomalyBaseline)
+	err = r.db.GetContext(ctx, baseline, query, args...)
+
+	return baseline, errors.Wrap(err, "[mysql.AnomalyBaseline]")
+
+}
@@ -0,0 +1,323 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type backupRepository struct {
+	db *sqlx.DB
+}
+
+// backupTable describes how the rows of a table that belong to a user are found. Every ? in scope is
+// bound to the id of the user. Key is only set for tables without a primary key, or whose primary key
+// is a surrogate, restores look rows up by it instead of relying on ON DUPLICATE KEY UPDATE. Secrets are the columns that are emptied
+// unless access tokens are included, restoring an empty secret keeps the value already stored.
+// Surrogate is an AUTO_INCREMENT column numbered across every user, it is left out on restore so
+// that a restored row can never take the place of the row of another user. InsertOnly tables only
+// have the rows that are missing inserted, existing rows are left as they are
+type backupTable struct {
+	name       string
+	scope      string
+	key        []string
+	secrets    []string
+	surrogate  string
+	insertOnly bool
+}
+
+const (
+	userItemsScope = "item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)"
+	merchantsScope = `id IN (SELECT merchant_id FROM transactions WHERE ` + userItemsScope + `)
+		OR id IN (SELECT merchant_id FROM recurring_series WHERE user_id = ?)
+		OR id IN (SELECT scope_id FROM budgets WHERE scope = 'merchant' AND user_id = ?)`
+)
+
+// backupTables are ordered so that every table comes after the tables it references. Merchants,
+// categories and institutions are shared between users, only the ones the user refers to are included
+// and restoring them never changes the rows other users see
+var backupTables = []backupTable{
+	{name: "users", scope: "id = ?"},
+	{name: "plaid_institutions", scope: "id IN (SELECT institution_id FROM user_items WHERE user_id = ?)", insertOnly: true},
+	{name: "plaid_categories", scope: `id IN (SELECT category_id FROM transactions WHERE ` + userItemsScope + `)
+		OR id IN (SELECT scope_id FROM budgets WHERE scope = 'category' AND user_id = ?)`, insertOnly: true},
+	{name: "merchants", scope: merchantsScope, insertOnly: true},
+	{name: "merchant_aliases", scope: "merchant_id IN (SELECT id FROM merchants WHERE " + merchantsScope + ")", key: []string{"alias_id"}, insertOnly: true},
+	{name: "user_items", scope: "user_id = ?", secrets: []string{"access_token"}},
+	{name: "accounts", scope: userItemsScope},
+	{name: "transactions", scope: userItemsScope},
+	{name: "transaction_changelogs", scope: userItemsScope, key: []string{"item_id", "transaction_id", "source", "actor", "created_at"}, surrogate: "changelog_id", insertOnly: true},
+	{name: "transaction_relations", scope: userItemsScope},
+	{name: "transaction_locations", scope: userItemsScope},
+	{name: "transaction_anomalies", scope: userItemsScope},
+	{name: "transaction_attachments", scope: userItemsScope},
+	{name: "receipts", scope: "user_id = ?"},
+	{name: "account_balance_snapshots", scope: userItemsScope},
+	{name: "account_forecasts", scope: userItemsScope},
+	{name: "statement_profiles", scope: userItemsScope},
+	{name: "budgets", scope: "user_id = ?"},
+	{name: "recurring_series", scope: "user_id = ?"},
+	{name: "journal_account_mappings", scope: "user_id = ?"},
+	{name: "notification_preferences", scope: "user_id = ?"},
+	{name: "notifications", scope: "user_id = ?"},
+	{name: "alert_rules", scope: "user_id = ?"},
+	{name: "alerts", scope: "user_id = ?"},
+	{name: "webhook_endpoints", scope: "user_id = ?", secrets: []string{"secret"}},
+	{name: "webhook_deliveries", scope: "user_id = ?"},
+}
+
+func NewBackupRepository(db *sqlx.DB) ledger.BackupRepository {
+	return &backupRepository{db: db}
+}
+
+func (r *backupRepository) BackupTables() []string {
+	var tables = make([]string, 0, len(backupTables))
+	for _, table := range backupTables {
+		tables = append(tables, table.name)
+	}
+
+	return tables
+}
+
+func lookupBackupTable(name string) (backupTable, error) {
+	for _, table := range backupTables {
+		if table.name == name {
+			return table, nil
+		}
+	}
+
+	return backupTable{}, errors.Errorf("%s is not a table that is backed up", name)
+}
+
+// BackupRows calls fn with every row of table that belongs to the user. Values are converted to the
+// strings, numbers and nulls they can be inserted back from, dates and times are written the way
+// MySQL writes them
+func (r *backupRepository) BackupRows(ctx context.Context, name string, userID uuid.UUID, includeAccessTokens bool, fn func(row ledger.BackupRow) error) error {
+
+	table, err := lookupBackupTable(name)
+	if err != nil {
+		return errors.Wrap(err, "[mysql.BackupRows]")
+	}
+
+	var args = make([]interface{}, strings.Count(table.scope, "?"))
+	for i := range args {
+		args[i] = userID
+	}
+
+	query, args, err := sq.Select("*").From(table.name).Where(table.scope, args...).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.BackupRows]")
+	}
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "[mysql.BackupRows]")
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.BackupRows]")
+	}
+
+	for rows.Next() {
+		var row = make(map[string]interface{}, len(columnTypes))
+		err = rows.MapScan(row)
+		if err != nil {
+			return errors.Wrap(err, "[mysql.BackupRows]")
+		}
+
+		for _, columnType := range columnTypes {
+			column := columnType.Name()
+			switch value := row[column].(type) {
+			case []byte:
+				row[column] = string(value)
+			case time.Time:
+				layout := "2006-01-02 15:04:05"
+				if columnType.DatabaseTypeName() == "DATE" {
+					layout = "2006-01-02"
+				}
+				row[column] = value.UTC().Format(layout)
+			}
+		}
+
+		if !includeAccessTokens {
+			for _, column := range table.secrets {
+				row[column] = ""
+			}
+		}
+
+		err = fn(ledger.BackupRow(row))
+		if err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(rows.Err(), "[mysql.BackupRows]")
+
+}
+
+// RestoreRows inserts rows into table, replacing the rows that already exist unless the table is
+// insert only, so restoring the same rows again changes nothing. Columns are checked against the
+// table, so a backup taken before a migration added columns can still be restored. The rows are
+// restored in a single transaction
+func (r *backupRepository) RestoreRows(ctx context.Context, name string, rows []ledger.BackupRow) error {
+
+	table, err := lookupBackupTable(name)
+	if err != nil {
+		return errors.Wrap(err, "[mysql.RestoreRows]")
+	}
+
+	columns, err := r.tableColumns(ctx, table.name)
+	if err != nil {
+		return errors.Wrap(err, "[mysql.RestoreRows]")
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "[mysql.RestoreRows]")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, row := range rows {
+		for column := range row {
+			if !columns[column] {
+				return errors.Errorf("[mysql.RestoreRows] table %s does not have a column named %s", table.name, column)
+			}
+		}
+
+		if table.surrogate != "" {
+			delete(row, table.surrogate)
+		}
+
+		if len(table.key) > 0 {
+			err = r.restoreKeyedRow(ctx, tx, table, row)
+		} else {
+			err = r.restoreRow(ctx, tx, table, row)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "[mysql.RestoreRows] failed to restore row of %s", table.name)
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "[mysql.RestoreRows]")
+
+}
+
+func (r *backupRepository) restoreRow(ctx context.Context, tx *sqlx.Tx, table backupTable, row ledger.BackupRow) error {
+
+	var columns = make([]string, 0, len(row))
+	var values = make([]interface{}, 0, len(row))
+	var updates = make([]string, 0, len(row))
+	for column, value := range row {
+		quoted := "`" + column + "`"
+		columns = append(columns, quoted)
+		values = append(values, value)
+
+		update := quoted + " = VALUES(" + quoted + ")"
+		if isSecret(table, column) {
+			update = quoted + " = IF(VALUES(" + quoted + ") = '', " + quoted + ", VALUES(" + quoted + "))"
+		}
+		updates = append(updates, update)
+	}
+
+	stmt := sq.Insert(table.name).Columns(columns...).Values(values...)
+	if table.insertOnly {
+		stmt = stmt.Options("IGNORE")
+	} else {
+		stmt = stmt.Suffix("ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", "))
+	}
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+
+	return err
+
+}
+
+// restoreKeyedRow updates the row with the same key as row, or inserts row when there is none. Rows
+// of insert only tables are never updated
+func (r *backupRepository) restoreKeyedRow(ctx context.Context, tx *sqlx.Tx, table backupTable, row ledger.BackupRow) error {
+
+	var key = make(sq.Eq, len(table.key))
+	for _, column := range table.key {
+		key["`"+column+"`"] = row[column]
+	}
+
+	query, args, err := sq.Select("1").From(table.name).Where(key).Limit(1).ToSql()
+	if err != nil {
+		return err
+	}
+
+	var exists int
+	err = tx.GetContext(ctx, &exists, query, args...)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err == nil && table.insertOnly {
+		return nil
+	}
+
+	var values = make(map[string]interface{}, len(row))
+	for column, value := range row {
+		values["`"+column+"`"] = value
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		query, args, err = sq.Insert(table.name).SetMap(values).ToSql()
+	} else {
+		query, args, err = sq.Update(table.name).SetMap(values).Where(key).ToSql()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+
+	return err
+
+}
+
+func (r *backupRepository) tableColumns(ctx context.Context, table string) (map[string]bool, error) {
+
+	rows, err := r.db.QueryxContext(ctx, "SELECT * FROM "+table+" LIMIT 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var columns = make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[name] = true
+	}
+
+	return columns, nil
+
+}
+
+func isSecret(table backupTable, column string) bool {
+	for _, secret := range table.secrets {
+		if secret == column {
+			return true
+		}
+	}
+
+	return false
+}
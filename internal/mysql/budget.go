@@ -0,0 +1,142 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type budgetRepository struct {
+	db *sqlx.DB
+}
+
+const budgetsTable = "budgets"
+
+var budgetColumns = []string{
+	"budget_id",
+	"user_id",
+	"name",
+	"scope",
+	"scope_id",
+	"period",
+	"amount",
+	"rollover",
+	"start_date",
+	"created_at",
+	"updated_at",
+}
+
+func NewBudgetRepository(db *sqlx.DB) ledger.BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+func (r *budgetRepository) Budget(ctx context.Context, userID uuid.UUID, budgetID string) (*ledger.Budget, error) {
+
+	query, args, err := sq.Select(budgetColumns...).From(budgetsTable).Where(sq.Eq{
+		"user_id":   userID,
+		"budget_id": budgetID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.Budget]")
+	}
+
+	var budget = new(ledger.Budget)
+	err = r.db.GetContext(ctx, budget, query, args...)
+
+	return budget, errors.Wrap(err, "[mysql.Budget]")
+
+}
+
+func (r *budgetRepository) Budgets(ctx context.Context, userID uuid.UUID) ([]*ledger.Budget, error) {
+
+	query, args, err := sq.Select(budgetColumns...).
+		From(budgetsTable).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("name asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.Budgets]")
+	}
+
+	var budgets = make([]*ledger.Budget, 0)
+	err = r.db.SelectContext(ctx, &budgets, query, args...)
+
+	return budgets, errors.Wrap(err, "[mysql.Budgets]")
+
+}
+
+func (r *budgetRepository) CreateBudget(ctx context.Context, budget *ledger.Budget) (*ledger.Budget, error) {
+
+	query, args, err := sq.Insert(budgetsTable).SetMap(map[string]interface{}{
+		"budget_id":  budget.BudgetID,
+		"user_id":    budget.UserID,
+		"name":       budget.Name,
+		"scope":      budget.Scope,
+		"scope_id":   budget.ScopeID,
+		"period":     budget.Period,
+		"amount":     budget.Amount,
+		"rollover":   budget.Rollover,
+		"start_date": budget.StartDate.Format("2006-01-02"),
+		"created_at": sq.Expr(`NOW()`),
+		"updated_at": sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateBudget]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateBudget]")
+	}
+
+	return r.Budget(ctx, budget.UserID, budget.BudgetID)
+
+}
+
+func (r *budgetRepository) UpdateBudget(ctx context.Context, budgetID string, budget *ledger.Budget) (*ledger.Budget, error) {
+
+	query, args, err := sq.Update(budgetsTable).SetMap(map[string]interface{}{
+		"name":       budget.Name,
+		"scope":      budget.Scope,
+		"scope_id":   budget.ScopeID,
+		"period":     budget.Period,
+		"amount":     budget.Amount,
+		"rollover":   budget.Rollover,
+		"start_date": budget.StartDate.Format("2006-01-02"),
+		"updated_at": sq.Expr(`NOW()`),
+	}).Where(sq.Eq{
+		"user_id":   budget.UserID,
+		"budget_id": budgetID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateBudget]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateBudget]")
+	}
+
+	return r.Budget(ctx, budget.UserID, budgetID)
+
+}
+
+func (r *budgetRepository) DeleteBudget(ctx context.Context, userID uuid.UUID, budgetID string) error {
+
+	query, args, err := sq.Delete(budgetsTable).Where(sq.Eq{
+		"user_id":   userID,
+		"budget_id": budgetID,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.DeleteBudget]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.DeleteBudget]")
+
+}
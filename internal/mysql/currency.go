@@ -0,0 +1,96 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type exchangeRateRepository struct {
+	db *sqlx.DB
+}
+
+const exchangeRatesTable = "exchange_rates"
+
+var exchangeRateColumns = []string{
+	"date",
+	"from_currency",
+	"to_currency",
+	"rate",
+	"created_at",
+	"updated_at",
+}
+
+func NewExchangeRateRepository(db *sqlx.DB) ledger.ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) ExchangeRate(ctx context.Context, from, to string, date time.Time) (*ledger.ExchangeRate, error) {
+
+	query, args, err := sq.Select(exchangeRateColumns...).
+		From(exchangeRatesTable).
+		Where(sq.Eq{"from_currency": from, "to_currency": to}).
+		Where(sq.LtOrEq{"date": date.Format("2006-01-02")}).
+		OrderBy("date desc").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.ExchangeRate]")
+	}
+
+	var rate = new(ledger.ExchangeRate)
+	err = r.db.GetContext(ctx, rate, query, args...)
+	return rate, errors.Wrap(err, "[mysql.ExchangeRate]")
+
+}
+
+func (r *exchangeRateRepository) ExchangeRates(ctx context.Context, from, to string) ([]*ledger.ExchangeRate, error) {
+
+	query, args, err := sq.Select(exchangeRateColumns...).
+		From(exchangeRatesTable).
+		Where(sq.Eq{"from_currency": from, "to_currency": to}).
+		OrderBy("date desc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.ExchangeRates]")
+	}
+
+	var rates = make([]*ledger.ExchangeRate, 0)
+	err = r.db.SelectContext(ctx, &rates, query, args...)
+	return rates, errors.Wrap(err, "[mysql.ExchangeRates]")
+
+}
+
+// SaveExchangeRates inserts the provided rates, overwriting the rate of any currency pair
+// that already has a rate for the same date
+func (r *exchangeRateRepository) SaveExchangeRates(ctx context.Context, rates []*ledger.ExchangeRate) error {
+
+	if len(rates) == 0 {
+		return nil
+	}
+
+	query := sq.Insert(exchangeRatesTable).Columns(exchangeRateColumns...)
+	for _, rate := range rates {
+		query = query.Values(
+			rate.Date.Format("2006-01-02"),
+			rate.FromCurrency,
+			rate.ToCurrency,
+			rate.Rate,
+			sq.Expr(`NOW()`),
+			sq.Expr(`NOW()`),
+		)
+	}
+
+	stmt, args, err := query.Suffix("ON DUPLICATE KEY UPDATE rate = VALUES(rate), updated_at = VALUES(updated_at)").ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.SaveExchangeRates]")
+	}
+
+	_, err = r.db.ExecContext(ctx, stmt, args...)
+	return errors.Wrap(err, "[mysql.SaveExchangeRates]")
+
+}
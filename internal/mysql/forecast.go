@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type forecastRepository struct {
+	db *sqlx.DB
+}
+
+const accountForecastsTable = "account_forecasts"
+
+var accountForecastColumns = []string{
+	"item_id",
+	"account_id",
+	"currency_code",
+	"current_balance",
+	"floor",
+	"lowest_balance",
+	"lowest_date",
+	"shortfall_date",
+	"days",
+	"categories",
+	"created_at",
+	"updated_at",
+}
+
+func NewForecastRepository(db *sqlx.DB) ledger.ForecastRepository {
+	return &forecastRepository{db: db}
+}
+
+func (r *forecastRepository) AccountForecastsByUserID(ctx context.Context, userID uuid.UUID) ([]*ledger.AccountForecast, error) {
+
+	query, args, err := sq.Select(accountForecastColumns...).
+		From(accountForecastsTable).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		OrderBy("item_id asc", "account_id asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.AccountForecastsByUserID]")
+	}
+
+	var forecasts = make([]*ledger.AccountForecast, 0)
+	err = r.db.SelectContext(ctx, &forecasts, query, args...)
+
+	return forecasts, errors.Wrap(err, "[mysql.AccountForecastsByUserID]")
+
+}
+
+// SaveAccountForecast replaces the forecast stored for the account
+func (r *forecastRepository) SaveAccountForecast(ctx context.Context, forecast *ledger.AccountForecast) error {
+
+	var shortfallDate interface{}
+	if forecast.ShortfallDate.Valid {
+		shortfallDate = forecast.ShortfallDate.Time.Format("2006-01-02")
+	}
+
+	query, args, err := sq.Insert(accountForecastsTable).SetMap(map[string]interface{}{
+		"item_id":         forecast.ItemID,
+		"account_id":      forecast.AccountID,
+		"currency_code":   forecast.Currency,
+		"current_balance": forecast.Current,
+		"floor":           forecast.Floor,
+		"lowest_balance":  forecast.LowestBalance,
+		"lowest_date":     forecast.LowestDate.Format("2006-01-02"),
+		"shortfall_date":  shortfallDate,
+		"days":            forecast.Days,
+		"categories":      forecast.Categories,
+		"created_at":      sq.Expr(`NOW()`),
+		"updated_at":      sq.Expr(`NOW()`),
+	}).Suffix(`ON DUPLICATE KEY UPDATE
+		currency_code = VALUES(currency_code),
+		current_balance = VALUES(current_balance),
+		floor = VALUES(floor),
+		lowest_balance = VALUES(lowest_balance),
+		lowest_date = VALUES(lowest_date),
+		shortfall_date = VALUES(shortfall_date),
+		days = VALUES(days),
+		categories = VALUES(categories),
+		updated_at = VALUES(updated_at)`).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.SaveAccountForecast]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.SaveAccountForecast]")
+
+}
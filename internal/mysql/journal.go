@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type journalRepository struct {
+	db *sqlx.DB
+}
+
+const journalAccountMappingsTable = "journal_account_mappings"
+
+var journalAccountMappingColumns = []string{
+	"user_id",
+	"source_type",
+	"source_id",
+	"account_name",
+	"created_at",
+	"updated_at",
+}
+
+func NewJournalRepository(db *sqlx.DB) ledger.JournalRepository {
+	return &journalRepository{db: db}
+}
+
+func (r *journalRepository) JournalAccountMapping(ctx context.Context, userID uuid.UUID, sourceType ledger.JournalSourceType, sourceID string) (*ledger.JournalAccountMapping, error) {
+
+	query, args, err := sq.Select(journalAccountMappingColumns...).From(journalAccountMappingsTable).Where(sq.Eq{
+		"user_id":     userID,
+		"source_type": sourceType,
+		"source_id":   sourceID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.JournalAccountMapping]")
+	}
+
+	var mapping = new(ledger.JournalAccountMapping)
+	err = r.db.GetContext(ctx, mapping, query, args...)
+
+	return mapping, errors.Wrap(err, "[mysql.JournalAccountMapping]")
+
+}
+
+func (r *journalRepository) JournalAccountMappings(ctx context.Context, userID uuid.UUID) ([]*ledger.JournalAccountMapping, error) {
+
+	query, args, err := sq.Select(journalAccountMappingColumns...).
+		From(journalAccountMappingsTable).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("source_type asc", "account_name asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.JournalAccountMappings]")
+	}
+
+	var mappings = make([]*ledger.JournalAccountMapping, 0)
+	err = r.db.SelectContext(ctx, &mappings, query, args...)
+
+	return mappings, errors.Wrap(err, "[mysql.JournalAccountMappings]")
+
+}
+
+func (r *journalRepository) SaveJournalAccountMapping(ctx context.Context, mapping *ledger.JournalAccountMapping) (*ledger.JournalAccountMapping, error) {
+
+	query, args, err := sq.Insert(journalAccountMappingsTable).SetMap(map[string]interface{}{
+		"user_id":      mapping.UserID,
+		"source_type":  mapping.SourceType,
+		"source_id":    mapping.SourceID,
+		"account_name": mapping.AccountName,
+		"created_at":   sq.Expr(`NOW()`),
+		"updated_at":   sq.Expr(`NOW()`),
+	}).Suffix(`ON DUPLICATE KEY UPDATE
+		account_name = VALUES(account_name),
+		updated_at = VALUES(updated_at)`).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SaveJournalAccountMapping]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SaveJournalAccountMapping]")
+	}
+
+	return r.JournalAccountMapping(ctx, mapping.UserID, mapping.SourceType, mapping.SourceID)
+
+}
+
+func (r *journalRepository) DeleteJournalAccountMapping(ctx context.Context, userID uuid.UUID, sourceType ledger.JournalSourceType, sourceID string) error {
+
+	query, args, err := sq.Delete(journalAccountMappingsTable).Where(sq.Eq{
+		"user_id":     userID,
+		"source_type": sourceType,
+		"source_id":   sourceID,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.DeleteJournalAccountMapping]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.DeleteJournalAccountMapping]")
+
+}
@@ -0,0 +1,212 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type notificationRepository struct {
+	db *sqlx.DB
+}
+
+const (
+	notificationPreferencesTable = "notification_preferences"
+	notificationsTable           = "notifications"
+)
+
+var notificationPreferenceColumns = []string{
+	"user_id",
+	"new_transactions",
+	"digest",
+	"created_at",
+	"updated_at",
+}
+
+var notificationColumns = []string{
+	"notification_id",
+	"user_id",
+	"type",
+	"title",
+	"message",
+	"item_id",
+	"reference_id",
+	"read_at",
+	"created_at",
+}
+
+func NewNotificationRepository(db *sqlx.DB) ledger.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) NotificationPreferences(ctx context.Context, userID uuid.UUID) (*ledger.NotificationPreferences, error) {
+
+	query, args, err := sq.Select(notificationPreferenceColumns...).From(notificationPreferencesTable).Where(sq.Eq{"user_id": userID}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.NotificationPreferences]")
+	}
+
+	var preferences = new(ledger.NotificationPreferences)
+	err = r.db.GetContext(ctx, preferences, query, args...)
+
+	return preferences, errors.Wrap(err, "[mysql.NotificationPreferences]")
+
+}
+
+func (r *notificationRepository) NotificationPreferencesByDigest(ctx context.Context, digest ledger.DigestFrequency) ([]*ledger.NotificationPreferences, error) {
+
+	query, args, err := sq.Select(notificationPreferenceColumns...).From(notificationPreferencesTable).Where(sq.Eq{"digest": digest}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.NotificationPreferencesByDigest]")
+	}
+
+	var preferences = make([]*ledger.NotificationPreferences, 0)
+	err = r.db.SelectContext(ctx, &preferences, query, args...)
+
+	return preferences, errors.Wrap(err, "[mysql.NotificationPreferencesByDigest]")
+
+}
+
+func (r *notificationRepository) SaveNotificationPreferences(ctx context.Context, preferences *ledger.NotificationPreferences) (*ledger.NotificationPreferences, error) {
+
+	query, args, err := sq.Insert(notificationPreferencesTable).SetMap(map[string]interface{}{
+		"user_id":          preferences.UserID,
+		"new_transactions": preferences.NewTransactions,
+		"digest":           preferences.Digest,
+		"created_at":       sq.Expr(`NOW()`),
+		"updated_at":       sq.Expr(`NOW()`),
+	}).Suffix(`ON DUPLICATE KEY UPDATE
+		new_transactions = VALUES(new_transactions),
+		digest = VALUES(digest),
+		updated_at = VALUES(updated_at)`).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SaveNotificationPreferences]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SaveNotificationPreferences]")
+	}
+
+	return r.NotificationPreferences(ctx, preferences.UserID)
+
+}
+
+func (r *notificationRepository) Notification(ctx context.Context, userID uuid.UUID, notificationID string) (*ledger.Notification, error) {
+
+	query, args, err := sq.Select(notificationColumns...).From(notificationsTable).Where(sq.Eq{
+		"user_id":         userID,
+		"notification_id": notificationID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.Notification]")
+	}
+
+	var notification = new(ledger.Notification)
+	err = r.db.GetContext(ctx, notification, query, args...)
+
+	return notification, errors.Wrap(err, "[mysql.Notification]")
+
+}
+
+func (r *notificationRepository) Notifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit uint64) ([]*ledger.Notification, error) {
+
+	stmt := sq.Select(notificationColumns...).
+		From(notificationsTable).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at desc", "notification_id asc").
+		Limit(limit)
+	if unreadOnly {
+		stmt = stmt.Where(sq.Eq{"read_at": nil})
+	}
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.Notifications]")
+	}
+
+	var notifications = make([]*ledger.Notification, 0)
+	err = r.db.SelectContext(ctx, &notifications, query, args...)
+
+	return notifications, errors.Wrap(err, "[mysql.Notifications]")
+
+}
+
+func (r *notificationRepository) UnreadNotificationCount(ctx context.Context, userID uuid.UUID) (int, error) {
+
+	query, args, err := sq.Select("COUNT(*)").From(notificationsTable).Where(sq.Eq{
+		"user_id": userID,
+		"read_at": nil,
+	}).ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "[mysql.UnreadNotificationCount]")
+	}
+
+	var count int
+	err = r.db.GetContext(ctx, &count, query, args...)
+
+	return count, errors.Wrap(err, "[mysql.UnreadNotificationCount]")
+
+}
+
+func (r *notificationRepository) CreateNotification(ctx context.Context, notification *ledger.Notification) (*ledger.Notification, error) {
+
+	query, args, err := sq.Insert(notificationsTable).SetMap(map[string]interface{}{
+		"notification_id": notification.NotificationID,
+		"user_id":         notification.UserID,
+		"type":            notification.Type,
+		"title":           notification.Title,
+		"message":         notification.Message,
+		"item_id":         notification.ItemID,
+		"reference_id":    notification.ReferenceID,
+		"created_at":      sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateNotification]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateNotification]")
+	}
+
+	return r.Notification(ctx, notification.UserID, notification.NotificationID)
+
+}
+
+func (r *notificationRepository) MarkNotificationRead(ctx context.Context, userID uuid.UUID, notificationID string) error {
+
+	query, args, err := sq.Update(notificationsTable).Set("read_at", sq.Expr(`NOW()`)).Where(sq.Eq{
+		"user_id":         userID,
+		"notification_id": notificationID,
+		"read_at":         nil,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.MarkNotificationRead]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.MarkNotificationRead]")
+
+}
+
+func (r *notificationRepository) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) error {
+
+	query, args, err := sq.Update(notificationsTable).Set("read_at", sq.Expr(`NOW()`)).Where(sq.Eq{
+		"user_id": userID,
+		"read_at": nil,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.MarkAllNotificationsRead]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.MarkAllNotificationsRead]")
+
+}
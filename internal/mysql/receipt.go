@@ -0,0 +1,183 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type receiptRepository struct {
+	db *sqlx.DB
+}
+
+const receiptsTable = "receipts"
+
+var receiptColumns = []string{
+	"receipt_id",
+	"user_id",
+	"item_id",
+	"transaction_id",
+	"object_key",
+	"thumbnail_key",
+	"content_type",
+	"text",
+	"total",
+	"receipt_date",
+	"merchant_name",
+	"amount_mismatch",
+	"date_mismatch",
+	"created_at",
+	"updated_at",
+}
+
+func NewReceiptRepository(db *sqlx.DB) ledger.ReceiptRepository {
+	return &receiptRepository{db: db}
+}
+
+func (r *receiptRepository) Receipt(ctx context.Context, userID uuid.UUID, receiptID string) (*ledger.Receipt, error) {
+
+	query, args, err := sq.Select(receiptColumns...).From(receiptsTable).Where(sq.Eq{
+		"user_id":    userID,
+		"receipt_id": receiptID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.Receipt]")
+	}
+
+	var receipt = new(ledger.Receipt)
+	err = r.db.GetContext(ctx, receipt, query, args...)
+
+	return receipt, errors.Wrap(err, "[mysql.Receipt]")
+
+}
+
+func (r *receiptRepository) ReceiptByTransactionID(ctx context.Context, itemID, transactionID string) (*ledger.Receipt, error) {
+
+	query, args, err := sq.Select(receiptColumns...).From(receiptsTable).Where(sq.Eq{
+		"item_id":        itemID,
+		"transaction_id": transactionID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.ReceiptByTransactionID]")
+	}
+
+	var receipt = new(ledger.Receipt)
+	err = r.db.GetContext(ctx, receipt, query, args...)
+
+	return receipt, errors.Wrap(err, "[mysql.ReceiptByTransactionID]")
+
+}
+
+func (r *receiptRepository) UnmatchedReceipts(ctx context.Context, userID uuid.UUID) ([]*ledger.Receipt, error) {
+
+	query, args, err := sq.Select(receiptColumns...).From(receiptsTable).Where(sq.Eq{
+		"user_id":        userID,
+		"transaction_id": nil,
+	}).OrderBy("created_at desc").ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UnmatchedReceipts]")
+	}
+
+	var receipts = make([]*ledger.Receipt, 0)
+	err = r.db.SelectContext(ctx, &receipts, query, args...)
+
+	return receipts, errors.Wrap(err, "[mysql.UnmatchedReceipts]")
+
+}
+
+func (r *receiptRepository) SearchReceipts(ctx context.Context, userID uuid.UUID, term string) ([]*ledger.Receipt, error) {
+
+	query, args, err := sq.Select(receiptColumns...).
+		From(receiptsTable).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Expr("MATCH (text, merchant_name) AGAINST (? IN NATURAL LANGUAGE MODE)", term)).
+		Limit(50).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SearchReceipts]")
+	}
+
+	var receipts = make([]*ledger.Receipt, 0)
+	err = r.db.SelectContext(ctx, &receipts, query, args...)
+
+	return receipts, errors.Wrap(err, "[mysql.SearchReceipts]")
+
+}
+
+func (r *receiptRepository) CreateReceipt(ctx context.Context, receipt *ledger.Receipt) (*ledger.Receipt, error) {
+
+	query, args, err := sq.Insert(receiptsTable).SetMap(map[string]interface{}{
+		"receipt_id":      receipt.ReceiptID,
+		"user_id":         receipt.UserID,
+		"item_id":         receipt.ItemID,
+		"transaction_id":  receipt.TransactionID,
+		"object_key":      receipt.ObjectKey,
+		"thumbnail_key":   receipt.ThumbnailKey,
+		"content_type":    receipt.ContentType,
+		"text":            receipt.Text,
+		"total":           receipt.Total,
+		"receipt_date":    receipt.Date,
+		"merchant_name":   receipt.MerchantName,
+		"amount_mismatch": receipt.AmountMismatch,
+		"date_mismatch":   receipt.DateMismatch,
+		"created_at":      sq.Expr(`NOW()`),
+		"updated_at":      sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateReceipt]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateReceipt]")
+	}
+
+	return r.Receipt(ctx, receipt.UserID, receipt.ReceiptID)
+
+}
+
+func (r *receiptRepository) UpdateReceipt(ctx context.Context, receiptID string, receipt *ledger.Receipt) (*ledger.Receipt, error) {
+
+	query, args, err := sq.Update(receiptsTable).SetMap(map[string]interface{}{
+		"item_id":         receipt.ItemID,
+		"transaction_id":  receipt.TransactionID,
+		"object_key":      receipt.ObjectKey,
+		"thumbnail_key":   receipt.ThumbnailKey,
+		"content_type":    receipt.ContentType,
+		"text":            receipt.Text,
+		"total":           receipt.Total,
+		"receipt_date":    receipt.Date,
+		"merchant_name":   receipt.MerchantName,
+		"amount_mismatch": receipt.AmountMismatch,
+		"date_mismatch":   receipt.DateMismatch,
+		"updated_at":      sq.Expr(`NOW()`),
+	}).Where(sq.Eq{"receipt_id": receiptID}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateReceipt]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateReceipt]")
+	}
+
+	return r.Receipt(ctx, receipt.UserID, receiptID)
+
+}
+
+func (r *receiptRepository) DeleteReceipt(ctx context.Context, receiptID string) error {
+
+	query, args, err := sq.Delete(receiptsTable).Where(sq.Eq{"receipt_id": receiptID}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.DeleteReceipt]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.DeleteReceipt]")
+
+}
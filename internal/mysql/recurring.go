@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type recurringRepository struct {
+	db *sqlx.DB
+}
+
+const recurringSeriesTable = "recurring_series"
+
+var recurringSeriesColumns = []string{
+	"series_id",
+	"user_id",
+	"name",
+	"kind",
+	"source",
+	"item_id",
+	"account_id",
+	"merchant_id",
+	"currency_code",
+	"frequency",
+	"status",
+	"amount",
+	"previous_amount",
+	"price_changed_at",
+	"transaction_count",
+	"first_date",
+	"last_date",
+	"next_date",
+	"created_at",
+	"updated_at",
+}
+
+func NewRecurringRepository(db *sqlx.DB) ledger.RecurringRepository {
+	return &recurringRepository{db: db}
+}
+
+func (r *recurringRepository) RecurringSeries(ctx context.Context, userID uuid.UUID, seriesID string) (*ledger.RecurringSeries, error) {
+
+	query, args, err := sq.Select(recurringSeriesColumns...).From(recurringSeriesTable).Where(sq.Eq{
+		"user_id":   userID,
+		"series_id": seriesID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.RecurringSeries]")
+	}
+
+	var series = new(ledger.RecurringSeries)
+	err = r.db.GetContext(ctx, series, query, args...)
+
+	return series, errors.Wrap(err, "[mysql.RecurringSeries]")
+
+}
+
+func (r *recurringRepository) RecurringSeriesByUserID(ctx context.Context, userID uuid.UUID) ([]*ledger.RecurringSeries, error) {
+
+	query, args, err := sq.Select(recurringSeriesColumns...).
+		From(recurringSeriesTable).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("next_date asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.RecurringSeriesByUserID]")
+	}
+
+	var series = make([]*ledger.RecurringSeries, 0)
+	err = r.db.SelectContext(ctx, &series, query, args...)
+
+	return series, errors.Wrap(err, "[mysql.RecurringSeriesByUserID]")
+
+}
+
+// SaveRecurringSeries creates the series, or replaces the details of the series that already
+// exists with the same id. A merchant can have several series in the same currency, so callers
+// match a series to the one it updates themselves
+func (r *recurringRepository) SaveRecurringSeries(ctx context.Context, series *ledger.RecurringSeries) error {
+
+	var priceChangedAt, lastDate interface{}
+	if series.PriceChangedAt.Valid {
+		priceChangedAt = series.PriceChangedAt.Time.Format("2006-01-02")
+	}
+	if series.LastDate.Valid {
+		lastDate = series.LastDate.Time.Format("2006-01-02")
+	}
+
+	query, args, err := sq.Insert(recurringSeriesTable).SetMap(map[string]interface{}{
+		"series_id":         series.SeriesID,
+		"user_id":           series.UserID,
+		"name":              series.Name,
+		"kind":              series.Kind,
+		"source":            series.Source,
+		"item_id":           series.ItemID,
+		"account_id":        series.AccountID,
+		"merchant_id":       series.MerchantID,
+		"currency_code":     series.CurrencyCode,
+		"frequency":         series.Frequency,
+		"status":            series.Status,
+		"amount":            series.Amount,
+		"previous_amount":   series.PreviousAmount,
+		"price_changed_at":  priceChangedAt,
+		"transaction_count": series.TransactionCount,
+		"first_date":        series.FirstDate.Format("2006-01-02"),
+		"last_date":         lastDate,
+		"next_date":         series.NextDate.Format("2006-01-02"),
+		"created_at":        sq.Expr(`NOW()`),
+		"updated_at":        sq.Expr(`NOW()`),
+	}).Suffix(`ON DUPLICATE KEY UPDATE
+		name = VALUES(name),
+		kind = VALUES(kind),
+		item_id = VALUES(item_id),
+		account_id = VALUES(account_id),
+		merchant_id = VALUES(merchant_id),
+		currency_code = VALUES(currency_code),
+		frequency = VALUES(frequency),
+		status = VALUES(status),
+		amount = VALUES(amount),
+		previous_amount = VALUES(previous_amount),
+		price_changed_at = VALUES(price_changed_at),
+		transaction_count = VALUES(transaction_count),
+		first_date = VALUES(first_date),
+		last_date = VALUES(last_date),
+		next_date = VALUES(next_date),
+		updated_at = VALUES(updated_at)`).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.SaveRecurringSeries]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.SaveRecurringSeries]")
+
+}
+
+func (r *recurringRepository) DeleteRecurringSeries(ctx context.Context, userID uuid.UUID, seriesID string) error {
+
+	query, args, err := sq.Delete(recurringSeriesTable).Where(sq.Eq{
+		"user_id":   userID,
+		"series_id": seriesID,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.DeleteRecurringSeries]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.DeleteRecurringSeries]")
+
+}
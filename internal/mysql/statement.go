@@ -0,0 +1,96 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type statementRepository struct {
+	db *sqlx.DB
+}
+
+const statementProfilesTable = "statement_profiles"
+
+var statementProfileColumns = []string{
+	"item_id",
+	"account_id",
+	"delimiter",
+	"skip_rows",
+	"date_column",
+	"date_format",
+	"description_column",
+	"amount_column",
+	"debit_column",
+	"credit_column",
+	"id_column",
+	"invert_amounts",
+	"created_at",
+	"updated_at",
+}
+
+func NewStatementRepository(db *sqlx.DB) ledger.StatementRepository {
+	return &statementRepository{db: db}
+}
+
+func (r *statementRepository) StatementProfile(ctx context.Context, itemID, accountID string) (*ledger.StatementProfile, error) {
+
+	query, args, err := sq.Select(statementProfileColumns...).From(statementProfilesTable).Where(sq.Eq{
+		"item_id":    itemID,
+		"account_id": accountID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.StatementProfile]")
+	}
+
+	var profile = new(ledger.StatementProfile)
+	err = r.db.GetContext(ctx, profile, query, args...)
+
+	return profile, errors.Wrap(err, "[mysql.StatementProfile]")
+
+}
+
+func (r *statementRepository) SaveStatementProfile(ctx context.Context, profile *ledger.StatementProfile) (*ledger.StatementProfile, error) {
+
+	query, args, err := sq.Insert(statementProfilesTable).SetMap(map[string]interface{}{
+		"item_id":            profile.ItemID,
+		"account_id":         profile.AccountID,
+		"delimiter":          profile.Delimiter,
+		"skip_rows":          profile.SkipRows,
+		"date_column":        profile.DateColumn,
+		"date_format":        profile.DateFormat,
+		"description_column": profile.DescriptionColumn,
+		"amount_column":      profile.AmountColumn,
+		"debit_column":       profile.DebitColumn,
+		"credit_column":      profile.CreditColumn,
+		"id_column":          profile.IDColumn,
+		"invert_amounts":     profile.InvertAmounts,
+		"created_at":         sq.Expr(`NOW()`),
+		"updated_at":         sq.Expr(`NOW()`),
+	}).Suffix(`ON DUPLICATE KEY UPDATE
+		delimiter = VALUES(delimiter),
+		skip_rows = VALUES(skip_rows),
+		date_column = VALUES(date_column),
+		date_format = VALUES(date_format),
+		description_column = VALUES(description_column),
+		amount_column = VALUES(amount_column),
+		debit_column = VALUES(debit_column),
+		credit_column = VALUES(credit_column),
+		id_column = VALUES(id_column),
+		invert_amounts = VALUES(invert_amounts),
+		updated_at = VALUES(updated_at)`).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SaveStatementProfile]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SaveStatementProfile]")
+	}
+
+	return r.StatementProfile(ctx, profile.ItemID, profile.AccountID)
+
+}
@@ -3,10 +3,13 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/volatiletech/null"
@@ -35,12 +38,57 @@ var transactionColumns = []string{
 	"authorized_date",
 	"authorized_datetime",
 	"date",
+	"hidden_at",
+	"hidden_reason",
+	"deleted_at",
 	"created_at",
 	"updated_at",
 }
 
 const transactionsTableName = "transactions"
 
+var transactionChangelogColumns = []string{
+	"changelog_id",
+	"item_id",
+	"transaction_id",
+	"source",
+	"actor",
+	"changelog",
+	"created_at",
+}
+
+const transactionChangelogsTableName = "transaction_changelogs"
+
+var transactionRelationColumns = []string{
+	"relation_id",
+	"item_id",
+	"transaction_id",
+	"related_transaction_id",
+	"type",
+	"status",
+	"created_at",
+	"updated_at",
+}
+
+const transactionRelationsTableName = "transaction_relations"
+
+var transactionAttachmentColumns = []string{
+	"attachment_id",
+	"item_id",
+	"transaction_id",
+	"object_key",
+	"thumbnail_key",
+	"filename",
+	"content_type",
+	"size",
+	"checksum",
+	"uploaded_at",
+}
+
+const transactionAttachmentsTableName = "transaction_attachments"
+
+const transactionLocationsTableName = "transaction_locations"
+
 func NewTransactionRepository(db *sqlx.DB) ledger.TransactionRepository {
 	return &transactionRepository{db: db}
 }
@@ -151,8 +199,16 @@ func transactionsQueryBuilder(stmt sq.SelectBuilder, filters *ledger.Transaction
 		}
 	}
 
-	// Never fetch hidden transactions
-	stmt = stmt.Where(sq.Eq{"hidden_at": nil}).Where(sq.Eq{"deleted_at": nil})
+	// Hidden transactions are only fetched when explicitly requested. This keeps them
+	// out of counts and any other aggregate built on top of this query builder
+	if filters != nil && filters.Hidden.Valid && filters.Hidden.Bool {
+		stmt = stmt.Where(sq.NotEq{"hidden_at": nil})
+	} else {
+		stmt = stmt.Where(sq.Eq{"hidden_at": nil})
+	}
+
+	// Never fetch deleted transactions
+	stmt = stmt.Where(sq.Eq{"deleted_at": nil})
 
 	return stmt
 }
@@ -184,6 +240,9 @@ func (r *transactionRepository) CreateTransaction(ctx context.Context, transacti
 			transaction.AuthorizedDate,
 			transaction.AuthorizedDateTime,
 			transaction.Date,
+			transaction.HiddenAt,
+			transaction.HiddenReason,
+			transaction.DeletedAt,
 			sq.Expr(`NOW()`),
 			sq.Expr(`NOW()`),
 		).ToSql()
@@ -200,9 +259,8 @@ func (r *transactionRepository) CreateTransaction(ctx context.Context, transacti
 
 }
 
-func (r *transactionRepository) UpdateTransaction(ctx context.Context, transactionID string, transaction *ledger.Transaction) (*ledger.Transaction, error) {
-
-	query, args, err := sq.Update(transactionsTableName).SetMap(map[string]interface{}{
+func (r *transactionRepository) updateTransactionQuery(transactionID string, transaction *ledger.Transaction) (string, []interface{}, error) {
+	return sq.Update(transactionsTableName).SetMap(map[string]interface{}{
 		"pending_transaction_id":   transaction.PendingTransactionID,
 		"category_id":              transaction.CategoryID,
 		"name":                     transaction.Name,
@@ -218,10 +276,17 @@ func (r *transactionRepository) UpdateTransaction(ctx context.Context, transacti
 		"authorized_date":          transaction.AuthorizedDate,
 		"authorized_datetime":      transaction.AuthorizedDateTime,
 		"date":                     transaction.Date,
+		"hidden_at":                transaction.HiddenAt,
+		"hidden_reason":            transaction.HiddenReason,
 		"updated_at":               sq.Expr(`NOW()`),
 	}).Where(sq.Eq{
-		"transaction_id": transaction.TransactionID,
+		"transaction_id": transactionID,
 	}).ToSql()
+}
+
+func (r *transactionRepository) UpdateTransaction(ctx context.Context, transactionID string, transaction *ledger.Transaction) (*ledger.Transaction, error) {
+
+	query, args, err := r.updateTransactionQuery(transactionID, transaction)
 	if err != nil {
 		return nil, errors.Wrap(err, "[mysql.UpdateTransaction]")
 	}
@@ -234,6 +299,24 @@ func (r *transactionRepository) UpdateTransaction(ctx context.Context, transacti
 	return r.Transaction(ctx, transaction.ItemID, transaction.TransactionID)
 }
 
+func (r *transactionRepository) UpdateTransactionTx(ctx context.Context, tx ledger.Transactioner, transactionID string, t *ledger.Transaction) error {
+
+	txn, ok := tx.(*transaction)
+	if !ok {
+		return ErrInvalidTransaction
+	}
+
+	query, args, err := r.updateTransactionQuery(transactionID, t)
+	if err != nil {
+		return errors.Wrap(err, "[mysql.UpdateTransactionTx]")
+	}
+
+	_, err = txn.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.UpdateTransactionTx]")
+
+}
+
 func (r *transactionRepository) UpdateTransactionMerchantTx(ctx context.Context, tx ledger.Transactioner, byMerchantID, toMerchantID string) error {
 
 	txn, ok := tx.(*transaction)
@@ -255,3 +338,597 @@ func (r *transactionRepository) UpdateTransactionMerchantTx(ctx context.Context,
 	return errors.Wrap(err, "[mysql.UpdateTransactionMerchant]")
 
 }
+
+func (r *transactionRepository) TransactionChangelogs(ctx context.Context, itemID, transactionID string) ([]*ledger.TransactionChangelog, error) {
+
+	query, args, err := sq.Select(transactionChangelogColumns...).
+		From(transactionChangelogsTableName).
+		Where(sq.Eq{
+			"item_id":        itemID,
+			"transaction_id": transactionID,
+		}).
+		OrderBy("changelog_id desc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.TransactionChangelogs]")
+	}
+
+	var changelogs = make([]*ledger.TransactionChangelog, 0)
+	err = r.db.SelectContext(ctx, &changelogs, query, args...)
+
+	return changelogs, errors.Wrap(err, "[mysql.TransactionChangelogs]")
+
+}
+
+func (r *transactionRepository) CreateTransactionChangelogTx(ctx context.Context, tx ledger.Transactioner, changelog *ledger.TransactionChangelog) error {
+
+	txn, ok := tx.(*transaction)
+	if !ok {
+		return ErrInvalidTransaction
+	}
+
+	query, args, err := sq.Insert(transactionChangelogsTableName).SetMap(map[string]interface{}{
+		"item_id":        changelog.ItemID,
+		"transaction_id": changelog.TransactionID,
+		"source":         changelog.Source,
+		"actor":          changelog.Actor,
+		"changelog":      changelog.Changelog,
+		"created_at":     sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.CreateTransactionChangelogTx]")
+	}
+
+	_, err = txn.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.CreateTransactionChangelogTx]")
+
+}
+
+// RefundCandidates returns posted charges on the same item and merchant as the refund that were made
+// within window before it, most recent first. Charges are only returned while the part of them that
+// other refunds have not been matched to, leaving out rejected matches, is at least as large as the
+// refund, so an order can be refunded in several parts
+func (r *transactionRepository) RefundCandidates(ctx context.Context, refund *ledger.Transaction, window time.Duration) ([]*ledger.Transaction, error) {
+
+	query, args, err := sq.Select(transactionColumns...).
+		From(transactionsTableName).
+		Where(sq.Eq{
+			"item_id":     refund.ItemID,
+			"merchant_id": refund.MerchantID,
+			"pending":     false,
+			"hidden_at":   nil,
+			"deleted_at":  nil,
+		}).
+		Where(sq.NotEq{"transaction_id": refund.TransactionID}).
+		Where(sq.Lt{"amount": 0}).
+		Where(sq.Expr(
+			`ROUND(-amount - (
+				SELECT COALESCE(SUM(f.amount), 0) FROM transaction_relations r
+				JOIN transactions f ON f.item_id = r.item_id AND f.transaction_id = r.transaction_id
+				WHERE r.item_id = transactions.item_id AND r.related_transaction_id = transactions.transaction_id
+					AND r.transaction_id != ? AND r.type = ? AND r.status != ?
+			), 2) >= ?`,
+			refund.TransactionID, ledger.TransactionRelationTypeRefund, ledger.TransactionRelationStatusRejected, math.Round(refund.Amount*100)/100,
+		)).
+		Where(sq.GtOrEq{"date": refund.Date.Add(-window).Format("2006-01-02")}).
+		Where(sq.LtOrEq{"date": refund.Date.Format("2006-01-02")}).
+		OrderBy("date desc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.RefundCandidates]")
+	}
+
+	var transactions = make([]*ledger.Transaction, 0)
+	err = r.db.SelectContext(ctx, &transactions, query, args...)
+
+	return transactions, errors.Wrap(err, "[mysql.RefundCandidates]")
+
+}
+
+// ReceiptCandidates returns the transactions across all of the users items whose amount matches
+// the total of a receipt and that posted between from and to
+func (r *transactionRepository) ReceiptCandidates(ctx context.Context, userID uuid.UUID, total float64, from, to time.Time) ([]*ledger.Transaction, error) {
+
+	query, args, err := sq.Select(transactionColumns...).
+		From(transactionsTableName).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		Where(sq.Eq{
+			"hidden_at":  nil,
+			"deleted_at": nil,
+		}).
+		Where(sq.Expr("ABS(amount) BETWEEN ? AND ?", total-0.01, total+0.01)).
+		Where(sq.GtOrEq{"date": from.Format("2006-01-02")}).
+		Where(sq.LtOrEq{"date": to.Format("2006-01-02")}).
+		OrderBy("date desc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.ReceiptCandidates]")
+	}
+
+	var transactions = make([]*ledger.Transaction, 0)
+	err = r.db.SelectContext(ctx, &transactions, query, args...)
+
+	return transactions, errors.Wrap(err, "[mysql.ReceiptCandidates]")
+
+}
+
+// StatementCandidates returns the transactions of the account that posted between from and to,
+// including hidden ones, so that a statement does not bring back a transaction the user hid
+func (r *transactionRepository) StatementCandidates(ctx context.Context, itemID, accountID string, from, to time.Time) ([]*ledger.Transaction, error) {
+
+	query, args, err := sq.Select(transactionColumns...).
+		From(transactionsTableName).
+		Where(sq.Eq{
+			"item_id":    itemID,
+			"account_id": accountID,
+			"deleted_at": nil,
+		}).
+		Where(sq.GtOrEq{"date": from.Format("2006-01-02")}).
+		Where(sq.LtOrEq{"date": to.Format("2006-01-02")}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.StatementCandidates]")
+	}
+
+	var transactions = make([]*ledger.Transaction, 0)
+	err = r.db.SelectContext(ctx, &transactions, query, args...)
+
+	return transactions, errors.Wrap(err, "[mysql.StatementCandidates]")
+
+}
+
+// RecurringCandidates returns the posted outflows across all of the users items that are
+// attributed to a merchant and dated on or after from, ordered by merchant and date
+func (r *transactionRepository) RecurringCandidates(ctx context.Context, userID uuid.UUID, from time.Time) ([]*ledger.Transaction, error) {
+
+	query, args, err := sq.Select(transactionColumns...).
+		From(transactionsTableName).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		Where(sq.Eq{
+			"pending":    false,
+			"hidden_at":  nil,
+			"deleted_at": nil,
+		}).
+		Where(sq.NotEq{"merchant_id": ""}).
+		Where(sq.Lt{"amount": 0}).
+		Where(sq.GtOrEq{"date": from.Format("2006-01-02")}).
+		OrderBy("merchant_id asc", "date asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.RecurringCandidates]")
+	}
+
+	var transactions = make([]*ledger.Transaction, 0)
+	err = r.db.SelectContext(ctx, &transactions, query, args...)
+
+	return transactions, errors.Wrap(err, "[mysql.RecurringCandidates]")
+
+}
+
+// transactionCurrencyCode mirrors ledger.Transaction.CurrencyCode
+const transactionCurrencyCode = "COALESCE(iso_currency_code, unofficial_currency_code, '')"
+
+// matchedRefund is true for a transaction that the user confirmed as the refund of a charge. The
+// reports net these against the charge rather than counting them on their own. Suggested matches
+// are only guesses, so they are counted as income until they are confirmed
+const matchedRefund = "EXISTS (SELECT 1 FROM transaction_relations r WHERE r.item_id = transactions.item_id AND r.transaction_id = transactions.transaction_id AND r.type = 'refund' AND r.status = 'confirmed')"
+
+// refundedChargeColumn selects column from the charge a confirmed refund was matched to, and from the
+// transaction itself otherwise, so that refunds are grouped with the charge they refund
+func refundedChargeColumn(column string) string {
+	return fmt.Sprintf(
+		"CASE WHEN %[2]s THEN (SELECT c.%[1]s FROM transaction_relations r JOIN transactions c ON c.item_id = r.item_id AND c.transaction_id = r.related_transaction_id WHERE r.item_id = transactions.item_id AND r.transaction_id = transactions.transaction_id AND r.type = 'refund' AND r.status = 'confirmed') ELSE %[1]s END",
+		column, matchedRefund,
+	)
+}
+
+// SpendingTotals sums the transactions across all of the users items that match filters, grouped by
+// groupBy, date and currency. Confirmed refunds are grouped with the charge they refund and are not
+// counted as transactions of their own. Hidden transactions are always excluded and the pagination
+// fields of the filter are ignored
+func (r *transactionRepository) SpendingTotals(ctx context.Context, userID uuid.UUID, groupBy ledger.SpendingGroupBy, filters *ledger.TransactionFilter) ([]*ledger.SpendingTotal, error) {
+
+	var columns, groups []string
+	switch groupBy {
+	case ledger.SpendingGroupByCategory:
+		columns = []string{refundedChargeColumn("category_id") + " AS group_key", "NULL AS item_id"}
+		groups = []string{"group_key"}
+	case ledger.SpendingGroupByMerchant:
+		columns = []string{"merchant_id AS group_key", "NULL AS item_id"}
+		groups = []string{"merchant_id"}
+	case ledger.SpendingGroupByAccount:
+		columns = []string{refundedChargeColumn("account_id") + " AS group_key", "item_id"}
+		groups = []string{"item_id", "group_key"}
+	default:
+		return nil, errors.Errorf("[mysql.SpendingTotals] unsupported grouping %s", groupBy)
+	}
+
+	var xfilters ledger.TransactionFilter
+	if filters != nil {
+		xfilters = *filters
+	}
+	xfilters.FromTransactionID = null.NewString("", false)
+	xfilters.Limit = null.NewUint64(0, false)
+	xfilters.Hidden = null.NewBool(false, false)
+
+	columns = append(columns,
+		"date",
+		transactionCurrencyCode+" AS currency_code",
+		"SUM(amount) AS total",
+		"SUM(CASE WHEN "+matchedRefund+" THEN 0 ELSE 1 END) AS count",
+	)
+
+	stmt := sq.Select(columns...).
+		From(transactionsTableName).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		GroupBy(append(groups, "date", "currency_code")...)
+	stmt = transactionsQueryBuilder(stmt, &xfilters)
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.SpendingTotals]")
+	}
+
+	var totals = make([]*ledger.SpendingTotal, 0)
+	err = r.db.SelectContext(ctx, &totals, query, args...)
+
+	return totals, errors.Wrap(err, "[mysql.SpendingTotals]")
+
+}
+
+// DiscretionarySpending sums the posted outflows of the user between from and to, inclusive, by account
+// and category. Transactions at merchants the user has a recurring series with are left out, they are
+// forecast from the series instead
+func (r *transactionRepository) DiscretionarySpending(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*ledger.DiscretionarySpend, error) {
+
+	query, args, err := sq.Select(
+		"item_id",
+		"account_id",
+		"category_id",
+		"-SUM(amount) AS total",
+	).
+		From(transactionsTableName).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		Where(sq.Expr("(merchant_id IS NULL OR merchant_id NOT IN (SELECT merchant_id FROM recurring_series WHERE user_id = ? AND merchant_id IS NOT NULL))", userID)).
+		Where(sq.Eq{
+			"pending":    false,
+			"hidden_at":  nil,
+			"deleted_at": nil,
+		}).
+		Where(sq.Lt{"amount": 0}).
+		Where(sq.GtOrEq{"date": from.Format("2006-01-02")}).
+		Where(sq.LtOrEq{"date": to.Format("2006-01-02")}).
+		GroupBy("item_id", "account_id", "category_id").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.DiscretionarySpending]")
+	}
+
+	var spending = make([]*ledger.DiscretionarySpend, 0)
+	err = r.db.SelectContext(ctx, &spending, query, args...)
+
+	return spending, errors.Wrap(err, "[mysql.DiscretionarySpending]")
+
+}
+
+// ExportTransactions calls fn with each of the users transactions that match filters, oldest first.
+// Rows are read from the database as fn is called, so the transactions are never all held in memory.
+// Iteration stops at the first error fn returns
+func (r *transactionRepository) ExportTransactions(ctx context.Context, userID uuid.UUID, filters *ledger.TransactionFilter, fn func(row *ledger.TransactionExportRow) error) error {
+
+	stmt := sq.Select(
+		"t.item_id",
+		"t.account_id",
+		"t.transaction_id",
+		"t.name",
+		"t.date",
+		"t.authorized_date",
+		"t.amount",
+		"t.iso_currency_code",
+		"t.unofficial_currency_code",
+		"t.pending",
+		"t.payment_channel",
+		"t.hidden_reason",
+		"t.category_id",
+		"m.name AS merchant_name",
+		"c.name AS category_name",
+		"COALESCE(c.hierarchy, JSON_ARRAY()) AS category_hierarchy",
+		"a.name AS account_name",
+		"a.mask AS account_mask",
+	).
+		From(transactionsTableName+" t").
+		LeftJoin(merchantsTable+" m ON m.id = t.merchant_id").
+		LeftJoin(plaidCategoriesTable+" c ON c.id = t.category_id").
+		LeftJoin(accountTable+" a ON a.item_id = t.item_id AND a.account_id = t.account_id").
+		Where(sq.Expr("t.item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		OrderBy("t.date asc", "t.transaction_id asc")
+	stmt = transactionsQueryBuilder(stmt, filters)
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.ExportTransactions]")
+	}
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "[mysql.ExportTransactions]")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row = new(ledger.TransactionExportRow)
+		err = rows.StructScan(row)
+		if err != nil {
+			return errors.Wrap(err, "[mysql.ExportTransactions]")
+		}
+
+		err = fn(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(rows.Err(), "[mysql.ExportTransactions]")
+
+}
+
+// cashFlowPeriods truncate the date of a transaction to the start of the interval it falls in
+var cashFlowPeriods = map[ledger.Interval]string{
+	ledger.IntervalDay:   "date",
+	ledger.IntervalWeek:  "DATE_SUB(date, INTERVAL WEEKDAY(date) DAY)",
+	ledger.IntervalMonth: "CAST(DATE_FORMAT(date, '%Y-%m-01') AS DATE)",
+	ledger.IntervalYear:  "CAST(DATE_FORMAT(date, '%Y-01-01') AS DATE)",
+}
+
+// CashFlowTotals sums the income and expenses of the users transactions that posted between from
+// and to, inclusive, by interval, date and currency. Positive amounts are income and negative amounts
+// are expenses, except for confirmed refunds which reduce the expenses instead. accountIDs limits
+// the totals to those accounts when it is not empty
+func (r *transactionRepository) CashFlowTotals(ctx context.Context, userID uuid.UUID, interval ledger.Interval, from, to time.Time, accountIDs []string) ([]*ledger.CashFlowTotal, error) {
+
+	period, ok := cashFlowPeriods[interval]
+	if !ok {
+		return nil, errors.Errorf("[mysql.CashFlowTotals] unsupported interval %s", interval)
+	}
+
+	stmt := sq.Select(
+		period+" AS period",
+		"date",
+		transactionCurrencyCode+" AS currency_code",
+		"COALESCE(SUM(CASE WHEN amount > 0 AND NOT "+matchedRefund+" THEN amount ELSE 0 END), 0) AS income",
+		"COALESCE(SUM(CASE WHEN amount < 0 OR "+matchedRefund+" THEN -amount ELSE 0 END), 0) AS expenses",
+		"COUNT(*) AS count",
+	).
+		From(transactionsTableName).
+		Where(sq.Expr("item_id IN (SELECT item_id FROM user_items WHERE user_id = ?)", userID)).
+		Where(sq.Eq{
+			"hidden_at":  nil,
+			"deleted_at": nil,
+		}).
+		Where(sq.GtOrEq{"date": from.Format("2006-01-02")}).
+		Where(sq.LtOrEq{"date": to.Format("2006-01-02")}).
+		GroupBy("period", "date", "currency_code").
+		OrderBy("date asc")
+	if len(accountIDs) > 0 {
+		stmt = stmt.Where(sq.Eq{"account_id": accountIDs})
+	}
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CashFlowTotals]")
+	}
+
+	var totals = make([]*ledger.CashFlowTotal, 0)
+	err = r.db.SelectContext(ctx, &totals, query, args...)
+
+	return totals, errors.Wrap(err, "[mysql.CashFlowTotals]")
+
+}
+
+func (r *transactionRepository) TransactionRelation(ctx context.Context, itemID, relationID string) (*ledger.TransactionRelation, error) {
+
+	query, args, err := sq.Select(transactionRelationColumns...).
+		From(transactionRelationsTableName).
+		Where(sq.Eq{
+			"item_id":     itemID,
+			"relation_id": relationID,
+		}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.TransactionRelation]")
+	}
+
+	var relation = new(ledger.TransactionRelation)
+	err = r.db.GetContext(ctx, relation, query, args...)
+
+	return relation, errors.Wrap(err, "[mysql.TransactionRelation]")
+
+}
+
+// TransactionRelations returns all relations the transaction is a part of, regardless of which side it is on
+func (r *transactionRepository) TransactionRelations(ctx context.Context, itemID, transactionID string) ([]*ledger.TransactionRelation, error) {
+
+	query, args, err := sq.Select(transactionRelationColumns...).
+		From(transactionRelationsTableName).
+		Where(sq.Eq{"item_id": itemID}).
+		Where(sq.Or{
+			sq.Eq{"transaction_id": transactionID},
+			sq.Eq{"related_transaction_id": transactionID},
+		}).
+		OrderBy("created_at asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.TransactionRelations]")
+	}
+
+	var relations = make([]*ledger.TransactionRelation, 0)
+	err = r.db.SelectContext(ctx, &relations, query, args...)
+
+	return relations, errors.Wrap(err, "[mysql.TransactionRelations]")
+
+}
+
+func (r *transactionRepository) CreateTransactionRelation(ctx context.Context, relation *ledger.TransactionRelation) (*ledger.TransactionRelation, error) {
+
+	query, args, err := sq.Insert(transactionRelationsTableName).SetMap(map[string]interface{}{
+		"relation_id":            relation.RelationID,
+		"item_id":                relation.ItemID,
+		"transaction_id":         relation.TransactionID,
+		"related_transaction_id": relation.RelatedTransactionID,
+		"type":                   relation.Type,
+		"status":                 relation.Status,
+		"created_at":             sq.Expr(`NOW()`),
+		"updated_at":             sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateTransactionRelation]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateTransactionRelation]")
+	}
+
+	return r.TransactionRelation(ctx, relation.ItemID, relation.RelationID)
+
+}
+
+func (r *transactionRepository) UpdateTransactionRelation(ctx context.Context, relationID string, relation *ledger.TransactionRelation) (*ledger.TransactionRelation, error) {
+
+	query, args, err := sq.Update(transactionRelationsTableName).SetMap(map[string]interface{}{
+		"related_transaction_id": relation.RelatedTransactionID,
+		"status":                 relation.Status,
+		"updated_at":             sq.Expr(`NOW()`),
+	}).Where(sq.Eq{"relation_id": relationID}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateTransactionRelation]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateTransactionRelation]")
+	}
+
+	return r.TransactionRelation(ctx, relation.ItemID, relationID)
+
+}
+
+// CreateTransactionLocation stores where a transaction was made, replacing the location already
+// stored for it
+func (r *transactionRepository) CreateTransactionLocation(ctx context.Context, location *ledger.TransactionLocation) error {
+
+	query, args, err := sq.Insert(transactionLocationsTableName).SetMap(map[string]interface{}{
+		"item_id":        location.ItemID,
+		"transaction_id": location.TransactionID,
+		"address":        location.Address,
+		"city":           location.City,
+		"region":         location.Region,
+		"postal_code":    location.PostalCode,
+		"country":        location.Country,
+		"lat":            location.Lat,
+		"lon":            location.Lon,
+		"store_number":   location.StoreNumber,
+		"created_at":     sq.Expr(`NOW()`),
+		"updated_at":     sq.Expr(`NOW()`),
+	}).Suffix(`ON DUPLICATE KEY UPDATE
+		address = VALUES(address),
+		city = VALUES(city),
+		region = VALUES(region),
+		postal_code = VALUES(postal_code),
+		country = VALUES(country),
+		lat = VALUES(lat),
+		lon = VALUES(lon),
+		store_number = VALUES(store_number),
+		updated_at = VALUES(updated_at)`).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.CreateTransactionLocation]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.CreateTransactionLocation]")
+
+}
+
+func (r *transactionRepository) TransactionAttachment(ctx context.Context, itemID, attachmentID string) (*ledger.TransactionAttachment, error) {
+
+	query, args, err := sq.Select(transactionAttachmentColumns...).
+		From(transactionAttachmentsTableName).
+		Where(sq.Eq{
+			"item_id":       itemID,
+			"attachment_id": attachmentID,
+		}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.TransactionAttachment]")
+	}
+
+	var attachment = new(ledger.TransactionAttachment)
+	err = r.db.GetContext(ctx, attachment, query, args...)
+
+	return attachment, errors.Wrap(err, "[mysql.TransactionAttachment]")
+
+}
+
+func (r *transactionRepository) TransactionAttachments(ctx context.Context, itemID, transactionID string) ([]*ledger.TransactionAttachment, error) {
+
+	query, args, err := sq.Select(transactionAttachmentColumns...).
+		From(transactionAttachmentsTableName).
+		Where(sq.Eq{
+			"item_id":        itemID,
+			"transaction_id": transactionID,
+		}).
+		OrderBy("uploaded_at asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.TransactionAttachments]")
+	}
+
+	var attachments = make([]*ledger.TransactionAttachment, 0)
+	err = r.db.SelectContext(ctx, &attachments, query, args...)
+
+	return attachments, errors.Wrap(err, "[mysql.TransactionAttachments]")
+
+}
+
+func (r *transactionRepository) CreateTransactionAttachment(ctx context.Context, attachment *ledger.TransactionAttachment) (*ledger.TransactionAttachment, error) {
+
+	query, args, err := sq.Insert(transactionAttachmentsTableName).SetMap(map[string]interface{}{
+		"attachment_id":  attachment.AttachmentID,
+		"item_id":        attachment.ItemID,
+		"transaction_id": attachment.TransactionID,
+		"object_key":     attachment.ObjectKey,
+		"thumbnail_key":  attachment.ThumbnailKey,
+		"filename":       attachment.Filename,
+		"content_type":   attachment.ContentType,
+		"size":           attachment.Size,
+		"checksum":       attachment.Checksum,
+		"uploaded_at":    sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateTransactionAttachment]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateTransactionAttachment]")
+	}
+
+	return r.TransactionAttachment(ctx, attachment.ItemID, attachment.AttachmentID)
+
+}
+
+func (r *transactionRepository) DeleteTransactionAttachment(ctx context.Context, itemID, attachmentID string) error {
+
+	query, args, err := sq.Delete(transactionAttachmentsTableName).Where(sq.Eq{
+		"item_id":       itemID,
+		"attachment_id": attachmentID,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.DeleteTransactionAttachment]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.DeleteTransactionAttachment]")
+
+}
@@ -16,7 +16,7 @@ type userRepository struct {
 }
 
 var userColumns = []string{
-	"id", "email", "auth0_subject", "created_at", "updated_at",
+	"id", "email", "auth0_subject", "base_currency", "is_admin", "calendar_token", "created_at", "updated_at",
 }
 
 func NewUserRepository(db *sqlx.DB) ledger.UserRepository {
@@ -68,13 +68,79 @@ func (r *userRepository) UserByEmail(ctx context.Context, email string) (*ledger
 
 }
 
+func (r *userRepository) UserByCalendarToken(ctx context.Context, token string) (*ledger.User, error) {
+
+	query := sq.Select(userColumns...).
+		From("users").
+		Where(sq.Eq{"calendar_token": token}).
+		Limit(1)
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sql stmt: %w", err)
+	}
+
+	var user = new(ledger.User)
+	err = r.db.GetContext(ctx, user, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+
+}
+
+func (r *userRepository) Users(ctx context.Context) ([]*ledger.User, error) {
+
+	query := sq.Select(userColumns...).
+		From("users").
+		OrderBy("created_at asc")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sql stmt: %w", err)
+	}
+
+	var users = make([]*ledger.User, 0)
+	err = r.db.SelectContext(ctx, &users, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+
+}
+
+func (r *userRepository) UsersByItemID(ctx context.Context, itemID string) ([]*ledger.User, error) {
+
+	query := sq.Select(userColumns...).
+		From("users").
+		Where(sq.Expr("id IN (SELECT user_id FROM user_items WHERE item_id = ?)", itemID)).
+		OrderBy("created_at asc")
+
+	stmt, args, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sql stmt: %w", err)
+	}
+
+	var users = make([]*ledger.User, 0)
+	err = r.db.SelectContext(ctx, &users, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+
+}
+
 func (r *userRepository) CreateUser(ctx context.Context, user *ledger.User) (*ledger.User, error) {
 
 	query := sq.Insert("users").Columns(
 		userColumns...,
 	).Values(
 		user.ID, user.Email,
-		user.Auth0Subject, time.Now(), time.Now(),
+		user.Auth0Subject, user.BaseCurrency, user.IsAdmin, user.CalendarToken,
+		time.Now(), time.Now(),
 	)
 
 	stmt, args, err := query.ToSql()
@@ -96,6 +162,9 @@ func (r *userRepository) UpdateUser(ctx context.Context, id uuid.UUID, user *led
 	query := sq.Update("users").
 		Set("email", user.Email).
 		Set("auth0_subject", user.Auth0Subject).
+		Set("base_currency", user.BaseCurrency).
+		Set("calendar_token", user.CalendarToken).
+		Set("updated_at", time.Now()).
 		Where(sq.Eq{"id": id})
 
 	stmt, args, err := query.ToSql()
@@ -0,0 +1,200 @@
+package mysql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+type webhookEndpointRepository struct {
+	db *sqlx.DB
+}
+
+const (
+	webhookEndpointsTable  = "webhook_endpoints"
+	webhookDeliveriesTable = "webhook_deliveries"
+)
+
+var webhookEndpointColumns = []string{
+	"endpoint_id",
+	"user_id",
+	"url",
+	"description",
+	"secret",
+	"event_types",
+	"enabled",
+	"created_at",
+	"updated_at",
+}
+
+var webhookDeliveryColumns = []string{
+	"delivery_id",
+	"endpoint_id",
+	"user_id",
+	"event_id",
+	"event_type",
+	"attempt",
+	"succeeded",
+	"status_code",
+	"error",
+	"duration_ms",
+	"created_at",
+}
+
+func NewWebhookEndpointRepository(db *sqlx.DB) ledger.WebhookEndpointRepository {
+	return &webhookEndpointRepository{db: db}
+}
+
+func (r *webhookEndpointRepository) WebhookEndpoint(ctx context.Context, userID uuid.UUID, endpointID string) (*ledger.WebhookEndpoint, error) {
+
+	query, args, err := sq.Select(webhookEndpointColumns...).From(webhookEndpointsTable).Where(sq.Eq{
+		"user_id":     userID,
+		"endpoint_id": endpointID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.WebhookEndpoint]")
+	}
+
+	var endpoint = new(ledger.WebhookEndpoint)
+	err = r.db.GetContext(ctx, endpoint, query, args...)
+
+	return endpoint, errors.Wrap(err, "[mysql.WebhookEndpoint]")
+
+}
+
+func (r *webhookEndpointRepository) WebhookEndpoints(ctx context.Context, userID uuid.UUID) ([]*ledger.WebhookEndpoint, error) {
+
+	query, args, err := sq.Select(webhookEndpointColumns...).
+		From(webhookEndpointsTable).
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("created_at asc", "endpoint_id asc").
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.WebhookEndpoints]")
+	}
+
+	var endpoints = make([]*ledger.WebhookEndpoint, 0)
+	err = r.db.SelectContext(ctx, &endpoints, query, args...)
+
+	return endpoints, errors.Wrap(err, "[mysql.WebhookEndpoints]")
+
+}
+
+func (r *webhookEndpointRepository) CreateWebhookEndpoint(ctx context.Context, endpoint *ledger.WebhookEndpoint) (*ledger.WebhookEndpoint, error) {
+
+	query, args, err := sq.Insert(webhookEndpointsTable).SetMap(map[string]interface{}{
+		"endpoint_id": endpoint.EndpointID,
+		"user_id":     endpoint.UserID,
+		"url":         endpoint.URL,
+		"description": endpoint.Description,
+		"secret":      endpoint.Secret,
+		"event_types": endpoint.EventTypes,
+		"enabled":     endpoint.Enabled,
+		"created_at":  sq.Expr(`NOW()`),
+		"updated_at":  sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateWebhookEndpoint]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.CreateWebhookEndpoint]")
+	}
+
+	return r.WebhookEndpoint(ctx, endpoint.UserID, endpoint.EndpointID)
+
+}
+
+func (r *webhookEndpointRepository) UpdateWebhookEndpoint(ctx context.Context, endpointID string, endpoint *ledger.WebhookEndpoint) (*ledger.WebhookEndpoint, error) {
+
+	query, args, err := sq.Update(webhookEndpointsTable).SetMap(map[string]interface{}{
+		"url":         endpoint.URL,
+		"description": endpoint.Description,
+		"secret":      endpoint.Secret,
+		"event_types": endpoint.EventTypes,
+		"enabled":     endpoint.Enabled,
+		"updated_at":  sq.Expr(`NOW()`),
+	}).Where(sq.Eq{
+		"user_id":     endpoint.UserID,
+		"endpoint_id": endpointID,
+	}).ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateWebhookEndpoint]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.UpdateWebhookEndpoint]")
+	}
+
+	return r.WebhookEndpoint(ctx, endpoint.UserID, endpointID)
+
+}
+
+func (r *webhookEndpointRepository) DeleteWebhookEndpoint(ctx context.Context, userID uuid.UUID, endpointID string) error {
+
+	query, args, err := sq.Delete(webhookEndpointsTable).Where(sq.Eq{
+		"user_id":     userID,
+		"endpoint_id": endpointID,
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.DeleteWebhookEndpoint]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.DeleteWebhookEndpoint]")
+
+}
+
+func (r *webhookEndpointRepository) WebhookDeliveries(ctx context.Context, userID uuid.UUID, endpointID string, limit uint64) ([]*ledger.WebhookDelivery, error) {
+
+	query, args, err := sq.Select(webhookDeliveryColumns...).
+		From(webhookDeliveriesTable).
+		Where(sq.Eq{
+			"user_id":     userID,
+			"endpoint_id": endpointID,
+		}).
+		OrderBy("created_at desc", "attempt desc").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "[mysql.WebhookDeliveries]")
+	}
+
+	var deliveries = make([]*ledger.WebhookDelivery, 0)
+	err = r.db.SelectContext(ctx, &deliveries, query, args...)
+
+	return deliveries, errors.Wrap(err, "[mysql.WebhookDeliveries]")
+
+}
+
+func (r *webhookEndpointRepository) CreateWebhookDelivery(ctx context.Context, delivery *ledger.WebhookDelivery) error {
+
+	query, args, err := sq.Insert(webhookDeliveriesTable).SetMap(map[string]interface{}{
+		"delivery_id": delivery.DeliveryID,
+		"endpoint_id": delivery.EndpointID,
+		"user_id":     delivery.UserID,
+		"event_id":    delivery.EventID,
+		"event_type":  delivery.EventType,
+		"attempt":     delivery.Attempt,
+		"succeeded":   delivery.Succeeded,
+		"status_code": delivery.StatusCode,
+		"error":       delivery.Error,
+		"duration_ms": delivery.DurationMS,
+		"created_at":  sq.Expr(`NOW()`),
+	}).ToSql()
+	if err != nil {
+		return errors.Wrap(err, "[mysql.CreateWebhookDelivery]")
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+
+	return errors.Wrap(err, "[mysql.CreateWebhookDelivery]")
+
+}
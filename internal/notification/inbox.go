@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/null"
+)
+
+// HandleEvent adds a notification to the inbox of the user of the event for item errors, imports that
+// brought in new transactions, alerts and newly detected subscriptions. Other events are ignored. The
+// new transactions of a DEFAULT_UPDATE are also emailed to the user. It is subscribed to the event
+// emitter, so errors are logged rather than returned
+func (s *service) HandleEvent(ctx context.Context, event *ledger.Event) {
+
+	entry := s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"event_id":   event.EventID,
+		"event_type": event.Type,
+		"user_id":    event.UserID,
+	})
+
+	if data, ok := event.Data.(*ledger.ImportCompletedEvent); ok && data.WebhookCode.SendEmail() {
+		err := s.notifyNewTransactions(ctx, event.UserID, data.Created)
+		if err != nil {
+			entry.WithError(err).Error("failed to send new transactions email")
+		}
+	}
+
+	notification, err := s.notificationForEvent(ctx, event)
+	if err != nil {
+		entry.WithError(err).Error("failed to build notification for event")
+		return
+	}
+
+	if notification == nil {
+		return
+	}
+
+	notification.NotificationID = uuid.Must(uuid.NewV4()).String()
+	notification.UserID = event.UserID
+	notification.Type = event.Type
+	notification.Title = internal.Truncate(strings.TrimSpace(notification.Title), 255)
+	notification.Message = internal.Truncate(strings.TrimSpace(notification.Message), 1024)
+
+	_, err = s.CreateNotification(ctx, notification)
+	if err != nil {
+		entry.WithError(err).Error("failed to create notification")
+	}
+
+}
+
+// notificationForEvent returns the notification the event is shown as, or nil when it is not shown
+func (s *service) notificationForEvent(ctx context.Context, event *ledger.Event) (*ledger.Notification, error) {
+
+	switch data := event.Data.(type) {
+	case *ledger.ItemErrorEvent:
+		// An item that cannot be imported fails again on every webhook until the user fixes it, the
+		// first notification is enough until it has been read
+		unread, err := s.Notifications(ctx, event.UserID, true, 100)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch unread notifications")
+		}
+
+		for _, notification := range unread {
+			if notification.Type == ledger.EventItemError && notification.ItemID.String == data.ItemID {
+				return nil, nil
+			}
+		}
+
+		return &ledger.Notification{
+			Title:   "An account could not be refreshed",
+			Message: data.Error,
+			ItemID:  null.StringFrom(data.ItemID),
+		}, nil
+	case *ledger.ImportCompletedEvent:
+		if data.NewTransactions == 0 {
+			return nil, nil
+		}
+
+		from := "your bank"
+		if data.Source == ledger.ImportSourceStatement {
+			from = "a statement"
+		}
+
+		return &ledger.Notification{
+			Title:   pluralize(data.NewTransactions, "new transaction", "new transactions"),
+			Message: fmt.Sprintf("%s imported from %s", pluralize(data.NewTransactions, "transaction was", "transactions were"), from),
+			ItemID:  null.StringFrom(data.ItemID),
+		}, nil
+	case *ledger.Alert:
+		return &ledger.Notification{
+			Title:       alertTitles[data.Type],
+			Message:     data.Message,
+			ItemID:      data.ItemID,
+			ReferenceID: null.StringFrom(data.AlertID),
+		}, nil
+	case *ledger.RecurringSeries:
+		title := "New subscription detected"
+		if data.Kind == ledger.RecurringKindIncome {
+			title = "New recurring income detected"
+		}
+
+		return &ledger.Notification{
+			Title:       title,
+			Message:     fmt.Sprintf("%s, %.2f %s %s", data.Name, data.Amount, data.CurrencyCode, data.Frequency),
+			ItemID:      data.ItemID,
+			ReferenceID: null.StringFrom(data.SeriesID),
+		}, nil
+	}
+
+	return nil, nil
+
+}
+
+var alertTitles = map[ledger.AlertRuleType]string{
+	ledger.AlertRuleTypeLargeTransaction: "Large transaction",
+	ledger.AlertRuleTypeLowBalance:       "Low balance",
+	ledger.AlertRuleTypeBudget:           "Budget threshold reached",
+	ledger.AlertRuleTypeNewMerchant:      "New merchant",
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+
+	return fmt.Sprintf("%d %s", n, plural)
+}
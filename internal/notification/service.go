@@ -0,0 +1,342 @@
+// Package notification provides service access to the emails sent to users about their transactions
+// and to the notifications in their inbox
+package notification
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/null"
+)
+
+// digestLargest is how many of the largest outflows are listed in a digest
+const digestLargest = 10
+
+type Service interface {
+	SendDigests(ctx context.Context, frequency ledger.DigestFrequency, now time.Time) error
+	HandleEvent(ctx context.Context, event *ledger.Event)
+	ledger.NotificationRepository
+}
+
+type service struct {
+	logger       *logrus.Logger
+	mailer       ledger.Mailer
+	accounts     ledger.AccountRepository
+	transactions ledger.TransactionRepository
+	users        ledger.UserRepository
+
+	ledger.NotificationRepository
+}
+
+// New returns a notification Service. mailer may be nil, in which case no emails are sent
+func New(
+	logger *logrus.Logger,
+	mailer ledger.Mailer,
+	accounts ledger.AccountRepository,
+	transactions ledger.TransactionRepository,
+	users ledger.UserRepository,
+	notifications ledger.NotificationRepository,
+) Service {
+	return &service{
+		logger:                 logger,
+		mailer:                 mailer,
+		accounts:               accounts,
+		transactions:           transactions,
+		users:                  users,
+		NotificationRepository: notifications,
+	}
+}
+
+// NotificationPreferences returns the saved preferences of the user, or the defaults when the user
+// has not saved any
+func (s *service) NotificationPreferences(ctx context.Context, userID uuid.UUID) (*ledger.NotificationPreferences, error) {
+
+	preferences, err := s.NotificationRepository.NotificationPreferences(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ledger.DefaultNotificationPreferences(userID), nil
+	}
+
+	return preferences, err
+
+}
+
+func (s *service) SaveNotificationPreferences(ctx context.Context, preferences *ledger.NotificationPreferences) (*ledger.NotificationPreferences, error) {
+
+	if preferences.Digest == "" {
+		preferences.Digest = ledger.DigestFrequencyNone
+	}
+
+	if !preferences.Digest.Valid() {
+		return nil, errors.Errorf("%s is not a valid digest frequency, valid frequencies are none, daily and weekly", preferences.Digest)
+	}
+
+	return s.NotificationRepository.SaveNotificationPreferences(ctx, preferences)
+
+}
+
+// notifyNewTransactions emails the user a list of the transactions when they want to hear about new
+// transactions. Hidden transactions are left out
+func (s *service) notifyNewTransactions(ctx context.Context, userID uuid.UUID, transactions []*ledger.Transaction) error {
+
+	if s.mailer == nil {
+		return nil
+	}
+
+	var visible = make([]*ledger.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if !transaction.HiddenAt.Valid {
+			visible = append(visible, transaction)
+		}
+	}
+
+	if len(visible) == 0 {
+		return nil
+	}
+
+	preferences, err := s.NotificationPreferences(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "[notification.notifyNewTransactions] failed to fetch notification preferences")
+	}
+
+	if !preferences.NewTransactions {
+		return nil
+	}
+
+	user, err := s.users.User(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "[notification.notifyNewTransactions] failed to fetch user")
+	}
+
+	names, err := s.accountNames(ctx, user.ID)
+	if err != nil {
+		return errors.Wrap(err, "[notification.notifyNewTransactions] failed to fetch account names")
+	}
+
+	var data = newTransactionsData{Transactions: make([]*emailTransaction, 0, len(visible))}
+	for _, transaction := range visible {
+		payee := transaction.Name
+		if transaction.MerchantName.Valid && transaction.MerchantName.String != "" {
+			payee = transaction.MerchantName.String
+		}
+
+		data.Transactions = append(data.Transactions, &emailTransaction{
+			Date:    transaction.Date.Format("Jan 2"),
+			Payee:   payee,
+			Account: names[transaction.AccountID],
+			Amount:  formatAmount(transaction.Amount, transaction.CurrencyCode()),
+			Pending: transaction.Pending,
+		})
+	}
+
+	subject := "1 new transaction"
+	if len(data.Transactions) != 1 {
+		subject = fmt.Sprintf("%d new transactions", len(data.Transactions))
+	}
+
+	err = s.send(ctx, user, subject, newTransactionsTemplates, data)
+
+	return errors.Wrap(err, "[notification.notifyNewTransactions] failed to send email")
+
+}
+
+// SendDigests emails every user with a digest of frequency a summary of their transactions. Daily
+// digests cover the day before now, weekly digests the seven days before now. Users without any
+// transactions in the period are not sent a digest
+func (s *service) SendDigests(ctx context.Context, frequency ledger.DigestFrequency, now time.Time) error {
+
+	if s.mailer == nil {
+		return nil
+	}
+
+	var days int
+	switch frequency {
+	case ledger.DigestFrequencyDaily:
+		days = 1
+	case ledger.DigestFrequencyWeekly:
+		days = 7
+	default:
+		return errors.Errorf("[notification.SendDigests] digests cannot be sent %s", frequency)
+	}
+
+	end := now.AddDate(0, 0, -1)
+	start := now.AddDate(0, 0, -days)
+
+	preferences, err := s.NotificationPreferencesByDigest(ctx, frequency)
+	if err != nil {
+		return errors.Wrap(err, "[notification.SendDigests] failed to fetch notification preferences")
+	}
+
+	for _, preference := range preferences {
+		entry := s.logger.WithContext(ctx).WithField("user_id", preference.UserID)
+
+		user, err := s.users.User(ctx, preference.UserID)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch user")
+			continue
+		}
+
+		data, err := s.digest(ctx, user.ID, start, end)
+		if err != nil {
+			entry.WithError(err).Error("failed to build digest")
+			continue
+		}
+
+		if data.Count == 0 {
+			continue
+		}
+
+		data.Frequency = string(frequency)
+		data.Period = start.Format("Jan 2")
+		if days > 1 {
+			data.Period = fmt.Sprintf("%s to %s", start.Format("Jan 2"), end.Format("Jan 2"))
+		}
+
+		subject := fmt.Sprintf("Your %s digest for %s", frequency, data.Period)
+		err = s.send(ctx, user, subject, digestTemplates, data)
+		if err != nil {
+			entry.WithError(err).Error("failed to send digest")
+		}
+	}
+
+	return nil
+
+}
+
+// digest summarises the transactions of the user between start and end inclusive
+func (s *service) digest(ctx context.Context, userID uuid.UUID, start, end time.Time) (*digestData, error) {
+
+	var data = new(digestData)
+	var totals = make(map[string]*digestTotal)
+	var outflows = make([]*ledger.TransactionExportRow, 0)
+
+	err := s.transactions.ExportTransactions(ctx, userID, &ledger.TransactionFilter{
+		StartDate:     null.TimeFrom(start),
+		EndDate:       null.TimeFrom(end),
+		DateInclusive: null.BoolFrom(true),
+	}, func(row *ledger.TransactionExportRow) error {
+		data.Count++
+
+		currency := row.ISOCurrencyCode.String
+		if !row.ISOCurrencyCode.Valid {
+			currency = row.UnofficialCurrencyCode.String
+		}
+
+		total, ok := totals[currency]
+		if !ok {
+			total = &digestTotal{Currency: currency}
+			totals[currency] = total
+		}
+
+		if row.Amount < 0 {
+			total.out += -row.Amount
+			outflows = append(outflows, row)
+		} else {
+			total.in += row.Amount
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, total := range totals {
+		total.In = formatAmount(total.in, total.Currency)
+		total.Out = formatAmount(total.out, total.Currency)
+		data.Totals = append(data.Totals, total)
+	}
+
+	sort.Slice(data.Totals, func(i, j int) bool {
+		return data.Totals[i].Currency < data.Totals[j].Currency
+	})
+
+	sort.SliceStable(outflows, func(i, j int) bool {
+		return outflows[i].Amount < outflows[j].Amount
+	})
+
+	if len(outflows) > digestLargest {
+		outflows = outflows[:digestLargest]
+	}
+
+	for _, row := range outflows {
+		payee := row.Name
+		if row.MerchantName.Valid && row.MerchantName.String != "" {
+			payee = row.MerchantName.String
+		}
+
+		currency := row.ISOCurrencyCode.String
+		if !row.ISOCurrencyCode.Valid {
+			currency = row.UnofficialCurrencyCode.String
+		}
+
+		data.Largest = append(data.Largest, &emailTransaction{
+			Date:    row.Date.Format("Jan 2"),
+			Payee:   payee,
+			Account: accountName(row.AccountName, row.AccountMask),
+			Amount:  formatAmount(row.Amount, currency),
+			Pending: row.Pending,
+		})
+	}
+
+	return data, nil
+
+}
+
+func (s *service) accountNames(ctx context.Context, userID uuid.UUID) (map[string]string, error) {
+
+	accounts, err := s.accounts.AccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var names = make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		names[account.AccountID] = accountName(account.Name, account.Mask)
+	}
+
+	return names, nil
+
+}
+
+func (s *service) send(ctx context.Context, user *ledger.User, subject string, templates *emailTemplates, data interface{}) error {
+
+	var text, html bytes.Buffer
+	err := templates.text.Execute(&text, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to render text body")
+	}
+
+	err = templates.html.Execute(&html, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to render html body")
+	}
+
+	return s.mailer.Send(ctx, &ledger.EmailMessage{
+		To:       []string{user.Email},
+		Subject:  subject,
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	})
+
+}
+
+func accountName(name, mask null.String) string {
+	if mask.Valid && mask.String != "" {
+		return strings.TrimSpace(fmt.Sprintf("%s %s", name.String, mask.String))
+	}
+
+	return name.String
+}
+
+func formatAmount(amount float64, currency string) string {
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", amount, currency))
+}
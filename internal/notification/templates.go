@@ -0,0 +1,95 @@
+package notification
+
+import (
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// emailTemplates render the plain text and HTML bodies of an email from the same data
+type emailTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// emailTransaction is a transaction formatted for display in an email
+type emailTransaction struct {
+	Date    string
+	Payee   string
+	Account string
+	Amount  string
+	Pending bool
+}
+
+type newTransactionsData struct {
+	Transactions []*emailTransaction
+}
+
+// digestTotal is the money that came in and went out in a currency. Amounts are formatted once the
+// digest is complete
+type digestTotal struct {
+	Currency string
+	In       string
+	Out      string
+
+	in, out float64
+}
+
+type digestData struct {
+	Frequency string
+	Period    string
+	Count     int
+	Totals    []*digestTotal
+	Largest   []*emailTransaction
+}
+
+var newTransactionsTemplates = &emailTemplates{
+	text: texttemplate.Must(texttemplate.New("new_transactions").Parse(
+		`New transactions were imported into your accounts:
+{{ range .Transactions }}
+{{ .Date }}  {{ .Payee }}  {{ .Amount }}{{ if .Account }}  ({{ .Account }}){{ end }}{{ if .Pending }}  pending{{ end }}{{ end }}
+
+Negative amounts are money leaving your accounts.
+`)),
+	html: htmltemplate.Must(htmltemplate.New("new_transactions").Parse(
+		`<p>New transactions were imported into your accounts:</p>
+<table cellpadding="4">
+{{- range .Transactions }}
+<tr><td>{{ .Date }}</td><td>{{ .Payee }}{{ if .Pending }} <em>(pending)</em>{{ end }}</td><td>{{ .Account }}</td><td align="right">{{ .Amount }}</td></tr>
+{{- end }}
+</table>
+<p>Negative amounts are money leaving your accounts.</p>
+`)),
+}
+
+var digestTemplates = &emailTemplates{
+	text: texttemplate.Must(texttemplate.New("digest").Parse(
+		`Your {{ .Frequency }} digest for {{ .Period }}
+
+{{ .Count }} transaction{{ if ne .Count 1 }}s{{ end }}
+{{ range .Totals }}
+In: {{ .In }}
+Out: {{ .Out }}
+{{ end }}{{ if .Largest }}
+Largest outflows:
+{{ range .Largest }}
+{{ .Date }}  {{ .Payee }}  {{ .Amount }}{{ if .Account }}  ({{ .Account }}){{ end }}{{ if .Pending }}  pending{{ end }}{{ end }}
+{{ end }}`)),
+	html: htmltemplate.Must(htmltemplate.New("digest").Parse(
+		`<h2>Your {{ .Frequency }} digest for {{ .Period }}</h2>
+<p>{{ .Count }} transaction{{ if ne .Count 1 }}s{{ end }}</p>
+<table cellpadding="4">
+<tr><th align="left">Currency</th><th align="right">In</th><th align="right">Out</th></tr>
+{{- range .Totals }}
+<tr><td>{{ .Currency }}</td><td align="right">{{ .In }}</td><td align="right">{{ .Out }}</td></tr>
+{{- end }}
+</table>
+{{- if .Largest }}
+<h3>Largest outflows</h3>
+<table cellpadding="4">
+{{- range .Largest }}
+<tr><td>{{ .Date }}</td><td>{{ .Payee }}{{ if .Pending }} <em>(pending)</em>{{ end }}</td><td>{{ .Account }}</td><td align="right">{{ .Amount }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+`)),
+}
@@ -0,0 +1,77 @@
+package receipt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// heifConvert is the converter shipped with libheif. There is no pure Go HEIC decoder, so
+// conversion is delegated to it rather than linking against libheif
+const heifConvert = "heif-convert"
+
+// ErrHEICUnsupported is returned when a HEIC image is uploaded to a server without libheif installed
+var ErrHEICUnsupported = errors.New("heic images are not supported, " + heifConvert + " is not installed")
+
+// heicBrands are the ftyp brands used by HEIC and HEIF images, including image sequences
+var heicBrands = []string{"heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1"}
+
+// DetectContentType extends http.DetectContentType with HEIC detection, which is the format
+// iPhones save photos in
+func DetectContentType(data []byte) string {
+
+	// ISO base media files start with the size of the ftyp box followed by the box type and major brand
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		brand := string(data[8:12])
+		for _, heicBrand := range heicBrands {
+			if brand == heicBrand {
+				return "image/heic"
+			}
+		}
+	}
+
+	return http.DetectContentType(data)
+
+}
+
+func convertHEIC(data []byte) ([]byte, error) {
+
+	path, err := exec.LookPath(heifConvert)
+	if err != nil {
+		return nil, ErrHEICUnsupported
+	}
+
+	dir, err := os.MkdirTemp("", "receipt-heic-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for heic conversion: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "image.heic")
+	dst := filepath.Join(dir, "image.jpg")
+
+	err = os.WriteFile(src, data, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write heic image: %w", err)
+	}
+
+	var stderr = new(bytes.Buffer)
+	cmd := exec.Command(path, "-q", "100", src, dst)
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert heic image: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	converted, err := os.ReadFile(dst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read converted heic image: %w", err)
+	}
+
+	return converted, nil
+
+}
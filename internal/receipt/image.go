@@ -0,0 +1,167 @@
+package receipt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	// ThumbnailSize is the length of the longest side of a thumbnail in pixels
+	ThumbnailSize = 320
+	// maxImagePixels guards against decompression bombs, 50 megapixels is well above
+	// what phone cameras produce
+	maxImagePixels = 50_000_000
+
+	jpegQuality          = 90
+	thumbnailJPEGQuality = 80
+)
+
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// IsImage reports whether contentType is one of the image formats accepted for receipts and attachments
+func IsImage(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp", "image/heic", "image/heif":
+		return true
+	}
+
+	return false
+}
+
+// Image is an uploaded image after it has been normalized
+type Image struct {
+	Data        []byte
+	ContentType string
+	// Thumbnail is a JPEG no larger than ThumbnailSize on either side
+	Thumbnail []byte
+}
+
+// ProcessImage normalizes an uploaded image so that it can be displayed by any browser. The EXIF
+// orientation of JPEGs is applied to the pixels and every image is re-encoded, which strips the
+// metadata, such as the location, that cameras embed. PNGs remain PNGs to keep them lossless,
+// every other format is converted to JPEG
+func ProcessImage(data []byte, contentType string) (*Image, error) {
+
+	if contentType == "image/heic" || contentType == "image/heif" {
+		converted, err := convertHEIC(data)
+		if err != nil {
+			return nil, err
+		}
+
+		// libheif applies the rotation stored in the container during the conversion
+		data, contentType = converted, "image/jpeg"
+	}
+
+	img, err := decodeImage(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	var out = new(bytes.Buffer)
+	switch contentType {
+	case "image/png":
+		err = png.Encode(out, img)
+	default:
+		contentType = "image/jpeg"
+		err = jpeg.Encode(out, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	thumbnail, err := Thumbnail(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Image{
+		Data:        out.Bytes(),
+		ContentType: contentType,
+		Thumbnail:   thumbnail,
+	}, nil
+
+}
+
+func decodeImage(data []byte, contentType string) (image.Image, error) {
+
+	var decodeConfig func(r *bytes.Reader) (image.Config, error)
+	var decode func(r *bytes.Reader) (image.Image, error)
+	switch contentType {
+	case "image/jpeg":
+		decodeConfig = func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) }
+		decode = func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) }
+	case "image/png":
+		decodeConfig = func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) }
+		decode = func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) }
+	case "image/webp":
+		decodeConfig = func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) }
+		decode = func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) }
+	default:
+		return nil, fmt.Errorf("%s is not a supported image format", contentType)
+	}
+
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if config.Width*config.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return img, nil
+
+}
+
+// Thumbnail scales img down so that its longest side is ThumbnailSize and encodes it as a JPEG.
+// Images that are already smaller are not scaled up. Transparent areas are filled with white
+func Thumbnail(img image.Image) ([]byte, error) {
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > ThumbnailSize || height > ThumbnailSize {
+		if width >= height {
+			width, height = ThumbnailSize, height*ThumbnailSize/width
+		} else {
+			width, height = width*ThumbnailSize/height, ThumbnailSize
+		}
+	}
+
+	// Very wide or tall images can round down to nothing
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var out = new(bytes.Buffer)
+	err := jpeg.Encode(out, dst, &jpeg.Options{Quality: thumbnailJPEGQuality})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	return out.Bytes(), nil
+
+}
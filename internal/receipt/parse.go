@@ -0,0 +1,127 @@
+package receipt
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger/internal"
+	"github.com/volatiletech/null"
+)
+
+// Details are the values pulled out of the text of a receipt. Any value that could not be
+// found is left null
+type Details struct {
+	Total        null.Float64
+	Date         null.Time
+	MerchantName null.String
+}
+
+var (
+	amountPattern = regexp.MustCompile(`\$?\s?(\d{1,3}(?:,\d{3})*|\d+)\.(\d{2})\b`)
+	totalPattern  = regexp.MustCompile(`(?i)\b(grand total|amount due|balance due|total due|amount paid|total)\b`)
+	subtotal      = regexp.MustCompile(`(?i)\bsub\s?-?total\b`)
+
+	isoDatePattern     = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	numericDatePattern = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4}|\d{2})\b`)
+	longDatePattern    = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2}),?\s+(\d{4})\b`)
+)
+
+// Parse pulls the total, date and merchant name out of the text of a receipt
+func Parse(text string) *Details {
+
+	details := new(Details)
+
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !details.MerchantName.Valid && strings.IndexFunc(line, isLetter) >= 0 {
+			details.MerchantName = null.StringFrom(internal.Truncate(line, 64))
+		}
+	}
+
+	// The last total on the receipt is the one that includes tax and tip
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if !totalPattern.MatchString(line) || subtotal.MatchString(line) {
+			continue
+		}
+
+		amount, ok := lastAmount(line)
+		if !ok && i+1 < len(lines) {
+			// Some layouts place the amount on the line following the label
+			amount, ok = lastAmount(lines[i+1])
+		}
+
+		if ok {
+			details.Total = null.Float64From(amount)
+			break
+		}
+	}
+
+	date, ok := findDate(text)
+	if ok {
+		details.Date = null.TimeFrom(date)
+	}
+
+	return details
+
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func lastAmount(line string) (float64, bool) {
+
+	matches := amountPattern.FindAllStringSubmatch(line, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+
+	match := matches[len(matches)-1]
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "")+"."+match[2], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return amount, true
+
+}
+
+func findDate(text string) (time.Time, bool) {
+
+	if m := isoDatePattern.FindStringSubmatch(text); m != nil {
+		date, err := time.Parse("2006-01-02", m[0])
+		if err == nil {
+			return date, true
+		}
+	}
+
+	if m := longDatePattern.FindStringSubmatch(text); m != nil {
+		date, err := time.Parse("Jan 2 2006", strings.Title(strings.ToLower(m[1]))+" "+m[2]+" "+m[3])
+		if err == nil {
+			return date, true
+		}
+	}
+
+	// Receipts from US merchants print dates month first
+	if m := numericDatePattern.FindStringSubmatch(text); m != nil {
+		layout := "1/2/2006"
+		if len(m[3]) == 2 {
+			layout = "1/2/06"
+		}
+		date, err := time.Parse(layout, m[1]+"/"+m[2]+"/"+m[3])
+		if err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+
+}
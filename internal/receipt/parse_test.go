@@ -0,0 +1,88 @@
+package receipt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/volatiletech/null"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		name string
+		text string
+		want Details
+	}{
+		{
+			name: "empty",
+			text: "",
+		},
+		{
+			name: "total after subtotal",
+			text: "Corner Cafe\n2021-06-14\nSubtotal 10.00\nTax 0.80\nTotal $10.80",
+			want: Details{
+				Total:        null.Float64From(10.80),
+				Date:         null.TimeFrom(time.Date(2021, 6, 14, 0, 0, 0, 0, time.UTC)),
+				MerchantName: null.StringFrom("Corner Cafe"),
+			},
+		},
+		{
+			name: "amount on line after label",
+			text: "ACME HARDWARE\nAmount Due\n1,204.99",
+			want: Details{
+				Total:        null.Float64From(1204.99),
+				MerchantName: null.StringFrom("ACME HARDWARE"),
+			},
+		},
+		{
+			name: "long date",
+			text: "Bookshop\nSep. 3, 2021\nTOTAL 18.00",
+			want: Details{
+				Total:        null.Float64From(18),
+				Date:         null.TimeFrom(time.Date(2021, 9, 3, 0, 0, 0, 0, time.UTC)),
+				MerchantName: null.StringFrom("Bookshop"),
+			},
+		},
+		{
+			name: "numeric date is month first",
+			text: "Diner\n07/04/21\nTotal 22.10",
+			want: Details{
+				Total:        null.Float64From(22.10),
+				Date:         null.TimeFrom(time.Date(2021, 7, 4, 0, 0, 0, 0, time.UTC)),
+				MerchantName: null.StringFrom("Diner"),
+			},
+		},
+		{
+			name: "long merchant is cut between characters",
+			text: strings.Repeat("a", 63) + "é café",
+			want: Details{
+				MerchantName: null.StringFrom(strings.Repeat("a", 63)),
+			},
+		},
+		{
+			name: "merchant skips lines without letters",
+			text: "12345\n\nFuel Stop\nSubtotal 30.00",
+			want: Details{
+				MerchantName: null.StringFrom("Fuel Stop"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.text)
+			if got.Total != tt.want.Total {
+				t.Errorf("Total = %v, want %v", got.Total, tt.want.Total)
+			}
+			if got.Date.Valid != tt.want.Date.Valid || !got.Date.Time.Equal(tt.want.Date.Time) {
+				t.Errorf("Date = %v, want %v", got.Date, tt.want.Date)
+			}
+			if got.MerchantName != tt.want.MerchantName {
+				t.Errorf("MerchantName = %v, want %v", got.MerchantName, tt.want.MerchantName)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,475 @@
+// Package receipt extracts structured details, such as the total and date, from uploaded receipts
+// and normalizes uploaded images so they can be previewed
+package receipt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNotPDF = errors.New("file is not a pdf")
+	// ErrNoText is returned for PDFs that do not contain a text layer, such as scanned receipts
+	ErrNoText = errors.New("pdf does not contain any text")
+	// ErrTooLarge is returned when the compressed streams of a PDF decode to more than maxDecodedSize
+	ErrTooLarge = errors.New("pdf content is too large")
+)
+
+// maxDecodedSize caps the decoded size of all of the streams of a document, a few kilobytes of
+// deflated zeros would otherwise expand to gigabytes
+const maxDecodedSize = 16 << 20
+
+// ExtractPDFText returns the text drawn by the content streams of a PDF. It understands
+// uncompressed and FlateDecode streams and the text showing operators, which covers the
+// receipts generated by point of sale and billing systems. Fonts with custom encodings
+// are not decoded, so text drawn with them may come back garbled or empty
+func ExtractPDFText(data []byte) (string, error) {
+
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", ErrNotPDF
+	}
+
+	streams, err := pdfStreams(data)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for _, stream := range streams {
+		extractContentText(stream, &out)
+	}
+
+	text := strings.TrimSpace(out.String())
+	if text == "" {
+		return "", ErrNoText
+	}
+
+	return text, nil
+
+}
+
+// pdfStreams returns the decoded contents of every stream in the document that could hold
+// page content. Images, fonts and streams using unsupported filters are skipped. ErrTooLarge
+// is returned once the decoded streams exceed maxDecodedSize
+func pdfStreams(data []byte) ([][]byte, error) {
+
+	var streams = make([][]byte, 0)
+	var offset, decodedSize int
+	for {
+		idx := bytes.Index(data[offset:], []byte("stream"))
+		if idx < 0 {
+			break
+		}
+
+		start := offset + idx
+		offset = start + len("stream")
+
+		// endstream also contains the keyword, make sure this is the start of a stream
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			continue
+		}
+
+		body := offset
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body < len(data) && data[body] == '\n' {
+			body++
+		}
+
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+
+		// The stream dictionary sits between the preceding obj keyword and the stream keyword
+		dictStart := bytes.LastIndex(data[:start], []byte("obj"))
+		if dictStart < 0 {
+			dictStart = 0
+		}
+		dict := data[dictStart:start]
+		content := bytes.TrimRight(data[body:body+end], "\r\n")
+		offset = body + end + len("endstream")
+
+		if bytes.Contains(dict, []byte("/Image")) || bytes.Contains(dict, []byte("/FontFile")) ||
+			bytes.Contains(dict, []byte("/Length1")) || bytes.Contains(dict, []byte("/XRef")) {
+			continue
+		}
+
+		if bytes.Contains(dict, []byte("/Filter")) {
+			if !bytes.Contains(dict, []byte("/FlateDecode")) {
+				continue
+			}
+
+			reader, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+
+			decoded, err := io.ReadAll(io.LimitReader(reader, int64(maxDecodedSize-decodedSize+1)))
+			if len(decoded) > maxDecodedSize-decodedSize {
+				return nil, ErrTooLarge
+			}
+			if err != nil && len(decoded) == 0 {
+				continue
+			}
+			content = decoded
+			decodedSize += len(decoded)
+		}
+
+		streams = append(streams, content)
+	}
+
+	return streams, nil
+
+}
+
+// extractContentText walks the operators of a content stream and writes the strings passed
+// to the text showing operators to out, starting a new line whenever the text moves down
+func extractContentText(content []byte, out *strings.Builder) {
+
+	var operands = make([][]byte, 0)
+	var inText bool
+	var lineHasText bool
+
+	newline := func() {
+		if lineHasText {
+			out.WriteByte('\n')
+			lineHasText = false
+		}
+	}
+
+	write := func(s []byte) {
+		if len(s) == 0 {
+			return
+		}
+		out.WriteString(latin1(s))
+		lineHasText = true
+	}
+
+	lex := &lexer{data: content}
+	for {
+		tok, kind := lex.next()
+		if kind == tokenEOF {
+			break
+		}
+
+		switch kind {
+		case tokenString:
+			operands = append(operands, tok)
+			continue
+		case tokenNumber:
+			operands = append(operands, tok)
+			continue
+		case tokenArrayStart, tokenArrayEnd, tokenName, tokenDict:
+			continue
+		}
+
+		switch string(tok) {
+		case "BT":
+			inText = true
+		case "ET":
+			inText = false
+			newline()
+		case "T*", "'", "\"":
+			newline()
+			if string(tok) != "T*" {
+				for _, operand := range operands {
+					if isString(operand) {
+						write(operand[1:])
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				ty, err := strconv.ParseFloat(string(operands[len(operands)-1]), 64)
+				tx, _ := strconv.ParseFloat(string(operands[len(operands)-2]), 64)
+				if err == nil && ty != 0 {
+					newline()
+				} else if tx > 0 && lineHasText {
+					out.WriteByte(' ')
+				}
+			}
+		case "Tm":
+			newline()
+		case "Tj":
+			if inText && len(operands) > 0 && isString(operands[len(operands)-1]) {
+				write(operands[len(operands)-1][1:])
+			}
+		case "TJ":
+			if !inText {
+				break
+			}
+			for _, operand := range operands {
+				if isString(operand) {
+					write(operand[1:])
+					continue
+				}
+
+				// Large negative kerning adjustments are how words are spaced apart
+				adjustment, err := strconv.ParseFloat(string(operand), 64)
+				if err == nil && adjustment < -200 && lineHasText {
+					out.WriteByte(' ')
+				}
+			}
+		case "ID":
+			lex.skipInlineImage()
+		}
+
+		operands = operands[:0]
+	}
+
+	newline()
+
+}
+
+// strings are tagged with a leading 0 byte so they can be told apart from numbers on the operand stack
+func isString(operand []byte) bool {
+	return len(operand) > 0 && operand[0] == 0
+}
+
+// latin1 converts PDFDocEncoding and WinAnsi text, both of which match latin1 for printable
+// characters, to UTF-8. Two byte encodings with a zero high byte are collapsed to a single byte
+func latin1(s []byte) string {
+
+	if len(s)%2 == 0 && len(s) > 0 {
+		wide := true
+		for i := 0; i < len(s); i += 2 {
+			if s[i] != 0 {
+				wide = false
+				break
+			}
+		}
+		if wide {
+			narrow := make([]byte, 0, len(s)/2)
+			for i := 1; i < len(s); i += 2 {
+				narrow = append(narrow, s[i])
+			}
+			s = narrow
+		}
+	}
+
+	var runes = make([]rune, 0, len(s))
+	for _, b := range s {
+		if b < 0x20 && b != '\t' {
+			continue
+		}
+		runes = append(runes, rune(b))
+	}
+
+	return string(runes)
+
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenName
+	tokenOperator
+	tokenArrayStart
+	tokenArrayEnd
+	tokenDict
+)
+
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\r' || b == '\t' || b == '\f' || b == 0
+}
+
+func isDelimiter(b byte) bool {
+	return strings.IndexByte("()<>[]{}/%", b) >= 0
+}
+
+func (l *lexer) next() ([]byte, tokenKind) {
+
+	for l.pos < len(l.data) {
+		b := l.data[l.pos]
+		if isWhitespace(b) {
+			l.pos++
+			continue
+		}
+
+		if b == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+
+		break
+	}
+
+	if l.pos >= len(l.data) {
+		return nil, tokenEOF
+	}
+
+	b := l.data[l.pos]
+	switch {
+	case b == '(':
+		return l.literalString(), tokenString
+	case b == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return nil, tokenDict
+	case b == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return nil, tokenDict
+	case b == '<':
+		return l.hexString(), tokenString
+	case b == '[':
+		l.pos++
+		return nil, tokenArrayStart
+	case b == ']':
+		l.pos++
+		return nil, tokenArrayEnd
+	case b == '/':
+		l.pos++
+		return l.regular(), tokenName
+	case b == '{' || b == '}' || b == ')' || b == '>':
+		l.pos++
+		return nil, tokenDict
+	}
+
+	tok := l.regular()
+	if len(tok) > 0 && (tok[0] == '-' || tok[0] == '+' || tok[0] == '.' || (tok[0] >= '0' && tok[0] <= '9')) {
+		return tok, tokenNumber
+	}
+
+	return tok, tokenOperator
+
+}
+
+func (l *lexer) regular() []byte {
+	start := l.pos
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+
+	// A lone delimiter that was not handled above would otherwise stall the lexer
+	if l.pos == start {
+		l.pos++
+	}
+
+	return l.data[start:l.pos]
+}
+
+func (l *lexer) literalString() []byte {
+
+	var out = []byte{0}
+	var depth int
+	l.pos++
+
+	for l.pos < len(l.data) {
+		b := l.data[l.pos]
+		l.pos++
+
+		switch b {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				b = '\n'
+			case 'r':
+				b = '\r'
+			case 't':
+				b = '\t'
+			case 'b':
+				b = '\b'
+			case 'f':
+				b = '\f'
+			case '\r', '\n':
+				// line continuation
+				if e == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					octal := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						octal = octal*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					b = byte(octal)
+				} else {
+					b = e
+				}
+			}
+		}
+
+		out = append(out, b)
+	}
+
+	return out
+
+}
+
+func (l *lexer) hexString() []byte {
+
+	var out = []byte{0}
+	var digits = make([]byte, 0, 2)
+	l.pos++
+
+	for l.pos < len(l.data) {
+		b := l.data[l.pos]
+		l.pos++
+
+		if b == '>' {
+			break
+		}
+
+		if isWhitespace(b) {
+			continue
+		}
+
+		digits = append(digits, b)
+		if len(digits) == 2 {
+			v, err := strconv.ParseUint(string(digits), 16, 8)
+			if err == nil {
+				out = append(out, byte(v))
+			}
+			digits = digits[:0]
+		}
+	}
+
+	if len(digits) == 1 {
+		v, err := strconv.ParseUint(string(digits)+"0", 16, 8)
+		if err == nil {
+			out = append(out, byte(v))
+		}
+	}
+
+	return out
+
+}
+
+// skipInlineImage advances past the binary data of an inline image, which ends with EI
+func (l *lexer) skipInlineImage() {
+	end := bytes.Index(l.data[l.pos:], []byte("EI"))
+	if end < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += end + 2
+}
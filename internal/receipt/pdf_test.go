@@ -0,0 +1,102 @@
+package receipt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// pdf wraps content streams in just enough of a document for ExtractPDFText, compressing the
+// stream when a FlateDecode filter is given
+func pdf(filter string, streams ...string) []byte {
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, stream := range streams {
+		content := []byte(stream)
+		if filter == "/FlateDecode" {
+			var compressed bytes.Buffer
+			w := zlib.NewWriter(&compressed)
+			_, _ = w.Write(content)
+			_ = w.Close()
+			content = compressed.Bytes()
+		}
+
+		dict := fmt.Sprintf("/Length %d", len(content))
+		if filter != "" {
+			dict += " /Filter " + filter
+		}
+
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s >>\nstream\n%s\nendstream\nendobj\n", i+1, dict, content)
+	}
+	buf.WriteString("%%EOF\n")
+
+	return buf.Bytes()
+
+}
+
+func TestExtractPDFText(t *testing.T) {
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		err  error
+	}{
+		{
+			name: "not a pdf",
+			data: []byte("GIF89a"),
+			err:  ErrNotPDF,
+		},
+		{
+			name: "uncompressed Tj",
+			data: pdf("", "BT /F1 12 Tf 72 720 Td (Corner Cafe) Tj ET"),
+			want: "Corner Cafe",
+		},
+		{
+			name: "lines split by Td",
+			data: pdf("", "BT (Corner Cafe) Tj 0 -14 Td (Total 12.50) Tj ET"),
+			want: "Corner Cafe\nTotal 12.50",
+		},
+		{
+			name: "TJ kerning spaces words",
+			data: pdf("", "BT [(Grand) -250 (Total)] TJ ET"),
+			want: "Grand Total",
+		},
+		{
+			name: "flate compressed stream",
+			data: pdf("/FlateDecode", "BT (Amount Due 4.20) Tj ET"),
+			want: "Amount Due 4.20",
+		},
+		{
+			name: "unsupported filter skipped",
+			data: pdf("/DCTDecode", "BT (hidden) Tj ET"),
+			err:  ErrNoText,
+		},
+		{
+			name: "text outside BT ignored",
+			data: pdf("", "(hidden) Tj"),
+			err:  ErrNoText,
+		},
+		{
+			name: "decompression bomb",
+			data: pdf("/FlateDecode", string(make([]byte, maxDecodedSize+1))),
+			err:  ErrTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractPDFText(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,93 @@
+package recurring
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+)
+
+// calendarDays is how far ahead the calendar feed lists the transactions of each series
+const calendarDays = 180
+
+// calendarLineLength is the longest a line of an iCalendar file may be, in octets, before it has to
+// be folded onto the next line
+const calendarLineLength = 75
+
+var calendarEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\n", `\n`)
+
+// Calendar renders the upcoming bills and paydays of the user as an iCalendar (RFC 5545) feed with
+// an all day event for each transaction expected over the next calendarDays
+func (s *service) Calendar(ctx context.Context, user *ledger.User) ([]byte, error) {
+
+	series, err := s.RecurringSeriesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[recurring.Calendar] failed to fetch recurring series")
+	}
+
+	var buf = new(bytes.Buffer)
+	writeCalendarLine(buf, "BEGIN:VCALENDAR")
+	writeCalendarLine(buf, "VERSION:2.0")
+	writeCalendarLine(buf, "PRODID:-//Ledger//Upcoming Bills//EN")
+	writeCalendarLine(buf, "CALSCALE:GREGORIAN")
+	writeCalendarLine(buf, "METHOD:PUBLISH")
+	writeCalendarLine(buf, "X-WR-CALNAME:Ledger Bills")
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	from := ledger.IntervalDay.Start(time.Now())
+	to := from.AddDate(0, 0, calendarDays)
+	for _, series := range series {
+
+		label := "Bill"
+		if series.Kind == ledger.RecurringKindIncome {
+			label = "Payday"
+		}
+		summary := fmt.Sprintf("%s: %s %.2f %s", label, series.Name, series.Amount, series.CurrencyCode)
+
+		for _, date := range series.Occurrences(from, to) {
+			writeCalendarLine(buf, "BEGIN:VEVENT")
+			writeCalendarLine(buf, fmt.Sprintf("UID:%s-%s@ledger", series.SeriesID, date.Format("20060102")))
+			writeCalendarLine(buf, "DTSTAMP:"+stamp)
+			writeCalendarLine(buf, "DTSTART;VALUE=DATE:"+date.Format("20060102"))
+			writeCalendarLine(buf, "DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format("20060102"))
+			writeCalendarLine(buf, "SUMMARY:"+calendarEscaper.Replace(summary))
+			writeCalendarLine(buf, "TRANSP:TRANSPARENT")
+			writeCalendarLine(buf, "END:VEVENT")
+		}
+	}
+
+	writeCalendarLine(buf, "END:VCALENDAR")
+
+	return buf.Bytes(), nil
+
+}
+
+// writeCalendarLine writes a content line terminated by CRLF, folding it onto continuation lines
+// that start with a space whenever it is longer than calendarLineLength. Lines are only folded
+// between characters so that multi-byte characters are never split
+func writeCalendarLine(buf *bytes.Buffer, line string) {
+
+	limit := calendarLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+
+		// Continuation lines lose an octet to the leading space
+		limit = calendarLineLength - 1
+	}
+
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+
+}
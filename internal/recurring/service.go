@@ -0,0 +1,515 @@
+// Package recurring provides service access to the recurring charges, such as subscriptions,
+// detected in a users transactions
+package recurring
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/event"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+type Service interface {
+	DetectRecurringSeries(ctx context.Context, user *ledger.User) ([]*ledger.RecurringSeries, error)
+	CreateRecurringSeries(ctx context.Context, series *ledger.RecurringSeries) (*ledger.RecurringSeries, error)
+	UpdateRecurringSeries(ctx context.Context, seriesID string, series *ledger.RecurringSeries) (*ledger.RecurringSeries, error)
+	Upcoming(ctx context.Context, user *ledger.User, days int) ([]*ledger.ProjectedBalance, error)
+	Calendar(ctx context.Context, user *ledger.User) ([]byte, error)
+	ledger.RecurringRepository
+}
+
+type service struct {
+	event        event.Service
+	accounts     ledger.AccountRepository
+	merchants    ledger.MerchantRepository
+	transactions ledger.TransactionRepository
+
+	ledger.RecurringRepository
+}
+
+func New(event event.Service, accounts ledger.AccountRepository, merchants ledger.MerchantRepository, transactions ledger.TransactionRepository, recurring ledger.RecurringRepository) Service {
+	return &service{
+		event:               event,
+		accounts:            accounts,
+		merchants:           merchants,
+		transactions:        transactions,
+		RecurringRepository: recurring,
+	}
+}
+
+// lookbackMonths is how much history is mined for series, long enough to see an annual charge twice
+const lookbackMonths = 27
+
+// amountTolerance is how much the amount of a charge may differ from the one before it, as a share
+// of the earlier amount, and still be considered the same price
+const amountTolerance = 0.2
+
+// maxMissed is how many expected charges a series can miss before it is treated as cancelled
+const maxMissed = 2
+
+type schedule struct {
+	frequency ledger.RecurringFrequency
+	// minGap and maxGap bound the number of days between consecutive charges
+	minGap, maxGap int
+	// minCharges is the fewest charges that are needed to recognise the schedule
+	minCharges int
+	// grace is the number of days a charge can be late before it is reported missing
+	grace int
+}
+
+var schedules = []schedule{
+	{frequency: ledger.RecurringFrequencyWeekly, minGap: 6, maxGap: 8, minCharges: 4, grace: 3},
+	{frequency: ledger.RecurringFrequencyMonthly, minGap: 27, maxGap: 34, minCharges: 3, grace: 5},
+	{frequency: ledger.RecurringFrequencyAnnual, minGap: 355, maxGap: 375, minCharges: 2, grace: 14},
+}
+
+// DetectRecurringSeries mines the transaction history of the user for charges at the same merchant that
+// are made weekly, monthly or annually for a consistent amount and stores them as recurring series. A
+// merchant has more than one series when it charges different amounts on schedules of their own.
+// Series that are detected again keep their id, series that are no longer detected are removed. Series
+// created by the user are left alone, other than moving their next date on once it has passed
+func (s *service) DetectRecurringSeries(ctx context.Context, user *ledger.User) ([]*ledger.RecurringSeries, error) {
+
+	now := time.Now()
+	transactions, err := s.transactions.RecurringCandidates(ctx, user.ID, now.AddDate(0, -lookbackMonths, 0))
+	if err != nil {
+		return nil, errors.Wrap(err, "[recurring.DetectRecurringSeries] failed to fetch candidate transactions")
+	}
+
+	existing, err := s.RecurringSeriesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[recurring.DetectRecurringSeries] failed to fetch existing series")
+	}
+
+	today := ledger.IntervalDay.Start(now)
+	var existingByKey = make(map[string][]*ledger.RecurringSeries, len(existing))
+	for _, series := range existing {
+		if series.Source == ledger.RecurringSourceDetected {
+			key := series.MerchantID.String + ":" + series.CurrencyCode
+			existingByKey[key] = append(existingByKey[key], series)
+			continue
+		}
+
+		if !series.NextDate.Before(today) {
+			continue
+		}
+
+		for n := 1; series.NextDate.Before(today); n++ {
+			series.NextDate = series.Frequency.Add(series.FirstDate, n)
+		}
+
+		err = s.SaveRecurringSeries(ctx, series)
+		if err != nil {
+			return nil, errors.Wrap(err, "[recurring.DetectRecurringSeries] failed to move series on to its next date")
+		}
+	}
+
+	// Candidates are ordered by merchant and date, so each group is already in date order
+	var keys = make([]string, 0)
+	var charges = make(map[string][]*ledger.Transaction)
+	for _, transaction := range transactions {
+		key := transaction.MerchantID + ":" + transaction.CurrencyCode()
+		if _, ok := charges[key]; !ok {
+			keys = append(keys, key)
+		}
+		charges[key] = append(charges[key], transaction)
+	}
+
+	for _, key := range keys {
+		for _, series := range detectSeries(charges[key], now) {
+			series.UserID = user.ID
+			merchant, err := s.merchants.Merchant(ctx, series.MerchantID.String)
+			if err == nil {
+				series.Name = merchant.Name
+			}
+
+			series.SeriesID = uuid.Must(uuid.NewV4()).String()
+			previous := matchExisting(existingByKey[key], series)
+			detected := previous != nil
+			if detected {
+				series.SeriesID = previous.SeriesID
+				existingByKey[key] = removeSeries(existingByKey[key], previous)
+			}
+
+			err = s.SaveRecurringSeries(ctx, series)
+			if err != nil {
+				return nil, errors.Wrap(err, "[recurring.DetectRecurringSeries] failed to save series")
+			}
+
+			if !detected {
+				saved, err := s.RecurringSeries(ctx, user.ID, series.SeriesID)
+				if err != nil {
+					return nil, errors.Wrap(err, "[recurring.DetectRecurringSeries] failed to fetch detected series")
+				}
+
+				s.event.Emit(ctx, user.ID, ledger.EventSubscriptionDetected, saved)
+			}
+		}
+	}
+
+	for _, stale := range existingByKey {
+		for _, series := range stale {
+			err = s.DeleteRecurringSeries(ctx, user.ID, series.SeriesID)
+			if err != nil {
+				return nil, errors.Wrap(err, "[recurring.DetectRecurringSeries] failed to delete stale series")
+			}
+		}
+	}
+
+	return s.RecurringSeriesByUserID(ctx, user.ID)
+
+}
+
+// CreateRecurringSeries stores a series defined by the user, such as a payday, that cannot be detected
+// from their transactions. The currency defaults to the currency of the account the series is paid from or into
+func (s *service) CreateRecurringSeries(ctx context.Context, series *ledger.RecurringSeries) (*ledger.RecurringSeries, error) {
+
+	series.SeriesID = uuid.Must(uuid.NewV4()).String()
+	series.Source = ledger.RecurringSourceUser
+	series.Status = ledger.RecurringStatusActive
+	series.FirstDate = series.NextDate
+
+	err := s.validateRecurringSeries(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.SaveRecurringSeries(ctx, series)
+	if err != nil {
+		return nil, errors.Wrap(err, "[recurring.CreateRecurringSeries] failed to save series")
+	}
+
+	return s.RecurringSeries(ctx, series.UserID, series.SeriesID)
+
+}
+
+// UpdateRecurringSeries updates a series defined by the user. Detected series are replaced every time
+// transactions are mined, so they cannot be updated
+func (s *service) UpdateRecurringSeries(ctx context.Context, seriesID string, series *ledger.RecurringSeries) (*ledger.RecurringSeries, error) {
+
+	if series.Source != ledger.RecurringSourceUser {
+		return nil, errors.New("detected series cannot be updated")
+	}
+
+	series.SeriesID = seriesID
+	series.FirstDate = series.NextDate
+
+	err := s.validateRecurringSeries(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.SaveRecurringSeries(ctx, series)
+	if err != nil {
+		return nil, errors.Wrap(err, "[recurring.UpdateRecurringSeries] failed to save series")
+	}
+
+	return s.RecurringSeries(ctx, series.UserID, seriesID)
+
+}
+
+func (s *service) validateRecurringSeries(ctx context.Context, series *ledger.RecurringSeries) error {
+
+	series.Name = strings.TrimSpace(series.Name)
+	if series.Name == "" {
+		return errors.New("a name is required")
+	}
+
+	if !series.Kind.Valid() {
+		return errors.Errorf("%s is not a valid kind, valid kinds are bill and income", series.Kind)
+	}
+
+	if !series.Frequency.Valid() {
+		return errors.Errorf("%s is not a valid frequency, valid frequencies are weekly, monthly and annual", series.Frequency)
+	}
+
+	if series.Amount <= 0 {
+		return errors.New("amount must be greater than zero")
+	}
+
+	if series.NextDate.IsZero() {
+		return errors.New("the date of the next transaction is required")
+	}
+
+	account, err := s.accounts.Account(ctx, series.ItemID.String, series.AccountID.String)
+	if err != nil {
+		return errors.Wrap(err, "[recurring.validateRecurringSeries] failed to fetch account")
+	}
+
+	if series.CurrencyCode == "" && account.Balance != nil {
+		series.CurrencyCode = account.Balance.CurrencyCode()
+	}
+
+	return nil
+
+}
+
+// matchExisting returns the previously detected series at the same merchant that series is a
+// continuation of, the one with the closest amount before any price change, or nil when there is none
+func matchExisting(existing []*ledger.RecurringSeries, series *ledger.RecurringSeries) *ledger.RecurringSeries {
+
+	var amounts = []float64{series.Amount}
+	if series.PreviousAmount.Valid {
+		amounts = append(amounts, series.PreviousAmount.Float64)
+	}
+
+	var match *ledger.RecurringSeries
+	var matchDiff = math.Inf(1)
+	for _, candidate := range existing {
+		for _, amount := range amounts {
+			diff := math.Abs(candidate.Amount - amount)
+			if diff <= candidate.Amount*amountTolerance+0.01 && diff < matchDiff {
+				match, matchDiff = candidate, diff
+			}
+		}
+	}
+
+	return match
+
+}
+
+func removeSeries(list []*ledger.RecurringSeries, series *ledger.RecurringSeries) []*ledger.RecurringSeries {
+
+	var out = make([]*ledger.RecurringSeries, 0, len(list))
+	for _, candidate := range list {
+		if candidate != series {
+			out = append(out, candidate)
+		}
+	}
+
+	return out
+
+}
+
+// detectSeries returns the series formed by the charges at a single merchant in a single currency,
+// which must be ordered oldest first. Charges are clustered by amount first, so that two subscriptions
+// at the same merchant are told apart and a one-off purchase does not break up a series
+func detectSeries(charges []*ledger.Transaction, now time.Time) []*ledger.RecurringSeries {
+
+	var detected = make([]*ledger.RecurringSeries, 0)
+	for _, cluster := range clusterByAmount(charges) {
+		series := detectClusterSeries(cluster, now)
+		if series != nil {
+			detected = append(detected, series)
+		}
+	}
+
+	return detected
+
+}
+
+// clusterByAmount splits charges, ordered oldest first, into clusters of charges for about the same
+// amount. Each charge joins the cluster whose latest amount is closest to its own, within
+// amountTolerance. A cluster that ends right where another begins, a gap of one period later, is a
+// price change rather than a second series and the two are joined back together
+func clusterByAmount(charges []*ledger.Transaction) [][]*ledger.Transaction {
+
+	var clusters = make([][]*ledger.Transaction, 0)
+	for _, charge := range charges {
+		best, bestDiff := -1, math.Inf(1)
+		for i, cluster := range clusters {
+			latest := -cluster[len(cluster)-1].Amount
+			diff := math.Abs(-charge.Amount - latest)
+			if diff <= latest*amountTolerance+0.01 && diff < bestDiff {
+				best, bestDiff = i, diff
+			}
+		}
+
+		if best < 0 {
+			clusters = append(clusters, []*ledger.Transaction{charge})
+			continue
+		}
+
+		clusters[best] = append(clusters[best], charge)
+	}
+
+	var merged = make([][]*ledger.Transaction, 0, len(clusters))
+	var joined = make([]bool, len(clusters))
+	for i := range clusters {
+		if joined[i] {
+			continue
+		}
+
+		cluster := clusters[i]
+		schedule, ok := scheduleForGap(medianGap(cluster))
+		if ok {
+			for j := i + 1; j < len(clusters); j++ {
+				next := clusters[j]
+				if joined[j] || !next[0].Date.After(cluster[len(cluster)-1].Date) {
+					continue
+				}
+
+				gap := daysBetween(cluster[len(cluster)-1].Date, next[0].Date)
+				if gap < schedule.minGap || gap > schedule.maxGap {
+					continue
+				}
+
+				if nextSchedule, ok := scheduleForGap(medianGap(next)); len(next) > 1 && (!ok || nextSchedule.frequency != schedule.frequency) {
+					continue
+				}
+
+				cluster = append(cluster[:len(cluster):len(cluster)], next...)
+				joined[j] = true
+				break
+			}
+		}
+
+		merged = append(merged, cluster)
+	}
+
+	return merged
+
+}
+
+// detectClusterSeries returns the series formed by the most recent charges of a cluster, which must
+// be ordered oldest first, or nil when they do not keep to a schedule or the series has since been
+// cancelled. The schedule is read from the median of the gaps between the charges
+func detectClusterSeries(charges []*ledger.Transaction, now time.Time) *ledger.RecurringSeries {
+
+	charges = oncePerDay(charges)
+	if len(charges) < 2 {
+		return nil
+	}
+
+	schedule, ok := scheduleForGap(medianGap(charges))
+	if !ok {
+		return nil
+	}
+
+	// Walk back from the most recent charge for as long as the charges keep to the schedule. Charges
+	// that come too soon after the one before them are extra purchases of the same amount and are skipped
+	run := []*ledger.Transaction{charges[len(charges)-1]}
+	for i := len(charges) - 2; i >= 0; i-- {
+		gap := daysBetween(charges[i].Date, run[0].Date)
+		if gap < schedule.minGap {
+			continue
+		}
+		if gap > schedule.maxGap {
+			break
+		}
+		run = append([]*ledger.Transaction{charges[i]}, run...)
+	}
+
+	if len(run) < schedule.minCharges {
+		return nil
+	}
+
+	// Amounts may drift, such as a utility bill, or change once, such as a price increase, but
+	// a run with more than one large change is not a consistent amount
+	var changedAt, jumps int
+	for i := 1; i < len(run); i++ {
+		previous, current := -run[i-1].Amount, -run[i].Amount
+		if math.Abs(current-previous) < 0.01 {
+			continue
+		}
+
+		if math.Abs(current-previous) > previous*amountTolerance {
+			jumps++
+			if jumps > 1 {
+				return nil
+			}
+		}
+
+		changedAt = i
+	}
+
+	latest := run[len(run)-1]
+	series := &ledger.RecurringSeries{
+		Name:             latest.Name,
+		Kind:             ledger.RecurringKindBill,
+		Source:           ledger.RecurringSourceDetected,
+		ItemID:           null.StringFrom(latest.ItemID),
+		AccountID:        null.StringFrom(latest.AccountID),
+		MerchantID:       null.StringFrom(latest.MerchantID),
+		CurrencyCode:     latest.CurrencyCode(),
+		Frequency:        schedule.frequency,
+		Status:           ledger.RecurringStatusActive,
+		Amount:           math.Round(-latest.Amount*100) / 100,
+		TransactionCount: int64(len(run)),
+		FirstDate:        run[0].Date,
+		LastDate:         null.TimeFrom(latest.Date),
+		NextDate:         schedule.frequency.Next(latest.Date),
+	}
+
+	if changedAt > 0 {
+		series.PreviousAmount = null.Float64From(math.Round(-run[changedAt-1].Amount*100) / 100)
+		series.PriceChangedAt = null.TimeFrom(run[changedAt].Date)
+	}
+
+	if now.After(series.NextDate.AddDate(0, 0, schedule.grace)) {
+		expected := series.NextDate
+		for i := 0; i < maxMissed; i++ {
+			expected = schedule.frequency.Next(expected)
+		}
+
+		if now.After(expected.AddDate(0, 0, schedule.grace)) {
+			return nil
+		}
+
+		series.Status = ledger.RecurringStatusMissing
+	}
+
+	return series
+
+}
+
+// oncePerDay drops all but the last of the charges, ordered oldest first, made on the same day
+func oncePerDay(charges []*ledger.Transaction) []*ledger.Transaction {
+
+	var out = make([]*ledger.Transaction, 0, len(charges))
+	for _, charge := range charges {
+		if len(out) > 0 && daysBetween(out[len(out)-1].Date, charge.Date) == 0 {
+			out[len(out)-1] = charge
+			continue
+		}
+		out = append(out, charge)
+	}
+
+	return out
+
+}
+
+// medianGap returns the median number of days between consecutive charges, ordered oldest first,
+// ignoring charges made on the same day. It is zero when there is no gap to measure
+func medianGap(charges []*ledger.Transaction) int {
+
+	var gaps = make([]int, 0, len(charges))
+	for i := 1; i < len(charges); i++ {
+		if gap := daysBetween(charges[i-1].Date, charges[i].Date); gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+
+	if len(gaps) == 0 {
+		return 0
+	}
+
+	sort.Ints(gaps)
+	if len(gaps)%2 == 0 {
+		return (gaps[len(gaps)/2-1] + gaps[len(gaps)/2]) / 2
+	}
+
+	return gaps[len(gaps)/2]
+
+}
+
+func scheduleForGap(gap int) (schedule, bool) {
+	for _, schedule := range schedules {
+		if gap >= schedule.minGap && gap <= schedule.maxGap {
+			return schedule, true
+		}
+	}
+
+	return schedule{}, false
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
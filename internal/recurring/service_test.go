@@ -0,0 +1,154 @@
+package recurring
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ddouglas/ledger"
+)
+
+type charge struct {
+	date   string
+	amount float64
+}
+
+func charges(in ...charge) []*ledger.Transaction {
+
+	var out = make([]*ledger.Transaction, 0, len(in))
+	for _, c := range in {
+		date, err := time.Parse("2006-01-02", c.date)
+		if err != nil {
+			panic(err)
+		}
+
+		out = append(out, &ledger.Transaction{
+			MerchantID: "merchant",
+			Date:       date,
+			Amount:     -c.amount,
+		})
+	}
+
+	return out
+
+}
+
+// monthly returns n charges of amount on the 5th of each month, starting in January 2021
+func monthly(n int, amount float64) []charge {
+
+	var out = make([]charge, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, charge{time.Date(2021, time.Month(1+i), 5, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), amount})
+	}
+
+	return out
+
+}
+
+func merge(groups ...[]charge) []charge {
+
+	var out = make([]charge, 0)
+	for _, group := range groups {
+		out = append(out, group...)
+	}
+
+	// Candidates are returned in date order
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].date < out[j].date
+	})
+
+	return out
+
+}
+
+func TestDetectSeries(t *testing.T) {
+
+	now := time.Date(2021, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		frequency ledger.RecurringFrequency
+		amount    float64
+		count     int64
+	}
+
+	tests := []struct {
+		name    string
+		charges []charge
+		want    []want
+	}{
+		{
+			name:    "too few charges",
+			charges: monthly(2, 9.99),
+		},
+		{
+			name:    "monthly",
+			charges: monthly(6, 9.99),
+			want:    []want{{ledger.RecurringFrequencyMonthly, 9.99, 6}},
+		},
+		{
+			name: "weekly",
+			charges: []charge{
+				{"2021-05-06", 5}, {"2021-05-13", 5}, {"2021-05-20", 5}, {"2021-05-27", 5}, {"2021-06-03", 5},
+			},
+			want: []want{{ledger.RecurringFrequencyWeekly, 5, 5}},
+		},
+		{
+			name:    "one-off purchase of a different amount",
+			charges: merge(monthly(6, 9.99), []charge{{"2021-03-18", 54.20}}),
+			want:    []want{{ledger.RecurringFrequencyMonthly, 9.99, 6}},
+		},
+		{
+			name:    "one-off purchase of the same amount",
+			charges: merge(monthly(6, 9.99), []charge{{"2021-06-01", 9.99}}),
+			want:    []want{{ledger.RecurringFrequencyMonthly, 9.99, 6}},
+		},
+		{
+			name:    "two subscriptions at the same merchant",
+			charges: merge(monthly(6, 9.99), monthly(6, 2.99)),
+			want: []want{
+				{ledger.RecurringFrequencyMonthly, 9.99, 6},
+				{ledger.RecurringFrequencyMonthly, 2.99, 6},
+			},
+		},
+		{
+			name:    "two charges on the same day",
+			charges: merge(monthly(6, 9.99), []charge{{"2021-04-05", 9.99}}),
+			want:    []want{{ledger.RecurringFrequencyMonthly, 9.99, 6}},
+		},
+		{
+			name:    "price increase",
+			charges: merge(monthly(3, 9.99), monthly(6, 13.99)[3:]),
+			want:    []want{{ledger.RecurringFrequencyMonthly, 13.99, 6}},
+		},
+		{
+			name:    "cancelled",
+			charges: []charge{{"2020-11-05", 9.99}, {"2020-12-05", 9.99}, {"2021-01-05", 9.99}},
+		},
+		{
+			name:    "irregular",
+			charges: []charge{{"2021-01-05", 20}, {"2021-02-19", 20}, {"2021-03-01", 20}, {"2021-05-27", 20}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectSeries(charges(tt.charges...), now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("detected %d series, want %d", len(got), len(tt.want))
+			}
+
+			for i, series := range got {
+				if series.Frequency != tt.want[i].frequency {
+					t.Errorf("series %d frequency = %s, want %s", i, series.Frequency, tt.want[i].frequency)
+				}
+				if series.Amount != tt.want[i].amount {
+					t.Errorf("series %d amount = %v, want %v", i, series.Amount, tt.want[i].amount)
+				}
+				if series.TransactionCount != tt.want[i].count {
+					t.Errorf("series %d transaction count = %d, want %d", i, series.TransactionCount, tt.want[i].count)
+				}
+			}
+		})
+	}
+
+}
@@ -0,0 +1,95 @@
+package recurring
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+)
+
+// maxUpcomingDays keeps a projection to roughly a year of weekly transactions per series
+const maxUpcomingDays = 366
+
+// Upcoming projects the balance of each of the users accounts forward from its current balance
+// over the next days, applying every transaction the recurring series paid from or into the
+// account are expected to make. Series without an account are left out
+func (s *service) Upcoming(ctx context.Context, user *ledger.User, days int) ([]*ledger.ProjectedBalance, error) {
+
+	if days < 1 || days > maxUpcomingDays {
+		return nil, errors.Errorf("[recurring.Upcoming] days must be between 1 and %d", maxUpcomingDays)
+	}
+
+	accounts, err := s.accounts.AccountsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[recurring.Upcoming] failed to fetch accounts")
+	}
+
+	series, err := s.RecurringSeriesByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[recurring.Upcoming] failed to fetch recurring series")
+	}
+
+	var projections = make([]*ledger.ProjectedBalance, 0, len(accounts))
+	var projectionsByAccount = make(map[string]*ledger.ProjectedBalance, len(accounts))
+	var liabilities = make(map[*ledger.ProjectedBalance]bool)
+	for _, account := range accounts {
+		if account.Balance == nil {
+			continue
+		}
+
+		projection := &ledger.ProjectedBalance{
+			ItemID:       account.ItemID,
+			AccountID:    account.AccountID,
+			Currency:     account.Balance.CurrencyCode(),
+			Current:      account.Balance.Current,
+			Transactions: make([]*ledger.ProjectedTransaction, 0),
+		}
+		projections = append(projections, projection)
+		projectionsByAccount[account.ItemID+":"+account.AccountID] = projection
+		liabilities[projection] = ledger.IsLiability(account.Type.String)
+	}
+
+	from := ledger.IntervalDay.Start(time.Now())
+	to := from.AddDate(0, 0, days)
+	for _, series := range series {
+		projection, ok := projectionsByAccount[series.ItemID.String+":"+series.AccountID.String]
+		if !ok {
+			continue
+		}
+
+		for _, date := range series.Occurrences(from, to) {
+			projection.Transactions = append(projection.Transactions, &ledger.ProjectedTransaction{
+				Date:   date,
+				Series: series,
+				Amount: series.SignedAmount(),
+			})
+		}
+	}
+
+	for _, projection := range projections {
+		sort.SliceStable(projection.Transactions, func(i, j int) bool {
+			return projection.Transactions[i].Date.Before(projection.Transactions[j].Date)
+		})
+
+		// The balance of a credit or loan account is what is owed, so a bill raises it
+		balance := projection.Current
+		for _, transaction := range projection.Transactions {
+			if liabilities[projection] {
+				balance -= transaction.Amount
+			} else {
+				balance += transaction.Amount
+			}
+
+			balance = math.Round(balance*100) / 100
+			transaction.Balance = balance
+		}
+
+		projection.Projected = balance
+	}
+
+	return projections, nil
+
+}
@@ -0,0 +1,301 @@
+// Package report provides service access to aggregate views of a users transactions
+package report
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/currency"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+type Service interface {
+	Spending(ctx context.Context, user *ledger.User, groupBy ledger.SpendingGroupBy, filters *ledger.TransactionFilter) ([]*ledger.SpendingGroup, error)
+	CashFlow(ctx context.Context, user *ledger.User, interval ledger.Interval, from, to time.Time, accountIDs []string) ([]*ledger.CashFlowPeriod, error)
+	NetWorth(ctx context.Context, user *ledger.User, interval ledger.Interval, from, to time.Time) ([]*ledger.NetWorthPeriod, error)
+}
+
+type service struct {
+	currency currency.Service
+
+	accounts     ledger.AccountRepository
+	transactions ledger.TransactionRepository
+}
+
+func New(currency currency.Service, accounts ledger.AccountRepository, transactions ledger.TransactionRepository) Service {
+	return &service{
+		currency:     currency,
+		accounts:     accounts,
+		transactions: transactions,
+	}
+}
+
+// Spending returns the sum, count and average of the users transactions grouped by category,
+// merchant or account, largest total first. Transactions in other currencies are converted into the
+// users base currency at the rate for the day they posted. Transactions without a rate for that day
+// are left out of the totals and counted as unconverted rather than failing the report
+func (s *service) Spending(ctx context.Context, user *ledger.User, groupBy ledger.SpendingGroupBy, filters *ledger.TransactionFilter) ([]*ledger.SpendingGroup, error) {
+
+	if !groupBy.Valid() {
+		return nil, errors.Errorf("[report.Spending] %s is not a valid grouping", groupBy)
+	}
+
+	totals, err := s.transactions.SpendingTotals(ctx, user.ID, groupBy, filters)
+	if err != nil {
+		return nil, errors.Wrap(err, "[report.Spending] failed to fetch spending totals")
+	}
+
+	convert := s.converter(user.BaseCurrency)
+
+	var groups = make([]*ledger.SpendingGroup, 0, len(totals))
+	var groupsByKey = make(map[string]*ledger.SpendingGroup)
+	for _, total := range totals {
+
+		key := total.ItemID.String + ":" + total.Key.String
+		group, ok := groupsByKey[key]
+		if !ok {
+			group = &ledger.SpendingGroup{
+				GroupBy:  groupBy,
+				Key:      total.Key,
+				ItemID:   total.ItemID,
+				Currency: user.BaseCurrency,
+			}
+			groupsByKey[key] = group
+			groups = append(groups, group)
+		}
+
+		group.Count += total.Count
+
+		amount, err := convert(ctx, total.Total, total.CurrencyCode, total.Date)
+		if errors.Is(err, currency.ErrExchangeRateNotFound) {
+			group.Unconverted += total.Count
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "[report.Spending] failed to convert total to base currency")
+		}
+
+		group.Total += amount
+	}
+
+	for _, group := range groups {
+		group.Total = math.Round(group.Total*100) / 100
+		if converted := group.Count - group.Unconverted; converted > 0 {
+			group.Average = math.Round(group.Total/float64(converted)*100) / 100
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return math.Abs(groups[i].Total) > math.Abs(groups[j].Total)
+	})
+
+	return groups, nil
+
+}
+
+// maxPeriods keeps a daily series over many years from producing an unbounded response
+const maxPeriods = 1000
+
+// CashFlow returns the income, expenses and savings rate of the user for every interval between from
+// and to, oldest first. Intervals without any transactions are included with zero totals so that
+// the series can be charted directly. Transactions in other currencies are converted into the users
+// base currency at the rate for the day they posted. Transactions without a rate for that day are left
+// out of the totals and counted as unconverted rather than failing the report
+func (s *service) CashFlow(ctx context.Context, user *ledger.User, interval ledger.Interval, from, to time.Time, accountIDs []string) ([]*ledger.CashFlowPeriod, error) {
+
+	if !interval.Valid() {
+		return nil, errors.Errorf("[report.CashFlow] %s is not a valid interval", interval)
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("[report.CashFlow] from must be before to")
+	}
+
+	from, to = interval.Start(from.UTC()), to.UTC()
+
+	var periods = make([]*ledger.CashFlowPeriod, 0)
+	var periodsByStart = make(map[time.Time]*ledger.CashFlowPeriod)
+	for start := from; !start.After(to); start = interval.Next(start) {
+		if len(periods) == maxPeriods {
+			return nil, errors.Errorf("[report.CashFlow] range covers more than %d periods, use a larger interval", maxPeriods)
+		}
+
+		period := &ledger.CashFlowPeriod{
+			Start:    start,
+			End:      interval.Next(start).AddDate(0, 0, -1),
+			Currency: user.BaseCurrency,
+		}
+		periods = append(periods, period)
+		periodsByStart[start] = period
+	}
+
+	totals, err := s.transactions.CashFlowTotals(ctx, user.ID, interval, from, to, accountIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "[report.CashFlow] failed to fetch cash flow totals")
+	}
+
+	convert := s.converter(user.BaseCurrency)
+	for _, total := range totals {
+		period, ok := periodsByStart[interval.Start(total.Period.UTC())]
+		if !ok {
+			continue
+		}
+
+		income, err := convert(ctx, total.Income, total.CurrencyCode, total.Date)
+		if errors.Is(err, currency.ErrExchangeRateNotFound) {
+			period.Unconverted += total.Count
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "[report.CashFlow] failed to convert income to base currency")
+		}
+
+		expenses, err := convert(ctx, total.Expenses, total.CurrencyCode, total.Date)
+		if err != nil {
+			return nil, errors.Wrap(err, "[report.CashFlow] failed to convert expenses to base currency")
+		}
+
+		period.Income += income
+		period.Expenses += expenses
+	}
+
+	for _, period := range periods {
+		period.Income = math.Round(period.Income*100) / 100
+		period.Expenses = math.Round(period.Expenses*100) / 100
+		period.Net = math.Round((period.Income-period.Expenses)*100) / 100
+		if period.Income > 0 {
+			period.SavingsRate = null.Float64From(math.Round(period.Net/period.Income*10000) / 10000)
+		}
+	}
+
+	return periods, nil
+
+}
+
+// NetWorth returns the assets, liabilities and net worth of the user at the end of every interval
+// between from and to, oldest first. Each account contributes the most recent snapshot of its
+// balance taken on or before the end of the interval, accounts without a snapshot by then are left out.
+// Balances in a currency without an exchange rate are left out and counted as unconverted
+func (s *service) NetWorth(ctx context.Context, user *ledger.User, interval ledger.Interval, from, to time.Time) ([]*ledger.NetWorthPeriod, error) {
+
+	if !interval.Valid() {
+		return nil, errors.Errorf("[report.NetWorth] %s is not a valid interval", interval)
+	}
+
+	if to.Before(from) {
+		return nil, errors.New("[report.NetWorth] from must be before to")
+	}
+
+	from, to = interval.Start(from.UTC()), to.UTC()
+
+	var dates = make([]time.Time, 0)
+	for start := from; !start.After(to); start = interval.Next(start) {
+		if len(dates) == maxPeriods {
+			return nil, errors.Errorf("[report.NetWorth] range covers more than %d periods, use a larger interval", maxPeriods)
+		}
+
+		date := interval.Next(start).AddDate(0, 0, -1)
+		if date.After(to) {
+			date = to
+		}
+		dates = append(dates, date)
+	}
+
+	accounts, err := s.accounts.AccountsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[report.NetWorth] failed to fetch accounts")
+	}
+
+	var accountTypes = make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		accountTypes[account.ItemID+":"+account.AccountID] = account.Type.String
+	}
+
+	snapshots, err := s.accounts.AccountBalanceSnapshotsByUserID(ctx, user.ID, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "[report.NetWorth] failed to fetch balance snapshots")
+	}
+
+	convert := s.converter(user.BaseCurrency)
+
+	now := time.Now()
+	var latest = make(map[string]*ledger.AccountBalanceSnapshot)
+	var periods = make([]*ledger.NetWorthPeriod, 0, len(dates))
+	var next int
+	for _, date := range dates {
+
+		// Snapshots are ordered oldest first, so each period only needs to apply the snapshots
+		// taken since the previous one
+		for next < len(snapshots) && !snapshots[next].Date.UTC().After(date) {
+			snapshot := snapshots[next]
+			latest[snapshot.ItemID+":"+snapshot.AccountID] = snapshot
+			next++
+		}
+
+		rateDate := date
+		if rateDate.After(now) {
+			rateDate = now
+		}
+
+		period := &ledger.NetWorthPeriod{
+			Date:     date,
+			Currency: user.BaseCurrency,
+		}
+
+		for key, snapshot := range latest {
+			balance, err := convert(ctx, snapshot.Current, snapshot.CurrencyCode(), rateDate)
+			if errors.Is(err, currency.ErrExchangeRateNotFound) {
+				period.Unconverted++
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, "[report.NetWorth] failed to convert balance to base currency")
+			}
+
+			if ledger.IsLiability(accountTypes[key]) {
+				period.Liabilities += balance
+			} else {
+				period.Assets += balance
+			}
+		}
+
+		period.Assets = math.Round(period.Assets*100) / 100
+		period.Liabilities = math.Round(period.Liabilities*100) / 100
+		period.NetWorth = math.Round((period.Assets-period.Liabilities)*100) / 100
+		periods = append(periods, period)
+
+	}
+
+	return periods, nil
+
+}
+
+// converter returns a function that converts amounts into the to currency at the rate for date,
+// looking each currency and date up only once for the lifetime of the function
+func (s *service) converter(to string) func(ctx context.Context, amount float64, from string, date time.Time) (float64, error) {
+
+	type rate struct {
+		rate float64
+		err  error
+	}
+
+	var rates = make(map[string]rate)
+	return func(ctx context.Context, amount float64, from string, date time.Time) (float64, error) {
+		key := from + ":" + date.Format("2006-01-02")
+		r, ok := rates[key]
+		if !ok {
+			r.rate, r.err = s.currency.Convert(ctx, 1, from, to, date)
+			if r.err != nil && !errors.Is(r.err, currency.ErrExchangeRateNotFound) {
+				return 0, r.err
+			}
+			rates[key] = r
+		}
+
+		return amount * r.rate, r.err
+	}
+
+}
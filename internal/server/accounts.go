@@ -8,9 +8,11 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ddouglas/ledger"
 	"github.com/ddouglas/ledger/internal"
+	"github.com/ddouglas/ledger/internal/receipt"
 	"github.com/go-chi/chi/v5"
 	"github.com/pkg/errors"
 	"github.com/r3labs/diff/v2"
@@ -300,18 +302,23 @@ func (s *server) handleGetAccountTransactionReceiptURL(w http.ResponseWriter, r
 		return
 	}
 
-	// presigned, err := s.transaction.TransactionReceiptPresignedURL(ctx, itemID, transactionID)
-	// if err != nil {
-	// 	GetLogEntry(r).WithError(err).Error()
-	// 	s.writeError(ctx, w, http.StatusBadRequest, errors.New("failed to generate presigned url for transaction receipt"))
-	// 	return
-	// }
+	receipt, err := s.transaction.TransactionReceiptPresignedURL(ctx, itemID, transactionID)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, errors.New("failed to generate presigned url for transaction receipt"))
+		return
+	}
 
-	// s.writeResponse(ctx, w, http.StatusOK, struct {
-	// 	URL string `json:"url"`
-	// }{
-	// 	URL: presigned,
-	// })
+	if !receipt.Get.Valid {
+		s.writeError(ctx, w, http.StatusNotFound, errors.New("transaction does not have a receipt"))
+		return
+	}
+
+	s.writeResponse(ctx, w, http.StatusOK, struct {
+		URL string `json:"url"`
+	}{
+		URL: receipt.Get.String,
+	})
 
 }
 
@@ -390,14 +397,28 @@ func (s *server) handlePostAccountTransactionReceipt(w http.ResponseWriter, r *h
 	// Read the first buffer into the base 64 decoder
 	b64Decoder := base64.NewDecoder(base64.StdEncoding, buf)
 	// Read from the decoder now into our second buffer
-	_, _ = buf2.ReadFrom(b64Decoder)
-
-	// err = s.transaction.AddReceiptToTransaction(ctx, itemID, transactionID, buf2)
-	// if err != nil {
-	// 	GetLogEntry(r).WithError(err).Error()
-	// 	s.writeError(ctx, w, http.StatusInternalServerError, errors.New("failed to add file to transaction"))
-	// 	return
-	// }
+	_, err = buf2.ReadFrom(b64Decoder)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, errors.New("failed to decode base64 encoded request body"))
+		return
+	}
+
+	data := buf2.Bytes()
+	contentType := receipt.DetectContentType(data)
+
+	_, err = s.transaction.AddReceiptToTransaction(ctx, user.ID, itemID, transactionID, graphql.Upload{
+		File:        bytes.NewReader(data),
+		Filename:    transactionID,
+		Size:        int64(len(data)),
+		ContentType: contentType,
+	})
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusInternalServerError, errors.New("failed to add file to transaction"))
+		return
+	}
+
 	s.writeResponse(ctx, w, http.StatusNoContent, nil)
 
 }
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pkg/errors"
+)
+
+// handleGetCalendar serves the upcoming bills and paydays of the user that owns the token in the
+// url as an iCalendar feed. Calendar clients cannot send a bearer token, so the token is the only
+// credential and an unknown token is indistinguishable from a missing calendar
+func (s *server) handleGetCalendar(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		s.writeError(ctx, w, http.StatusNotFound, errors.New("calendar not found"))
+		return
+	}
+
+	user, err := s.user.UserByCalendarToken(ctx, token)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			GetLogEntry(r).WithError(err).Error()
+		}
+		s.writeError(ctx, w, http.StatusNotFound, errors.New("calendar not found"))
+		return
+	}
+
+	calendar, err := s.recurring.Calendar(ctx, user)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusInternalServerError, errors.New("failed to build calendar"))
+		return
+	}
+
+	w.Header().Set("content-type", "text/calendar; charset=utf-8")
+	w.Header().Set("cache-control", "private, max-age=900")
+	_, _ = w.Write(calendar)
+
+}
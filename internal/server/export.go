@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+// handleGetTransactionsExport streams the transactions of a user as CSV. Exports requested with the
+// requestExport mutation are downloaded with the token it returned, so that they can be fetched by a
+// plain link. Without a token the request must carry a bearer token and the export is described by
+// the query string
+func (s *server) handleGetTransactionsExport(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		s.authorization(http.HandlerFunc(s.handleGetAuthorizedTransactionsExport)).ServeHTTP(w, r)
+		return
+	}
+
+	export, err := s.export.TransactionExport(ctx, token)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusInternalServerError, errors.New("failed to fetch export"))
+		return
+	}
+
+	if export == nil {
+		s.writeError(ctx, w, http.StatusNotFound, errors.New("export not found or expired"))
+		return
+	}
+
+	s.writeTransactionsExport(w, r, export.UserID, export.Options)
+
+}
+
+func (s *server) handleGetAuthorizedTransactionsExport(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	values := r.URL.Query()
+
+	var filters = new(ledger.TransactionFilter)
+	err := filters.BuildFromURLValues(values)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	options := &ledger.TransactionExportOptions{
+		Filters:    filters,
+		DateFormat: ledger.ExportDateFormat(values.Get("dateFormat")),
+	}
+
+	columns := values.Get("columns")
+	if columns != "" {
+		for _, column := range strings.Split(columns, ",") {
+			options.Columns = append(options.Columns, ledger.ExportColumn(strings.TrimSpace(column)))
+		}
+	}
+
+	s.writeTransactionsExport(w, r, user.ID, options)
+
+}
+
+func (s *server) writeTransactionsExport(w http.ResponseWriter, r *http.Request, userID uuid.UUID, options *ledger.TransactionExportOptions) {
+
+	var ctx = r.Context()
+
+	err := s.export.ValidateTransactionExportOptions(options)
+	if err != nil {
+		s.writeError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("content-type", "text/csv; charset=utf-8")
+	w.Header().Set("content-disposition", `attachment; filename="transactions.csv"`)
+
+	// Rows are streamed, so by the time an error occurs the response has already been started
+	err = s.export.WriteTransactionsCSV(ctx, w, userID, options)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error("failed to write transactions export")
+	}
+
+}
@@ -6,8 +6,75 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 )
 
+type AlertRuleInput struct {
+	Name            string        `json:"name"`
+	Type            AlertRuleType `json:"type"`
+	Threshold       float32       `json:"threshold"`
+	ItemID          *string       `json:"itemID"`
+	AccountID       *string       `json:"accountID"`
+	BudgetID        *string       `json:"budgetID"`
+	CooldownMinutes *uint         `json:"cooldownMinutes"`
+	Enabled         *bool         `json:"enabled"`
+}
+
+type BudgetInput struct {
+	Name      string      `json:"name"`
+	Scope     BudgetScope `json:"scope"`
+	ScopeID   string      `json:"scopeID"`
+	Period    Interval    `json:"period"`
+	Amount    float32     `json:"amount"`
+	Rollover  *bool       `json:"rollover"`
+	StartDate *time.Time  `json:"startDate"`
+}
+
+type ManualAccountInput struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Subtype  *string  `json:"subtype"`
+	Mask     *string  `json:"mask"`
+	Currency string   `json:"currency"`
+	Balance  *float32 `json:"balance"`
+}
+
+type NotificationPreferencesInput struct {
+	NewTransactions bool            `json:"newTransactions"`
+	Digest          DigestFrequency `json:"digest"`
+}
+
+type RecurringSeriesInput struct {
+	Name         string             `json:"name"`
+	Kind         RecurringKind      `json:"kind"`
+	Frequency    RecurringFrequency `json:"frequency"`
+	Amount       float32            `json:"amount"`
+	CurrencyCode *string            `json:"currencyCode"`
+	ItemID       string             `json:"itemID"`
+	AccountID    string             `json:"accountID"`
+	NextDate     time.Time          `json:"nextDate"`
+}
+
+type StatementProfileInput struct {
+	Delimiter         *string           `json:"delimiter"`
+	SkipRows          *int              `json:"skipRows"`
+	DateColumn        int               `json:"dateColumn"`
+	DateFormat        *ExportDateFormat `json:"dateFormat"`
+	DescriptionColumn int               `json:"descriptionColumn"`
+	AmountColumn      *int              `json:"amountColumn"`
+	DebitColumn       *int              `json:"debitColumn"`
+	CreditColumn      *int              `json:"creditColumn"`
+	IDColumn          *int              `json:"idColumn"`
+	InvertAmounts     *bool             `json:"invertAmounts"`
+}
+
+type TransactionChange struct {
+	Type  string  `json:"type"`
+	Field string  `json:"field"`
+	From  *string `json:"from"`
+	To    *string `json:"to"`
+}
+
 type TransactionFilter struct {
 	CategoryID        *string          `json:"categoryID"`
 	MerchantID        *string          `json:"merchantID"`
@@ -18,6 +85,638 @@ type TransactionFilter struct {
 	DateInclusive     *bool            `json:"dateInclusive"`
 	OnDate            *string          `json:"onDate"`
 	TransactionType   *TransactionType `json:"transactionType"`
+	Hidden            *bool            `json:"hidden"`
+}
+
+type WebhookEndpointInput struct {
+	URL         string             `json:"url"`
+	Description *string            `json:"description"`
+	EventTypes  []WebhookEventType `json:"eventTypes"`
+	Enabled     *bool              `json:"enabled"`
+}
+
+type AlertRuleType string
+
+const (
+	AlertRuleTypeLargeTransaction AlertRuleType = "LARGE_TRANSACTION"
+	AlertRuleTypeLowBalance       AlertRuleType = "LOW_BALANCE"
+	AlertRuleTypeBudget           AlertRuleType = "BUDGET"
+	AlertRuleTypeNewMerchant      AlertRuleType = "NEW_MERCHANT"
+)
+
+var AllAlertRuleType = []AlertRuleType{
+	AlertRuleTypeLargeTransaction,
+	AlertRuleTypeLowBalance,
+	AlertRuleTypeBudget,
+	AlertRuleTypeNewMerchant,
+}
+
+func (e AlertRuleType) IsValid() bool {
+	switch e {
+	case AlertRuleTypeLargeTransaction, AlertRuleTypeLowBalance, AlertRuleTypeBudget, AlertRuleTypeNewMerchant:
+		return true
+	}
+	return false
+}
+
+func (e AlertRuleType) String() string {
+	return string(e)
+}
+
+func (e *AlertRuleType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AlertRuleType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AlertRuleType", str)
+	}
+	return nil
+}
+
+func (e AlertRuleType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type AnomalyReasonType string
+
+const (
+	AnomalyReasonTypeAmount      AnomalyReasonType = "AMOUNT"
+	AnomalyReasonTypeNewMerchant AnomalyReasonType = "NEW_MERCHANT"
+	AnomalyReasonTypeLocation    AnomalyReasonType = "LOCATION"
+)
+
+var AllAnomalyReasonType = []AnomalyReasonType{
+	AnomalyReasonTypeAmount,
+	AnomalyReasonTypeNewMerchant,
+	AnomalyReasonTypeLocation,
+}
+
+func (e AnomalyReasonType) IsValid() bool {
+	switch e {
+	case AnomalyReasonTypeAmount, AnomalyReasonTypeNewMerchant, AnomalyReasonTypeLocation:
+		return true
+	}
+	return false
+}
+
+func (e AnomalyReasonType) String() string {
+	return string(e)
+}
+
+func (e *AnomalyReasonType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AnomalyReasonType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AnomalyReasonType", str)
+	}
+	return nil
+}
+
+func (e AnomalyReasonType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type BudgetScope string
+
+const (
+	BudgetScopeCategory BudgetScope = "CATEGORY"
+	BudgetScopeMerchant BudgetScope = "MERCHANT"
+)
+
+var AllBudgetScope = []BudgetScope{
+	BudgetScopeCategory,
+	BudgetScopeMerchant,
+}
+
+func (e BudgetScope) IsValid() bool {
+	switch e {
+	case BudgetScopeCategory, BudgetScopeMerchant:
+		return true
+	}
+	return false
+}
+
+func (e BudgetScope) String() string {
+	return string(e)
+}
+
+func (e *BudgetScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BudgetScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BudgetScope", str)
+	}
+	return nil
+}
+
+func (e BudgetScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DigestFrequency string
+
+const (
+	DigestFrequencyNone   DigestFrequency = "NONE"
+	DigestFrequencyDaily  DigestFrequency = "DAILY"
+	DigestFrequencyWeekly DigestFrequency = "WEEKLY"
+)
+
+var AllDigestFrequency = []DigestFrequency{
+	DigestFrequencyNone,
+	DigestFrequencyDaily,
+	DigestFrequencyWeekly,
+}
+
+func (e DigestFrequency) IsValid() bool {
+	switch e {
+	case DigestFrequencyNone, DigestFrequencyDaily, DigestFrequencyWeekly:
+		return true
+	}
+	return false
+}
+
+func (e DigestFrequency) String() string {
+	return string(e)
+}
+
+func (e *DigestFrequency) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DigestFrequency(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DigestFrequency", str)
+	}
+	return nil
+}
+
+func (e DigestFrequency) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExportColumn string
+
+const (
+	ExportColumnDate              ExportColumn = "DATE"
+	ExportColumnAuthorizedDate    ExportColumn = "AUTHORIZED_DATE"
+	ExportColumnName              ExportColumn = "NAME"
+	ExportColumnAmount            ExportColumn = "AMOUNT"
+	ExportColumnCurrency          ExportColumn = "CURRENCY"
+	ExportColumnMerchant          ExportColumn = "MERCHANT"
+	ExportColumnCategory          ExportColumn = "CATEGORY"
+	ExportColumnCategoryHierarchy ExportColumn = "CATEGORY_HIERARCHY"
+	ExportColumnAccount           ExportColumn = "ACCOUNT"
+	ExportColumnAccountMask       ExportColumn = "ACCOUNT_MASK"
+	ExportColumnPending           ExportColumn = "PENDING"
+	ExportColumnPaymentChannel    ExportColumn = "PAYMENT_CHANNEL"
+	ExportColumnHiddenReason      ExportColumn = "HIDDEN_REASON"
+	ExportColumnItemID            ExportColumn = "ITEM_ID"
+	ExportColumnAccountID         ExportColumn = "ACCOUNT_ID"
+	ExportColumnTransactionID     ExportColumn = "TRANSACTION_ID"
+)
+
+var AllExportColumn = []ExportColumn{
+	ExportColumnDate,
+	ExportColumnAuthorizedDate,
+	ExportColumnName,
+	ExportColumnAmount,
+	ExportColumnCurrency,
+	ExportColumnMerchant,
+	ExportColumnCategory,
+	ExportColumnCategoryHierarchy,
+	ExportColumnAccount,
+	ExportColumnAccountMask,
+	ExportColumnPending,
+	ExportColumnPaymentChannel,
+	ExportColumnHiddenReason,
+	ExportColumnItemID,
+	ExportColumnAccountID,
+	ExportColumnTransactionID,
+}
+
+func (e ExportColumn) IsValid() bool {
+	switch e {
+	case ExportColumnDate, ExportColumnAuthorizedDate, ExportColumnName, ExportColumnAmount, ExportColumnCurrency, ExportColumnMerchant, ExportColumnCategory, ExportColumnCategoryHierarchy, ExportColumnAccount, ExportColumnAccountMask, ExportColumnPending, ExportColumnPaymentChannel, ExportColumnHiddenReason, ExportColumnItemID, ExportColumnAccountID, ExportColumnTransactionID:
+		return true
+	}
+	return false
+}
+
+func (e ExportColumn) String() string {
+	return string(e)
+}
+
+func (e *ExportColumn) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExportColumn(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExportColumn", str)
+	}
+	return nil
+}
+
+func (e ExportColumn) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExportDateFormat string
+
+const (
+	ExportDateFormatIso ExportDateFormat = "ISO"
+	ExportDateFormatUs  ExportDateFormat = "US"
+	ExportDateFormatEu  ExportDateFormat = "EU"
+)
+
+var AllExportDateFormat = []ExportDateFormat{
+	ExportDateFormatIso,
+	ExportDateFormatUs,
+	ExportDateFormatEu,
+}
+
+func (e ExportDateFormat) IsValid() bool {
+	switch e {
+	case ExportDateFormatIso, ExportDateFormatUs, ExportDateFormatEu:
+		return true
+	}
+	return false
+}
+
+func (e ExportDateFormat) String() string {
+	return string(e)
+}
+
+func (e *ExportDateFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExportDateFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExportDateFormat", str)
+	}
+	return nil
+}
+
+func (e ExportDateFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Interval string
+
+const (
+	IntervalDay   Interval = "DAY"
+	IntervalWeek  Interval = "WEEK"
+	IntervalMonth Interval = "MONTH"
+	IntervalYear  Interval = "YEAR"
+)
+
+var AllInterval = []Interval{
+	IntervalDay,
+	IntervalWeek,
+	IntervalMonth,
+	IntervalYear,
+}
+
+func (e Interval) IsValid() bool {
+	switch e {
+	case IntervalDay, IntervalWeek, IntervalMonth, IntervalYear:
+		return true
+	}
+	return false
+}
+
+func (e Interval) String() string {
+	return string(e)
+}
+
+func (e *Interval) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Interval(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Interval", str)
+	}
+	return nil
+}
+
+func (e Interval) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type JournalSourceType string
+
+const (
+	JournalSourceTypeAccount  JournalSourceType = "ACCOUNT"
+	JournalSourceTypeCategory JournalSourceType = "CATEGORY"
+)
+
+var AllJournalSourceType = []JournalSourceType{
+	JournalSourceTypeAccount,
+	JournalSourceTypeCategory,
+}
+
+func (e JournalSourceType) IsValid() bool {
+	switch e {
+	case JournalSourceTypeAccount, JournalSourceTypeCategory:
+		return true
+	}
+	return false
+}
+
+func (e JournalSourceType) String() string {
+	return string(e)
+}
+
+func (e *JournalSourceType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = JournalSourceType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid JournalSourceType", str)
+	}
+	return nil
+}
+
+func (e JournalSourceType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type NotificationType string
+
+const (
+	NotificationTypeItemError            NotificationType = "ITEM_ERROR"
+	NotificationTypeImportCompleted      NotificationType = "IMPORT_COMPLETED"
+	NotificationTypeAlertFired           NotificationType = "ALERT_FIRED"
+	NotificationTypeSubscriptionDetected NotificationType = "SUBSCRIPTION_DETECTED"
+)
+
+var AllNotificationType = []NotificationType{
+	NotificationTypeItemError,
+	NotificationTypeImportCompleted,
+	NotificationTypeAlertFired,
+	NotificationTypeSubscriptionDetected,
+}
+
+func (e NotificationType) IsValid() bool {
+	switch e {
+	case NotificationTypeItemError, NotificationTypeImportCompleted, NotificationTypeAlertFired, NotificationTypeSubscriptionDetected:
+		return true
+	}
+	return false
+}
+
+func (e NotificationType) String() string {
+	return string(e)
+}
+
+func (e *NotificationType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationType", str)
+	}
+	return nil
+}
+
+func (e NotificationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RecurringFrequency string
+
+const (
+	RecurringFrequencyWeekly  RecurringFrequency = "WEEKLY"
+	RecurringFrequencyMonthly RecurringFrequency = "MONTHLY"
+	RecurringFrequencyAnnual  RecurringFrequency = "ANNUAL"
+)
+
+var AllRecurringFrequency = []RecurringFrequency{
+	RecurringFrequencyWeekly,
+	RecurringFrequencyMonthly,
+	RecurringFrequencyAnnual,
+}
+
+func (e RecurringFrequency) IsValid() bool {
+	switch e {
+	case RecurringFrequencyWeekly, RecurringFrequencyMonthly, RecurringFrequencyAnnual:
+		return true
+	}
+	return false
+}
+
+func (e RecurringFrequency) String() string {
+	return string(e)
+}
+
+func (e *RecurringFrequency) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RecurringFrequency(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RecurringFrequency", str)
+	}
+	return nil
+}
+
+func (e RecurringFrequency) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RecurringKind string
+
+const (
+	RecurringKindBill   RecurringKind = "BILL"
+	RecurringKindIncome RecurringKind = "INCOME"
+)
+
+var AllRecurringKind = []RecurringKind{
+	RecurringKindBill,
+	RecurringKindIncome,
+}
+
+func (e RecurringKind) IsValid() bool {
+	switch e {
+	case RecurringKindBill, RecurringKindIncome:
+		return true
+	}
+	return false
+}
+
+func (e RecurringKind) String() string {
+	return string(e)
+}
+
+func (e *RecurringKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RecurringKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RecurringKind", str)
+	}
+	return nil
+}
+
+func (e RecurringKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RecurringSource string
+
+const (
+	RecurringSourceDetected RecurringSource = "DETECTED"
+	RecurringSourceUser     RecurringSource = "USER"
+)
+
+var AllRecurringSource = []RecurringSource{
+	RecurringSourceDetected,
+	RecurringSourceUser,
+}
+
+func (e RecurringSource) IsValid() bool {
+	switch e {
+	case RecurringSourceDetected, RecurringSourceUser:
+		return true
+	}
+	return false
+}
+
+func (e RecurringSource) String() string {
+	return string(e)
+}
+
+func (e *RecurringSource) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RecurringSource(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RecurringSource", str)
+	}
+	return nil
+}
+
+func (e RecurringSource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RecurringStatus string
+
+const (
+	RecurringStatusActive  RecurringStatus = "ACTIVE"
+	RecurringStatusMissing RecurringStatus = "MISSING"
+)
+
+var AllRecurringStatus = []RecurringStatus{
+	RecurringStatusActive,
+	RecurringStatusMissing,
+}
+
+func (e RecurringStatus) IsValid() bool {
+	switch e {
+	case RecurringStatusActive, RecurringStatusMissing:
+		return true
+	}
+	return false
+}
+
+func (e RecurringStatus) String() string {
+	return string(e)
+}
+
+func (e *RecurringStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RecurringStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RecurringStatus", str)
+	}
+	return nil
+}
+
+func (e RecurringStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SpendingGroupBy string
+
+const (
+	SpendingGroupByCategory SpendingGroupBy = "CATEGORY"
+	SpendingGroupByMerchant SpendingGroupBy = "MERCHANT"
+	SpendingGroupByAccount  SpendingGroupBy = "ACCOUNT"
+)
+
+var AllSpendingGroupBy = []SpendingGroupBy{
+	SpendingGroupByCategory,
+	SpendingGroupByMerchant,
+	SpendingGroupByAccount,
+}
+
+func (e SpendingGroupBy) IsValid() bool {
+	switch e {
+	case SpendingGroupByCategory, SpendingGroupByMerchant, SpendingGroupByAccount:
+		return true
+	}
+	return false
+}
+
+func (e SpendingGroupBy) String() string {
+	return string(e)
+}
+
+func (e *SpendingGroupBy) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SpendingGroupBy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SpendingGroupBy", str)
+	}
+	return nil
+}
+
+func (e SpendingGroupBy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
 type TransactionType string
@@ -60,3 +759,52 @@ func (e *TransactionType) UnmarshalGQL(v interface{}) error {
 func (e TransactionType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type WebhookEventType string
+
+const (
+	WebhookEventTypeTransactionCreated   WebhookEventType = "TRANSACTION_CREATED"
+	WebhookEventTypeTransactionUpdated   WebhookEventType = "TRANSACTION_UPDATED"
+	WebhookEventTypeItemError            WebhookEventType = "ITEM_ERROR"
+	WebhookEventTypeImportCompleted      WebhookEventType = "IMPORT_COMPLETED"
+	WebhookEventTypeAlertFired           WebhookEventType = "ALERT_FIRED"
+	WebhookEventTypeSubscriptionDetected WebhookEventType = "SUBSCRIPTION_DETECTED"
+)
+
+var AllWebhookEventType = []WebhookEventType{
+	WebhookEventTypeTransactionCreated,
+	WebhookEventTypeTransactionUpdated,
+	WebhookEventTypeItemError,
+	WebhookEventTypeImportCompleted,
+	WebhookEventTypeAlertFired,
+	WebhookEventTypeSubscriptionDetected,
+}
+
+func (e WebhookEventType) IsValid() bool {
+	switch e {
+	case WebhookEventTypeTransactionCreated, WebhookEventTypeTransactionUpdated, WebhookEventTypeItemError, WebhookEventTypeImportCompleted, WebhookEventTypeAlertFired, WebhookEventTypeSubscriptionDetected:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEventType) String() string {
+	return string(e)
+}
+
+func (e *WebhookEventType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEventType", str)
+	}
+	return nil
+}
+
+func (e WebhookEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
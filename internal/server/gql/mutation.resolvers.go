@@ -6,12 +6,197 @@ package resolvers
 import (
 	"context"
 	"errors"
+	"math"
+	"strings"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/ddouglas/ledger"
 	"github.com/ddouglas/ledger/internal"
+	"github.com/ddouglas/ledger/internal/journal"
 	"github.com/ddouglas/ledger/internal/server/gql/generated"
+	"github.com/ddouglas/ledger/internal/server/gql/model"
+	"github.com/volatiletech/null"
 )
 
+func (r *mutationResolver) CreateManualAccount(ctx context.Context, input model.ManualAccountInput) (*ledger.Account, error) {
+	user := internal.UserFromContext(ctx)
+
+	account := &ledger.Account{
+		Name:    null.StringFrom(input.Name),
+		Type:    null.StringFrom(input.Type),
+		Subtype: null.StringFromPtr(input.Subtype),
+		Mask:    null.StringFromPtr(input.Mask),
+		Balance: &ledger.AccountBalance{
+			ISOCurrencyCode: input.Currency,
+		},
+	}
+
+	if input.Balance != nil {
+		account.Balance.Current = math.Round(float64(*input.Balance)*100) / 100
+		account.Balance.Available = account.Balance.Current
+		account.Balance.LastUpdated = null.TimeFrom(time.Now())
+	}
+
+	account, err := r.item.CreateManualAccount(ctx, user.ID, account)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to create manual account")
+		return nil, errors.New("failed to create manual account")
+	}
+
+	return account, nil
+}
+
+func (r *mutationResolver) SaveStatementProfile(ctx context.Context, itemID string, accountID string, input model.StatementProfileInput) (*ledger.StatementProfile, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership of item")
+		return nil, errors.New("failed to verify ownership of item")
+	}
+
+	profile := &ledger.StatementProfile{ItemID: itemID, AccountID: accountID}
+	applyStatementProfileInput(profile, input)
+
+	profile, err = r.statement.SaveStatementProfile(ctx, profile)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to save statement profile")
+		return nil, errors.New("failed to save statement profile")
+	}
+
+	return profile, nil
+}
+
+func (r *mutationResolver) UpdateBalanceFloor(ctx context.Context, itemID string, accountID string, floor *float32) (*ledger.Account, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	var balanceFloor null.Float64
+	if floor != nil {
+		balanceFloor = null.Float64From(math.Round(float64(*floor)*100) / 100)
+	}
+
+	err = r.account.UpdateAccountBalanceFloor(ctx, itemID, accountID, balanceFloor)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to update balance floor")
+		return nil, errors.New("failed to update balance floor")
+	}
+
+	// The stored forecasts were summarised against the old floor
+	_, err = r.forecast.RunForecast(ctx, user)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to run forecast")
+	}
+
+	account, err := r.account.Account(ctx, itemID, accountID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch account")
+		return nil, errors.New("failed to fetch account")
+	}
+
+	return account, nil
+}
+
+func (r *mutationResolver) CreateAlertRule(ctx context.Context, input model.AlertRuleInput) (*ledger.AlertRule, error) {
+	user := internal.UserFromContext(ctx)
+
+	rule := &ledger.AlertRule{UserID: user.ID}
+	applyAlertRuleInput(rule, input)
+
+	rule, err := r.alert.CreateAlertRule(ctx, rule)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to create alert rule")
+		return nil, errors.New("failed to create alert rule")
+	}
+
+	return rule, nil
+}
+
+func (r *mutationResolver) UpdateAlertRule(ctx context.Context, ruleID string, input model.AlertRuleInput) (*ledger.AlertRule, error) {
+	user := internal.UserFromContext(ctx)
+
+	rule, err := r.alert.AlertRule(ctx, user.ID, ruleID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch alert rule")
+		return nil, errors.New("failed to fetch alert rule")
+	}
+
+	applyAlertRuleInput(rule, input)
+
+	rule, err = r.alert.UpdateAlertRule(ctx, ruleID, rule)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to update alert rule")
+		return nil, errors.New("failed to update alert rule")
+	}
+
+	return rule, nil
+}
+
+func (r *mutationResolver) DeleteAlertRule(ctx context.Context, ruleID string) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.alert.DeleteAlertRule(ctx, user.ID, ruleID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to delete alert rule")
+		return false, errors.New("failed to delete alert rule")
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) CreateBudget(ctx context.Context, input model.BudgetInput) (*ledger.Budget, error) {
+	user := internal.UserFromContext(ctx)
+
+	budget := &ledger.Budget{UserID: user.ID}
+	applyBudgetInput(budget, input)
+
+	budget, err := r.budget.CreateBudget(ctx, budget)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to create budget")
+		return nil, errors.New("failed to create budget")
+	}
+
+	return budget, nil
+}
+
+func (r *mutationResolver) UpdateBudget(ctx context.Context, budgetID string, input model.BudgetInput) (*ledger.Budget, error) {
+	user := internal.UserFromContext(ctx)
+
+	budget, err := r.budget.Budget(ctx, user.ID, budgetID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch budget")
+		return nil, errors.New("failed to fetch budget")
+	}
+
+	applyBudgetInput(budget, input)
+
+	budget, err = r.budget.UpdateBudget(ctx, budgetID, budget)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to update budget")
+		return nil, errors.New("failed to update budget")
+	}
+
+	return budget, nil
+}
+
+func (r *mutationResolver) DeleteBudget(ctx context.Context, budgetID string) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.budget.DeleteBudget(ctx, user.ID, budgetID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to delete budget")
+		return false, errors.New("failed to delete budget")
+	}
+
+	return true, nil
+}
+
 func (r *mutationResolver) ConvertMerchantToAlias(ctx context.Context, parent string, child string) (*ledger.Merchant, error) {
 	return r.transaction.ConvertMerchantToAlias(ctx, parent, child)
 }
@@ -29,12 +214,116 @@ func (r *mutationResolver) UpdateMerchant(ctx context.Context, merchantID string
 	return err == nil, err
 }
 
+func (r *mutationResolver) UploadReceipt(ctx context.Context, itemID string, transactionID string, file graphql.Upload) (*ledger.Transaction, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	transaction, err := r.transaction.AddReceiptToTransaction(ctx, user.ID, itemID, transactionID, file)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to upload receipt")
+		return nil, errors.New("failed to upload receipt")
+	}
+
+	return transaction, nil
+}
+
 func (r *mutationResolver) DeleteReceipt(ctx context.Context, itemID string, transactionID string) (bool, error) {
-	err := r.transaction.RemoveReceiptFromTransaction(ctx, itemID, transactionID)
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return false, errors.New("failed to verify ownership")
+	}
+
+	err = r.transaction.RemoveReceiptFromTransaction(ctx, itemID, transactionID)
 
 	return err == nil, err
 }
 
+func (r *mutationResolver) UploadUnmatchedReceipt(ctx context.Context, file graphql.Upload) (*ledger.Receipt, error) {
+	user := internal.UserFromContext(ctx)
+
+	receipt, err := r.transaction.UploadUnmatchedReceipt(ctx, user.ID, file)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to upload receipt")
+		return nil, errors.New("failed to upload receipt")
+	}
+
+	return receipt, nil
+}
+
+func (r *mutationResolver) AssignReceipt(ctx context.Context, receiptID string, itemID string, transactionID string) (*ledger.Transaction, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	transaction, err := r.transaction.AssignReceipt(ctx, user.ID, receiptID, itemID, transactionID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to assign receipt")
+		return nil, errors.New("failed to assign receipt")
+	}
+
+	return transaction, nil
+}
+
+func (r *mutationResolver) DeleteUnmatchedReceipt(ctx context.Context, receiptID string) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.transaction.RemoveUnmatchedReceipt(ctx, user.ID, receiptID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to delete receipt")
+		return false, errors.New("failed to delete receipt")
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) AddAttachment(ctx context.Context, itemID string, transactionID string, file graphql.Upload) (*ledger.TransactionAttachment, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	attachment, err := r.transaction.AddTransactionAttachment(ctx, itemID, transactionID, file)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to add attachment")
+		return nil, errors.New("failed to add attachment")
+	}
+
+	return attachment, nil
+}
+
+func (r *mutationResolver) DeleteAttachment(ctx context.Context, itemID string, attachmentID string) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return false, errors.New("failed to verify ownership")
+	}
+
+	err = r.transaction.RemoveTransactionAttachment(ctx, itemID, attachmentID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to delete attachment")
+		return false, errors.New("failed to delete attachment")
+	}
+
+	return true, nil
+}
+
 func (r *mutationResolver) UpdateTransaction(ctx context.Context, itemID string, transactionID string, input *ledger.UpdateTransactionInput) (*ledger.Transaction, error) {
 	user := internal.UserFromContext(ctx)
 
@@ -52,7 +341,7 @@ func (r *mutationResolver) UpdateTransaction(ctx context.Context, itemID string,
 
 	transaction.FromUpdateTransactionInput(input)
 
-	transaction, err = r.transaction.UpdateTransaction(ctx, transactionID, transaction)
+	transaction, err = r.transaction.UpdateTransactionWithChangelog(ctx, ledger.TransactionChangeSourceUser, user.ID.String(), transaction)
 	if err != nil {
 		r.logger.WithError(err).Error("failed to update transaction")
 		return nil, errors.New("failed to update transaction")
@@ -61,6 +350,380 @@ func (r *mutationResolver) UpdateTransaction(ctx context.Context, itemID string,
 	return transaction, nil
 }
 
+func (r *mutationResolver) HideTransaction(ctx context.Context, itemID string, transactionID string, reason string) (*ledger.Transaction, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	transaction, err := r.transaction.HideTransaction(ctx, ledger.TransactionChangeSourceUser, user.ID.String(), itemID, transactionID, reason)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to hide transaction")
+		return nil, errors.New("failed to hide transaction")
+	}
+
+	return transaction, nil
+}
+
+func (r *mutationResolver) UnhideTransaction(ctx context.Context, itemID string, transactionID string) (*ledger.Transaction, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	transaction, err := r.transaction.UnhideTransaction(ctx, ledger.TransactionChangeSourceUser, user.ID.String(), itemID, transactionID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to unhide transaction")
+		return nil, errors.New("failed to unhide transaction")
+	}
+
+	return transaction, nil
+}
+
+func (r *mutationResolver) AcknowledgeAnomaly(ctx context.Context, itemID string, transactionID string) (*ledger.TransactionAnomaly, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	err = r.anomaly.AcknowledgeTransactionAnomaly(ctx, itemID, transactionID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to acknowledge anomaly")
+		return nil, errors.New("failed to acknowledge anomaly")
+	}
+
+	anomaly, err := r.anomaly.TransactionAnomaly(ctx, itemID, transactionID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch anomaly")
+		return nil, errors.New("failed to fetch anomaly")
+	}
+
+	return anomaly, nil
+}
+
+func (r *mutationResolver) ConfirmRefundMatch(ctx context.Context, itemID string, relationID string) (*ledger.TransactionRelation, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	relation, err := r.transaction.UpdateRefundMatchStatus(ctx, itemID, relationID, ledger.TransactionRelationStatusConfirmed)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to confirm refund match")
+		return nil, errors.New("failed to confirm refund match")
+	}
+
+	return relation, nil
+}
+
+func (r *mutationResolver) RejectRefundMatch(ctx context.Context, itemID string, relationID string) (*ledger.TransactionRelation, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	relation, err := r.transaction.UpdateRefundMatchStatus(ctx, itemID, relationID, ledger.TransactionRelationStatusRejected)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to reject refund match")
+		return nil, errors.New("failed to reject refund match")
+	}
+
+	return relation, nil
+}
+
+func (r *mutationResolver) RequestExport(ctx context.Context, filters *model.TransactionFilter, columns []model.ExportColumn, dateFormat *model.ExportDateFormat) (*ledger.TransactionExport, error) {
+	user := internal.UserFromContext(ctx)
+
+	options := &ledger.TransactionExportOptions{
+		Filters: buildTransactionFilters(filters),
+	}
+	for _, column := range columns {
+		options.Columns = append(options.Columns, ledger.ExportColumn(strings.ToLower(column.String())))
+	}
+	if dateFormat != nil {
+		options.DateFormat = ledger.ExportDateFormat(strings.ToLower(dateFormat.String()))
+	}
+
+	export, err := r.export.RequestTransactionExport(ctx, user, options)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to request export")
+		return nil, errors.New("failed to request export")
+	}
+
+	return export, nil
+}
+
+func (r *mutationResolver) SaveJournalAccountMapping(ctx context.Context, sourceType model.JournalSourceType, sourceID string, accountName string) (*ledger.JournalAccountMapping, error) {
+	user := internal.UserFromContext(ctx)
+
+	mapping, err := r.journal.SaveJournalAccountMapping(ctx, &ledger.JournalAccountMapping{
+		UserID:      user.ID,
+		SourceType:  ledger.JournalSourceType(strings.ToLower(sourceType.String())),
+		SourceID:    sourceID,
+		AccountName: accountName,
+	})
+	if errors.Is(err, journal.ErrInvalidAccountMapping) {
+		return nil, err
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("failed to save journal account mapping")
+		return nil, errors.New("failed to save journal account mapping")
+	}
+
+	return mapping, nil
+}
+
+func (r *mutationResolver) DeleteJournalAccountMapping(ctx context.Context, sourceType model.JournalSourceType, sourceID string) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.journal.DeleteJournalAccountMapping(ctx, user.ID, ledger.JournalSourceType(strings.ToLower(sourceType.String())), sourceID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to delete journal account mapping")
+		return false, errors.New("failed to delete journal account mapping")
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) DetectSubscriptions(ctx context.Context) ([]*ledger.RecurringSeries, error) {
+	user := internal.UserFromContext(ctx)
+
+	series, err := r.recurring.DetectRecurringSeries(ctx, user)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to detect subscriptions")
+		return nil, errors.New("failed to detect subscriptions")
+	}
+
+	return series, nil
+}
+
+func (r *mutationResolver) CreateRecurringSeries(ctx context.Context, input model.RecurringSeriesInput) (*ledger.RecurringSeries, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, input.ItemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	series := &ledger.RecurringSeries{UserID: user.ID}
+	applyRecurringSeriesInput(series, input)
+
+	series, err = r.recurring.CreateRecurringSeries(ctx, series)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to create recurring series")
+		return nil, errors.New("failed to create recurring series")
+	}
+
+	return series, nil
+}
+
+func (r *mutationResolver) UpdateRecurringSeries(ctx context.Context, seriesID string, input model.RecurringSeriesInput) (*ledger.RecurringSeries, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, input.ItemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	series, err := r.recurring.RecurringSeries(ctx, user.ID, seriesID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch recurring series")
+		return nil, errors.New("failed to fetch recurring series")
+	}
+
+	applyRecurringSeriesInput(series, input)
+
+	series, err = r.recurring.UpdateRecurringSeries(ctx, seriesID, series)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to update recurring series")
+		return nil, errors.New("failed to update recurring series")
+	}
+
+	return series, nil
+}
+
+func (r *mutationResolver) DeleteRecurringSeries(ctx context.Context, seriesID string) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.recurring.DeleteRecurringSeries(ctx, user.ID, seriesID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to delete recurring series")
+		return false, errors.New("failed to delete recurring series")
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RotateCalendarToken(ctx context.Context) (string, error) {
+	user := internal.UserFromContext(ctx)
+
+	user, err := r.user.RotateCalendarToken(ctx, user)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to rotate calendar token")
+		return "", errors.New("failed to rotate calendar token")
+	}
+
+	return user.CalendarToken.String, nil
+}
+
+func (r *mutationResolver) SaveNotificationPreferences(ctx context.Context, input model.NotificationPreferencesInput) (*ledger.NotificationPreferences, error) {
+	user := internal.UserFromContext(ctx)
+
+	preferences, err := r.notification.SaveNotificationPreferences(ctx, &ledger.NotificationPreferences{
+		UserID:          user.ID,
+		NewTransactions: input.NewTransactions,
+		Digest:          ledger.DigestFrequency(strings.ToLower(input.Digest.String())),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("failed to save notification preferences")
+		return nil, errors.New("failed to save notification preferences")
+	}
+
+	return preferences, nil
+}
+
+func (r *mutationResolver) MarkNotificationRead(ctx context.Context, notificationID string) (*ledger.Notification, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.notification.MarkNotificationRead(ctx, user.ID, notificationID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to mark notification read")
+		return nil, errors.New("failed to mark notification read")
+	}
+
+	notification, err := r.notification.Notification(ctx, user.ID, notificationID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch notification")
+		return nil, errors.New("failed to fetch notification")
+	}
+
+	return notification, nil
+}
+
+func (r *mutationResolver) MarkAllRead(ctx context.Context) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.notification.MarkAllNotificationsRead(ctx, user.ID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to mark notifications read")
+		return false, errors.New("failed to mark notifications read")
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) UpdateBaseCurrency(ctx context.Context, currency string) (*ledger.User, error) {
+	user := internal.UserFromContext(ctx)
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return nil, errors.New("currency must not be empty")
+	}
+
+	user.BaseCurrency = currency
+
+	user, err := r.user.UpdateUser(ctx, user.ID, user)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to update base currency")
+		return nil, errors.New("failed to update base currency")
+	}
+
+	return user, nil
+}
+
+func (r *mutationResolver) SaveExchangeRates(ctx context.Context, rates []*ledger.ExchangeRate) (int, error) {
+	user := internal.UserFromContext(ctx)
+	if !user.IsAdmin {
+		return 0, errors.New("only administrators may save exchange rates")
+	}
+
+	err := r.currency.SaveExchangeRates(ctx, rates)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to save exchange rates")
+		return 0, errors.New("failed to save exchange rates")
+	}
+
+	return len(rates), nil
+}
+
+func (r *mutationResolver) CreateWebhookEndpoint(ctx context.Context, input model.WebhookEndpointInput) (*ledger.WebhookEndpoint, error) {
+	user := internal.UserFromContext(ctx)
+
+	endpoint := &ledger.WebhookEndpoint{UserID: user.ID}
+	applyWebhookEndpointInput(endpoint, input)
+
+	endpoint, err := r.webhook.CreateWebhookEndpoint(ctx, endpoint)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to create webhook endpoint")
+		return nil, errors.New("failed to create webhook endpoint")
+	}
+
+	return endpoint, nil
+}
+
+func (r *mutationResolver) UpdateWebhookEndpoint(ctx context.Context, endpointID string, input model.WebhookEndpointInput) (*ledger.WebhookEndpoint, error) {
+	user := internal.UserFromContext(ctx)
+
+	endpoint, err := r.webhook.WebhookEndpoint(ctx, user.ID, endpointID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch webhook endpoint")
+		return nil, errors.New("failed to fetch webhook endpoint")
+	}
+
+	applyWebhookEndpointInput(endpoint, input)
+
+	endpoint, err = r.webhook.UpdateWebhookEndpoint(ctx, endpointID, endpoint)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to update webhook endpoint")
+		return nil, errors.New("failed to update webhook endpoint")
+	}
+
+	endpoint.Secret = ""
+
+	return endpoint, nil
+}
+
+func (r *mutationResolver) RotateWebhookEndpointSecret(ctx context.Context, endpointID string) (*ledger.WebhookEndpoint, error) {
+	user := internal.UserFromContext(ctx)
+
+	endpoint, err := r.webhook.RotateWebhookEndpointSecret(ctx, user.ID, endpointID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to rotate webhook endpoint secret")
+		return nil, errors.New("failed to rotate webhook endpoint secret")
+	}
+
+	return endpoint, nil
+}
+
+func (r *mutationResolver) DeleteWebhookEndpoint(ctx context.Context, endpointID string) (bool, error) {
+	user := internal.UserFromContext(ctx)
+
+	err := r.webhook.DeleteWebhookEndpoint(ctx, user.ID, endpointID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to delete webhook endpoint")
+		return false, errors.New("failed to delete webhook endpoint")
+	}
+
+	return true, nil
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
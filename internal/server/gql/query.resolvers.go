@@ -5,8 +5,11 @@ package resolvers
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/ddouglas/ledger"
@@ -15,18 +18,85 @@ import (
 	"github.com/ddouglas/ledger/internal/server/gql/model"
 	"github.com/gofrs/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/null"
 )
 
+func (r *queryResolver) Me(ctx context.Context) (*ledger.User, error) {
+	return internal.UserFromContext(ctx), nil
+}
+
+func (r *queryResolver) AlertRules(ctx context.Context) ([]*ledger.AlertRule, error) {
+	user := internal.UserFromContext(ctx)
+
+	return r.alert.AlertRules(ctx, user.ID)
+}
+
+func (r *queryResolver) Alerts(ctx context.Context, limit *int) ([]*ledger.Alert, error) {
+	user := internal.UserFromContext(ctx)
+
+	var l uint64 = 50
+	if limit != nil && *limit > 0 && *limit < 500 {
+		l = uint64(*limit)
+	}
+
+	return r.alert.Alerts(ctx, user.ID, l)
+}
+
+func (r *queryResolver) Budgets(ctx context.Context) ([]*ledger.Budget, error) {
+	user := internal.UserFromContext(ctx)
+
+	return r.budget.Budgets(ctx, user.ID)
+}
+
+func (r *queryResolver) BudgetStatus(ctx context.Context, period *time.Time) ([]*ledger.BudgetStatus, error) {
+	user := internal.UserFromContext(ctx)
+
+	date := time.Now()
+	if period != nil {
+		date = *period
+	}
+
+	statuses, err := r.budget.BudgetStatus(ctx, user, date)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch budget status")
+		return nil, errors.New("failed to fetch budget status")
+	}
+
+	return statuses, nil
+}
+
 func (r *queryResolver) Categories(ctx context.Context) ([]*ledger.PlaidCategory, error) {
 	return r.item.PlaidCategories(ctx)
 }
 
+func (r *queryResolver) FlaggedTransactions(ctx context.Context, acknowledged *bool) ([]*ledger.TransactionAnomaly, error) {
+	user := internal.UserFromContext(ctx)
+
+	anomalies, err := r.anomaly.TransactionAnomaliesByUserID(ctx, user.ID, null.BoolFromPtr(acknowledged))
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch flagged transactions")
+		return nil, errors.New("failed to fetch flagged transactions")
+	}
+
+	return anomalies, nil
+}
+
+func (r *queryResolver) ExchangeRates(ctx context.Context, fromCurrency string, toCurrency string) ([]*ledger.ExchangeRate, error) {
+	return r.currency.ExchangeRates(ctx, strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency))
+}
+
 func (r *queryResolver) Items(ctx context.Context) ([]*ledger.Item, error) {
 	user := internal.UserFromContext(ctx)
 
 	return r.item.ItemsByUserID(ctx, user.ID)
 }
 
+func (r *queryResolver) JournalAccountMappings(ctx context.Context) ([]*ledger.JournalAccountMapping, error) {
+	user := internal.UserFromContext(ctx)
+
+	return r.journal.JournalAccountMappings(ctx, user.ID)
+}
+
 func (r *queryResolver) LinkToken(ctx context.Context, state *string) (*ledger.LinkState, error) {
 	user := internal.UserFromContext(ctx)
 
@@ -57,6 +127,140 @@ func (r *queryResolver) Merchant(ctx context.Context, merchantID string) (*ledge
 	return r.transaction.Merchant(ctx, merchantID)
 }
 
+func (r *queryResolver) NotificationPreferences(ctx context.Context) (*ledger.NotificationPreferences, error) {
+	user := internal.UserFromContext(ctx)
+
+	preferences, err := r.notification.NotificationPreferences(ctx, user.ID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch notification preferences")
+		return nil, errors.New("failed to fetch notification preferences")
+	}
+
+	return preferences, nil
+}
+
+func (r *queryResolver) Notifications(ctx context.Context, unread *bool, limit *int) ([]*ledger.Notification, error) {
+	user := internal.UserFromContext(ctx)
+
+	var l uint64 = 50
+	if limit != nil && *limit > 0 && *limit < 500 {
+		l = uint64(*limit)
+	}
+
+	notifications, err := r.notification.Notifications(ctx, user.ID, unread != nil && *unread, l)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch notifications")
+		return nil, errors.New("failed to fetch notifications")
+	}
+
+	return notifications, nil
+}
+
+func (r *queryResolver) UnreadNotificationCount(ctx context.Context) (int, error) {
+	user := internal.UserFromContext(ctx)
+
+	count, err := r.notification.UnreadNotificationCount(ctx, user.ID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to count unread notifications")
+		return 0, errors.New("failed to count unread notifications")
+	}
+
+	return count, nil
+}
+
+func (r *queryResolver) StatementProfile(ctx context.Context, itemID string, accountID string) (*ledger.StatementProfile, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership of item")
+		return nil, errors.New("failed to verify ownership of item")
+	}
+
+	profile, err := r.statement.StatementProfile(ctx, itemID, accountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch statement profile")
+		return nil, errors.New("failed to fetch statement profile")
+	}
+
+	return profile, nil
+}
+
+func (r *queryResolver) Subscriptions(ctx context.Context) ([]*ledger.RecurringSeries, error) {
+	user := internal.UserFromContext(ctx)
+
+	series, err := r.recurring.RecurringSeriesByUserID(ctx, user.ID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch subscriptions")
+		return nil, errors.New("failed to fetch subscriptions")
+	}
+
+	return series, nil
+}
+
+func (r *queryResolver) Upcoming(ctx context.Context, days int) ([]*ledger.ProjectedBalance, error) {
+	user := internal.UserFromContext(ctx)
+
+	projections, err := r.recurring.Upcoming(ctx, user, days)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to project upcoming balances")
+		return nil, errors.New("failed to project upcoming balances")
+	}
+
+	return projections, nil
+}
+
+func (r *queryResolver) Forecast(ctx context.Context, days int) ([]*ledger.AccountForecast, error) {
+	user := internal.UserFromContext(ctx)
+
+	forecasts, err := r.forecast.Forecast(ctx, user, days)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to forecast balances")
+		return nil, errors.New("failed to forecast balances")
+	}
+
+	return forecasts, nil
+}
+
+func (r *queryResolver) Spending(ctx context.Context, groupBy model.SpendingGroupBy, filters *model.TransactionFilter) ([]*ledger.SpendingGroup, error) {
+	user := internal.UserFromContext(ctx)
+
+	groups, err := r.report.Spending(ctx, user, ledger.SpendingGroupBy(strings.ToLower(groupBy.String())), buildTransactionFilters(filters))
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch spending")
+		return nil, errors.New("failed to fetch spending")
+	}
+
+	return groups, nil
+}
+
+func (r *queryResolver) CashFlow(ctx context.Context, interval model.Interval, from time.Time, to time.Time, accountIDs []string) ([]*ledger.CashFlowPeriod, error) {
+	user := internal.UserFromContext(ctx)
+
+	periods, err := r.report.CashFlow(ctx, user, ledger.Interval(strings.ToLower(interval.String())), from, to, accountIDs)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch cash flow")
+		return nil, errors.New("failed to fetch cash flow")
+	}
+
+	return periods, nil
+}
+
+func (r *queryResolver) NetWorth(ctx context.Context, from time.Time, to time.Time, interval model.Interval) ([]*ledger.NetWorthPeriod, error) {
+	user := internal.UserFromContext(ctx)
+
+	periods, err := r.report.NetWorth(ctx, user, ledger.Interval(strings.ToLower(interval.String())), from, to)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch net worth")
+		return nil, errors.New("failed to fetch net worth")
+	}
+
+	return periods, nil
+}
+
 func (r *queryResolver) TransactionsPaginated(ctx context.Context, itemID string, accountID string, filters *model.TransactionFilter) (*ledger.PaginatedTransactions, error) {
 	user := internal.UserFromContext(ctx)
 
@@ -138,6 +342,47 @@ func (r *queryResolver) TransactionReceipt(ctx context.Context, itemID string, t
 	return presigned, nil
 }
 
+func (r *queryResolver) TransactionAttachments(ctx context.Context, itemID string, transactionID string) ([]*ledger.TransactionAttachment, error) {
+	user := internal.UserFromContext(ctx)
+
+	_, err := r.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to verify ownership")
+		return nil, errors.New("failed to verify ownership")
+	}
+
+	return r.transaction.TransactionAttachments(ctx, itemID, transactionID)
+}
+
+func (r *queryResolver) UnmatchedReceipts(ctx context.Context) ([]*ledger.Receipt, error) {
+	user := internal.UserFromContext(ctx)
+
+	return r.transaction.UnmatchedReceipts(ctx, user.ID)
+}
+
+func (r *queryResolver) SearchReceipts(ctx context.Context, term string) ([]*ledger.Receipt, error) {
+	user := internal.UserFromContext(ctx)
+
+	return r.transaction.SearchReceipts(ctx, user.ID, term)
+}
+
+func (r *queryResolver) WebhookEndpoints(ctx context.Context) ([]*ledger.WebhookEndpoint, error) {
+	user := internal.UserFromContext(ctx)
+
+	endpoints, err := r.webhook.WebhookEndpoints(ctx, user.ID)
+	if err != nil {
+		r.logger.WithError(err).Error("failed to fetch webhook endpoints")
+		return nil, errors.New("failed to fetch webhook endpoints")
+	}
+
+	// The secret is only shown when it is created or rotated
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+
+	return endpoints, nil
+}
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
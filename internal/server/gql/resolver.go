@@ -1,15 +1,34 @@
 package resolvers
 
 import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal"
 	"github.com/ddouglas/ledger/internal/account"
+	"github.com/ddouglas/ledger/internal/alert"
+	"github.com/ddouglas/ledger/internal/anomaly"
+	"github.com/ddouglas/ledger/internal/budget"
+	"github.com/ddouglas/ledger/internal/currency"
+	"github.com/ddouglas/ledger/internal/export"
+	"github.com/ddouglas/ledger/internal/forecast"
 	"github.com/ddouglas/ledger/internal/gateway"
 	"github.com/ddouglas/ledger/internal/item"
+	"github.com/ddouglas/ledger/internal/journal"
+	"github.com/ddouglas/ledger/internal/notification"
+	"github.com/ddouglas/ledger/internal/recurring"
+	"github.com/ddouglas/ledger/internal/report"
 	"github.com/ddouglas/ledger/internal/server/gql/dataloaders"
 	"github.com/ddouglas/ledger/internal/server/gql/model"
+	"github.com/ddouglas/ledger/internal/statement"
 	"github.com/ddouglas/ledger/internal/transaction"
+	"github.com/ddouglas/ledger/internal/user"
+	"github.com/ddouglas/ledger/internal/webhook"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/volatiletech/null"
 )
@@ -21,30 +40,69 @@ import (
 type Resolver struct {
 	logger *logrus.Logger
 
-	account     account.Service
-	loaders     dataloaders.Service
-	gateway     gateway.Service
-	item        item.Service
-	transaction transaction.Service
+	account      account.Service
+	alert        alert.Service
+	anomaly      anomaly.Service
+	budget       budget.Service
+	currency     currency.Service
+	journal      journal.Service
+	loaders      dataloaders.Service
+	export       export.Service
+	forecast     forecast.Service
+	gateway      gateway.Service
+	item         item.Service
+	notification notification.Service
+	recurring    recurring.Service
+	report       report.Service
+	statement    statement.Service
+	transaction  transaction.Service
+	user         user.Service
+	webhook      webhook.Service
 }
 
 func New(
 	logger *logrus.Logger,
 
 	account account.Service,
+	alert alert.Service,
+	anomaly anomaly.Service,
+	budget budget.Service,
+	currency currency.Service,
+	export export.Service,
+	forecast forecast.Service,
 	gateway gateway.Service,
 	item item.Service,
+	journal journal.Service,
 	loaders dataloaders.Service,
+	notification notification.Service,
+	recurring recurring.Service,
+	report report.Service,
+	statement statement.Service,
 	transaction transaction.Service,
+	user user.Service,
+	webhook webhook.Service,
 ) *Resolver {
 	return &Resolver{
 		logger: logger,
 
-		account:     account,
-		gateway:     gateway,
-		item:        item,
-		loaders:     loaders,
-		transaction: transaction,
+		account:      account,
+		alert:        alert,
+		anomaly:      anomaly,
+		budget:       budget,
+		currency:     currency,
+		export:       export,
+		forecast:     forecast,
+		gateway:      gateway,
+		item:         item,
+		journal:      journal,
+		loaders:      loaders,
+		notification: notification,
+		recurring:    recurring,
+		report:       report,
+		statement:    statement,
+		transaction:  transaction,
+		user:         user,
+		webhook:      webhook,
 	}
 }
 
@@ -76,6 +134,7 @@ func buildTransactionFilters(f *model.TransactionFilter) *ledger.TransactionFilt
 		}
 	}
 	t.DateInclusive = null.BoolFromPtr(f.DateInclusive)
+	t.Hidden = null.BoolFromPtr(f.Hidden)
 	if f.TransactionType != nil {
 		if *f.TransactionType == model.TransactionTypeExpenses {
 			t.AmountDir = null.Float64From(-1)
@@ -86,3 +145,126 @@ func buildTransactionFilters(f *model.TransactionFilter) *ledger.TransactionFilt
 
 	return t
 }
+
+// applyAlertRuleInput copies the fields of an alert rule input onto rule. Rules are enabled unless
+// the input says otherwise
+func applyAlertRuleInput(rule *ledger.AlertRule, input model.AlertRuleInput) {
+	rule.Name = input.Name
+	rule.Type = ledger.AlertRuleType(strings.ToLower(input.Type.String()))
+	rule.Threshold = math.Round(float64(input.Threshold)*100) / 100
+	rule.ItemID = null.StringFromPtr(input.ItemID)
+	rule.AccountID = null.StringFromPtr(input.AccountID)
+	rule.BudgetID = null.StringFromPtr(input.BudgetID)
+	rule.CooldownMinutes = 0
+	if input.CooldownMinutes != nil {
+		rule.CooldownMinutes = *input.CooldownMinutes
+	}
+	rule.Enabled = input.Enabled == nil || *input.Enabled
+}
+
+// applyWebhookEndpointInput copies the fields of a webhook endpoint input onto endpoint. Endpoints are
+// enabled unless the input says otherwise
+func applyWebhookEndpointInput(endpoint *ledger.WebhookEndpoint, input model.WebhookEndpointInput) {
+	endpoint.URL = input.URL
+	endpoint.Description = null.StringFromPtr(input.Description)
+	endpoint.EventTypes = make(ledger.EventTypes, 0, len(input.EventTypes))
+	for _, eventType := range input.EventTypes {
+		endpoint.EventTypes = append(endpoint.EventTypes, ledger.EventType(strings.ToLower(strings.Replace(eventType.String(), "_", ".", 1))))
+	}
+	endpoint.Enabled = input.Enabled == nil || *input.Enabled
+}
+
+// eventTypeEnum converts an event type, such as transaction.created, to its enum value, TRANSACTION_CREATED
+func eventTypeEnum(eventType ledger.EventType) string {
+	return strings.ToUpper(strings.Replace(string(eventType), ".", "_", 1))
+}
+
+// applyBudgetInput copies the fields of a budget input onto budget
+func applyBudgetInput(budget *ledger.Budget, input model.BudgetInput) {
+	budget.Name = input.Name
+	budget.Scope = ledger.BudgetScope(strings.ToLower(input.Scope.String()))
+	budget.ScopeID = input.ScopeID
+	budget.Period = ledger.Interval(strings.ToLower(input.Period.String()))
+	// Float arguments are decoded as float32, round away the noise from widening them
+	budget.Amount = math.Round(float64(input.Amount)*100) / 100
+	budget.Rollover = input.Rollover != nil && *input.Rollover
+	if input.StartDate != nil {
+		budget.StartDate = *input.StartDate
+	}
+}
+
+// applyRecurringSeriesInput copies the fields of a recurring series input onto series
+func applyRecurringSeriesInput(series *ledger.RecurringSeries, input model.RecurringSeriesInput) {
+	series.Name = input.Name
+	series.Kind = ledger.RecurringKind(strings.ToLower(input.Kind.String()))
+	series.Frequency = ledger.RecurringFrequency(strings.ToLower(input.Frequency.String()))
+	series.Amount = math.Round(float64(input.Amount)*100) / 100
+	series.CurrencyCode = ""
+	if input.CurrencyCode != nil {
+		series.CurrencyCode = strings.ToUpper(strings.TrimSpace(*input.CurrencyCode))
+	}
+	series.ItemID = null.StringFrom(input.ItemID)
+	series.AccountID = null.StringFrom(input.AccountID)
+	series.NextDate = input.NextDate
+}
+
+// applyStatementProfileInput copies the fields of a statement profile input onto profile
+func applyStatementProfileInput(profile *ledger.StatementProfile, input model.StatementProfileInput) {
+	profile.Delimiter = ""
+	if input.Delimiter != nil {
+		profile.Delimiter = *input.Delimiter
+	}
+	profile.SkipRows = 0
+	if input.SkipRows != nil {
+		profile.SkipRows = *input.SkipRows
+	}
+	profile.DateColumn = input.DateColumn
+	profile.DateFormat = ""
+	if input.DateFormat != nil {
+		profile.DateFormat = ledger.ExportDateFormat(strings.ToLower(input.DateFormat.String()))
+	}
+	profile.DescriptionColumn = input.DescriptionColumn
+	profile.AmountColumn = null.IntFromPtr(input.AmountColumn)
+	profile.DebitColumn = null.IntFromPtr(input.DebitColumn)
+	profile.CreditColumn = null.IntFromPtr(input.CreditColumn)
+	profile.IDColumn = null.IntFromPtr(input.IDColumn)
+	profile.InvertAmounts = input.InvertAmounts != nil && *input.InvertAmounts
+}
+
+// changeValueString renders a value captured in a transaction changelog as a
+// JSON string so that it can be returned to the client regardless of its type
+func changeValueString(v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	str := string(data)
+	return &str
+}
+
+// convertToBaseCurrency converts amount into the base currency of the requesting user
+// using the exchange rate for date. Nil is returned when no rate has been loaded for the pair
+func (r *Resolver) convertToBaseCurrency(ctx context.Context, amount float64, currencyCode string, date time.Time) (*float32, error) {
+	user := internal.UserFromContext(ctx)
+
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	converted, err := r.currency.Convert(ctx, amount, currencyCode, user.BaseCurrency, date)
+	if errors.Is(err, currency.ErrExchangeRateNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("failed to convert amount to base currency")
+		return nil, errors.New("failed to convert amount to base currency")
+	}
+
+	f := float32(converted)
+	return &f, nil
+}
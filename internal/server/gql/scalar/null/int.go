@@ -0,0 +1,34 @@
+package null
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/volatiletech/null"
+)
+
+func MarshalInt(ni null.Int) graphql.Marshaler {
+	if !ni.Valid {
+		return graphql.Null
+	}
+
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Itoa(ni.Int))
+	})
+}
+
+func UnmarshalInt(i interface{}) (null.Int, error) {
+
+	if i == nil {
+		return null.Int{}, nil
+	}
+
+	value, err := graphql.UnmarshalInt(i)
+	if err != nil {
+		return null.Int{}, err
+	}
+
+	return null.IntFrom(value), nil
+
+}
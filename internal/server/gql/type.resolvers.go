@@ -5,11 +5,94 @@ package resolvers
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/ddouglas/ledger"
 	"github.com/ddouglas/ledger/internal/server/gql/generated"
+	"github.com/ddouglas/ledger/internal/server/gql/model"
 )
 
+func (r *accountResolver) BalanceHistory(ctx context.Context, obj *ledger.Account, from *time.Time, to *time.Time) ([]*ledger.AccountBalanceSnapshot, error) {
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+
+	start := end.AddDate(0, 0, -90)
+	if from != nil {
+		start = *from
+	}
+
+	return r.account.AccountBalanceSnapshots(ctx, obj.ItemID, obj.AccountID, start, end)
+}
+
+func (r *accountBalanceResolver) ConvertedAvailable(ctx context.Context, obj *ledger.AccountBalance) (*float32, error) {
+	return r.convertToBaseCurrency(ctx, obj.Available, obj.CurrencyCode(), obj.LastUpdated.Time)
+}
+
+func (r *accountBalanceResolver) ConvertedCurrent(ctx context.Context, obj *ledger.AccountBalance) (*float32, error) {
+	return r.convertToBaseCurrency(ctx, obj.Current, obj.CurrencyCode(), obj.LastUpdated.Time)
+}
+
+func (r *accountForecastResolver) Days(ctx context.Context, obj *ledger.AccountForecast) ([]*ledger.ForecastDay, error) {
+	return obj.Days, nil
+}
+
+func (r *accountForecastResolver) Categories(ctx context.Context, obj *ledger.AccountForecast) ([]*ledger.ForecastCategory, error) {
+	return obj.Categories, nil
+}
+
+func (r *accountForecastResolver) Account(ctx context.Context, obj *ledger.AccountForecast) (*ledger.Account, error) {
+	return r.account.Account(ctx, obj.ItemID, obj.AccountID)
+}
+
+func (r *alertResolver) Type(ctx context.Context, obj *ledger.Alert) (model.AlertRuleType, error) {
+	return model.AlertRuleType(strings.ToUpper(string(obj.Type))), nil
+}
+
+func (r *alertRuleResolver) Type(ctx context.Context, obj *ledger.AlertRule) (model.AlertRuleType, error) {
+	return model.AlertRuleType(strings.ToUpper(string(obj.Type))), nil
+}
+
+func (r *anomalyReasonResolver) Type(ctx context.Context, obj *ledger.AnomalyReason) (model.AnomalyReasonType, error) {
+	return model.AnomalyReasonType(strings.ToUpper(string(obj.Type))), nil
+}
+
+func (r *budgetResolver) Scope(ctx context.Context, obj *ledger.Budget) (model.BudgetScope, error) {
+	return model.BudgetScope(strings.ToUpper(string(obj.Scope))), nil
+}
+
+func (r *budgetResolver) Period(ctx context.Context, obj *ledger.Budget) (model.Interval, error) {
+	return model.Interval(strings.ToUpper(string(obj.Period))), nil
+}
+
+func (r *budgetResolver) Category(ctx context.Context, obj *ledger.Budget) (*ledger.PlaidCategory, error) {
+	if obj.Scope != ledger.BudgetScopeCategory {
+		return nil, nil
+	}
+
+	return r.loaders.CategoryLoader().Load(ctx, obj.ScopeID)
+}
+
+func (r *budgetResolver) Merchant(ctx context.Context, obj *ledger.Budget) (*ledger.Merchant, error) {
+	if obj.Scope != ledger.BudgetScopeMerchant {
+		return nil, nil
+	}
+
+	return r.loaders.MerchantLoader().Load(ctx, obj.ScopeID)
+}
+
+func (r *forecastCategoryResolver) Category(ctx context.Context, obj *ledger.ForecastCategory) (*ledger.PlaidCategory, error) {
+	if !obj.CategoryID.Valid {
+		return nil, nil
+	}
+
+	return r.loaders.CategoryLoader().Load(ctx, obj.CategoryID.String)
+}
+
 func (r *itemResolver) AvailbleProducts(ctx context.Context, obj *ledger.Item) ([]string, error) {
 	return []string(obj.AvailableProducts), nil
 }
@@ -34,6 +117,10 @@ func (r *itemResolver) Accounts(ctx context.Context, obj *ledger.Item) ([]*ledge
 	return r.loaders.AccountsByItemIDLoader().Load(ctx, obj.ItemID)
 }
 
+func (r *journalAccountMappingResolver) SourceType(ctx context.Context, obj *ledger.JournalAccountMapping) (model.JournalSourceType, error) {
+	return model.JournalSourceType(strings.ToUpper(string(obj.SourceType))), nil
+}
+
 func (r *linkStateResolver) State(ctx context.Context, obj *ledger.LinkState) (string, error) {
 	return obj.State.String(), nil
 }
@@ -42,10 +129,111 @@ func (r *merchantResolver) Aliases(ctx context.Context, obj *ledger.Merchant) ([
 	return r.loaders.MerchantAliasLoader().Load(ctx, obj.ID)
 }
 
+func (r *notificationResolver) Type(ctx context.Context, obj *ledger.Notification) (model.NotificationType, error) {
+	return model.NotificationType(eventTypeEnum(obj.Type)), nil
+}
+
+func (r *notificationResolver) Read(ctx context.Context, obj *ledger.Notification) (bool, error) {
+	return obj.ReadAt.Valid, nil
+}
+
+func (r *notificationPreferencesResolver) Digest(ctx context.Context, obj *ledger.NotificationPreferences) (model.DigestFrequency, error) {
+	return model.DigestFrequency(strings.ToUpper(string(obj.Digest))), nil
+}
+
 func (r *plaidCategoryResolver) Hierarchy(ctx context.Context, obj *ledger.PlaidCategory) ([]string, error) {
 	return []string(obj.Hierarchy), nil
 }
 
+func (r *projectedBalanceResolver) Account(ctx context.Context, obj *ledger.ProjectedBalance) (*ledger.Account, error) {
+	return r.account.Account(ctx, obj.ItemID, obj.AccountID)
+}
+
+func (r *receiptResolver) URL(ctx context.Context, obj *ledger.Receipt) (string, error) {
+	return r.transaction.ReceiptURL(ctx, obj)
+}
+
+func (r *receiptResolver) ThumbnailURL(ctx context.Context, obj *ledger.Receipt) (*string, error) {
+	url, err := r.transaction.ReceiptThumbnailURL(ctx, obj)
+	return url.Ptr(), err
+}
+
+func (r *receiptResolver) Transaction(ctx context.Context, obj *ledger.Receipt) (*ledger.Transaction, error) {
+	if !obj.ItemID.Valid || !obj.TransactionID.Valid {
+		return nil, nil
+	}
+
+	return r.transaction.Transaction(ctx, obj.ItemID.String, obj.TransactionID.String)
+}
+
+func (r *receiptResolver) SuggestedTransactions(ctx context.Context, obj *ledger.Receipt) ([]*ledger.Transaction, error) {
+	return r.transaction.ReceiptSuggestions(ctx, obj)
+}
+
+func (r *recurringSeriesResolver) Kind(ctx context.Context, obj *ledger.RecurringSeries) (model.RecurringKind, error) {
+	return model.RecurringKind(strings.ToUpper(string(obj.Kind))), nil
+}
+
+func (r *recurringSeriesResolver) Source(ctx context.Context, obj *ledger.RecurringSeries) (model.RecurringSource, error) {
+	return model.RecurringSource(strings.ToUpper(string(obj.Source))), nil
+}
+
+func (r *recurringSeriesResolver) Frequency(ctx context.Context, obj *ledger.RecurringSeries) (model.RecurringFrequency, error) {
+	return model.RecurringFrequency(strings.ToUpper(string(obj.Frequency))), nil
+}
+
+func (r *recurringSeriesResolver) Status(ctx context.Context, obj *ledger.RecurringSeries) (model.RecurringStatus, error) {
+	return model.RecurringStatus(strings.ToUpper(string(obj.Status))), nil
+}
+
+func (r *recurringSeriesResolver) Account(ctx context.Context, obj *ledger.RecurringSeries) (*ledger.Account, error) {
+	if !obj.ItemID.Valid || !obj.AccountID.Valid {
+		return nil, nil
+	}
+
+	return r.account.Account(ctx, obj.ItemID.String, obj.AccountID.String)
+}
+
+func (r *recurringSeriesResolver) Merchant(ctx context.Context, obj *ledger.RecurringSeries) (*ledger.Merchant, error) {
+	if !obj.MerchantID.Valid {
+		return nil, nil
+	}
+
+	return r.loaders.MerchantLoader().Load(ctx, obj.MerchantID.String)
+}
+
+func (r *spendingGroupResolver) Category(ctx context.Context, obj *ledger.SpendingGroup) (*ledger.PlaidCategory, error) {
+	if obj.GroupBy != ledger.SpendingGroupByCategory || !obj.Key.Valid {
+		return nil, nil
+	}
+
+	return r.loaders.CategoryLoader().Load(ctx, obj.Key.String)
+}
+
+func (r *spendingGroupResolver) Merchant(ctx context.Context, obj *ledger.SpendingGroup) (*ledger.Merchant, error) {
+	if obj.GroupBy != ledger.SpendingGroupByMerchant || !obj.Key.Valid {
+		return nil, nil
+	}
+
+	return r.loaders.MerchantLoader().Load(ctx, obj.Key.String)
+}
+
+func (r *spendingGroupResolver) Account(ctx context.Context, obj *ledger.SpendingGroup) (*ledger.Account, error) {
+	if obj.GroupBy != ledger.SpendingGroupByAccount || !obj.Key.Valid {
+		return nil, nil
+	}
+
+	return r.account.Account(ctx, obj.ItemID.String, obj.Key.String)
+}
+
+func (r *statementProfileResolver) DateFormat(ctx context.Context, obj *ledger.StatementProfile) (model.ExportDateFormat, error) {
+	return model.ExportDateFormat(strings.ToUpper(string(obj.DateFormat))), nil
+}
+
+func (r *transactionResolver) ConvertedAmount(ctx context.Context, obj *ledger.Transaction) (*float32, error) {
+	return r.convertToBaseCurrency(ctx, obj.Amount, obj.CurrencyCode(), obj.Date)
+}
+
 func (r *transactionResolver) Category(ctx context.Context, obj *ledger.Transaction) (*ledger.PlaidCategory, error) {
 	if !obj.CategoryID.Valid {
 		return nil, nil
@@ -58,23 +246,268 @@ func (r *transactionResolver) Merchant(ctx context.Context, obj *ledger.Transact
 	return r.loaders.MerchantLoader().Load(ctx, obj.MerchantID)
 }
 
+func (r *transactionResolver) History(ctx context.Context, obj *ledger.Transaction) ([]*ledger.TransactionChangelog, error) {
+	return r.transaction.TransactionChangelogs(ctx, obj.ItemID, obj.TransactionID)
+}
+
+func (r *transactionResolver) Relations(ctx context.Context, obj *ledger.Transaction) ([]*ledger.TransactionRelation, error) {
+	return r.transaction.TransactionRelations(ctx, obj.ItemID, obj.TransactionID)
+}
+
+func (r *transactionResolver) Attachments(ctx context.Context, obj *ledger.Transaction) ([]*ledger.TransactionAttachment, error) {
+	return r.transaction.TransactionAttachments(ctx, obj.ItemID, obj.TransactionID)
+}
+
+func (r *transactionResolver) ReceiptDetails(ctx context.Context, obj *ledger.Transaction) (*ledger.Receipt, error) {
+	if !obj.HasReceipt {
+		return nil, nil
+	}
+
+	receipt, err := r.transaction.ReceiptByTransactionID(ctx, obj.ItemID, obj.TransactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return receipt, err
+}
+
+func (r *transactionResolver) NetAmount(ctx context.Context, obj *ledger.Transaction) (float32, error) {
+	amount, err := r.transaction.TransactionNetAmount(ctx, obj)
+	return float32(amount), err
+}
+
+func (r *transactionAnomalyResolver) Reasons(ctx context.Context, obj *ledger.TransactionAnomaly) ([]*ledger.AnomalyReason, error) {
+	return obj.Reasons, nil
+}
+
+func (r *transactionAnomalyResolver) Transaction(ctx context.Context, obj *ledger.TransactionAnomaly) (*ledger.Transaction, error) {
+	return r.transaction.Transaction(ctx, obj.ItemID, obj.TransactionID)
+}
+
+func (r *transactionAttachmentResolver) URL(ctx context.Context, obj *ledger.TransactionAttachment) (string, error) {
+	return r.transaction.TransactionAttachmentURL(ctx, obj)
+}
+
+func (r *transactionAttachmentResolver) ThumbnailURL(ctx context.Context, obj *ledger.TransactionAttachment) (*string, error) {
+	url, err := r.transaction.TransactionAttachmentThumbnailURL(ctx, obj)
+	return url.Ptr(), err
+}
+
+func (r *transactionChangelogResolver) Source(ctx context.Context, obj *ledger.TransactionChangelog) (string, error) {
+	return string(obj.Source), nil
+}
+
+func (r *transactionChangelogResolver) Changes(ctx context.Context, obj *ledger.TransactionChangelog) ([]*model.TransactionChange, error) {
+	var changes = make([]*model.TransactionChange, 0, len(obj.Changelog))
+	for _, change := range obj.Changelog {
+		changes = append(changes, &model.TransactionChange{
+			Type:  change.Type,
+			Field: strings.Join(change.Path, "."),
+			From:  changeValueString(change.From),
+			To:    changeValueString(change.To),
+		})
+	}
+
+	return changes, nil
+}
+
+func (r *transactionExportResolver) URL(ctx context.Context, obj *ledger.TransactionExport) (string, error) {
+	return "/exports/transactions.csv?token=" + obj.Token, nil
+}
+
+func (r *transactionRelationResolver) Type(ctx context.Context, obj *ledger.TransactionRelation) (string, error) {
+	return string(obj.Type), nil
+}
+
+func (r *transactionRelationResolver) Status(ctx context.Context, obj *ledger.TransactionRelation) (string, error) {
+	return string(obj.Status), nil
+}
+
+func (r *transactionRelationResolver) Transaction(ctx context.Context, obj *ledger.TransactionRelation) (*ledger.Transaction, error) {
+	return r.transaction.Transaction(ctx, obj.ItemID, obj.TransactionID)
+}
+
+func (r *transactionRelationResolver) RelatedTransaction(ctx context.Context, obj *ledger.TransactionRelation) (*ledger.Transaction, error) {
+	return r.transaction.Transaction(ctx, obj.ItemID, obj.RelatedTransactionID)
+}
+
+func (r *userResolver) ID(ctx context.Context, obj *ledger.User) (string, error) {
+	return obj.ID.String(), nil
+}
+
+func (r *webhookDeliveryResolver) EventType(ctx context.Context, obj *ledger.WebhookDelivery) (model.WebhookEventType, error) {
+	return model.WebhookEventType(eventTypeEnum(obj.EventType)), nil
+}
+
+func (r *webhookEndpointResolver) Secret(ctx context.Context, obj *ledger.WebhookEndpoint) (*string, error) {
+	if obj.Secret == "" {
+		return nil, nil
+	}
+
+	return &obj.Secret, nil
+}
+
+func (r *webhookEndpointResolver) EventTypes(ctx context.Context, obj *ledger.WebhookEndpoint) ([]model.WebhookEventType, error) {
+	var eventTypes = make([]model.WebhookEventType, 0, len(obj.EventTypes))
+	for _, eventType := range obj.EventTypes {
+		eventTypes = append(eventTypes, model.WebhookEventType(eventTypeEnum(eventType)))
+	}
+
+	return eventTypes, nil
+}
+
+func (r *webhookEndpointResolver) Deliveries(ctx context.Context, obj *ledger.WebhookEndpoint, limit *int) ([]*ledger.WebhookDelivery, error) {
+	var l uint64 = 50
+	if limit != nil && *limit > 0 && *limit < 500 {
+		l = uint64(*limit)
+	}
+
+	return r.webhook.WebhookDeliveries(ctx, obj.UserID, obj.EndpointID, l)
+}
+
+// Account returns generated.AccountResolver implementation.
+func (r *Resolver) Account() generated.AccountResolver { return &accountResolver{r} }
+
+// AccountBalance returns generated.AccountBalanceResolver implementation.
+func (r *Resolver) AccountBalance() generated.AccountBalanceResolver {
+	return &accountBalanceResolver{r}
+}
+
+// AccountForecast returns generated.AccountForecastResolver implementation.
+func (r *Resolver) AccountForecast() generated.AccountForecastResolver {
+	return &accountForecastResolver{r}
+}
+
+// Alert returns generated.AlertResolver implementation.
+func (r *Resolver) Alert() generated.AlertResolver { return &alertResolver{r} }
+
+// AlertRule returns generated.AlertRuleResolver implementation.
+func (r *Resolver) AlertRule() generated.AlertRuleResolver { return &alertRuleResolver{r} }
+
+// AnomalyReason returns generated.AnomalyReasonResolver implementation.
+func (r *Resolver) AnomalyReason() generated.AnomalyReasonResolver { return &anomalyReasonResolver{r} }
+
+// Budget returns generated.BudgetResolver implementation.
+func (r *Resolver) Budget() generated.BudgetResolver { return &budgetResolver{r} }
+
+// ForecastCategory returns generated.ForecastCategoryResolver implementation.
+func (r *Resolver) ForecastCategory() generated.ForecastCategoryResolver {
+	return &forecastCategoryResolver{r}
+}
+
 // Item returns generated.ItemResolver implementation.
 func (r *Resolver) Item() generated.ItemResolver { return &itemResolver{r} }
 
+// JournalAccountMapping returns generated.JournalAccountMappingResolver implementation.
+func (r *Resolver) JournalAccountMapping() generated.JournalAccountMappingResolver {
+	return &journalAccountMappingResolver{r}
+}
+
 // LinkState returns generated.LinkStateResolver implementation.
 func (r *Resolver) LinkState() generated.LinkStateResolver { return &linkStateResolver{r} }
 
 // Merchant returns generated.MerchantResolver implementation.
 func (r *Resolver) Merchant() generated.MerchantResolver { return &merchantResolver{r} }
 
+// Notification returns generated.NotificationResolver implementation.
+func (r *Resolver) Notification() generated.NotificationResolver { return &notificationResolver{r} }
+
+// NotificationPreferences returns generated.NotificationPreferencesResolver implementation.
+func (r *Resolver) NotificationPreferences() generated.NotificationPreferencesResolver {
+	return &notificationPreferencesResolver{r}
+}
+
 // PlaidCategory returns generated.PlaidCategoryResolver implementation.
 func (r *Resolver) PlaidCategory() generated.PlaidCategoryResolver { return &plaidCategoryResolver{r} }
 
+// ProjectedBalance returns generated.ProjectedBalanceResolver implementation.
+func (r *Resolver) ProjectedBalance() generated.ProjectedBalanceResolver {
+	return &projectedBalanceResolver{r}
+}
+
+// Receipt returns generated.ReceiptResolver implementation.
+func (r *Resolver) Receipt() generated.ReceiptResolver { return &receiptResolver{r} }
+
+// RecurringSeries returns generated.RecurringSeriesResolver implementation.
+func (r *Resolver) RecurringSeries() generated.RecurringSeriesResolver {
+	return &recurringSeriesResolver{r}
+}
+
+// SpendingGroup returns generated.SpendingGroupResolver implementation.
+func (r *Resolver) SpendingGroup() generated.SpendingGroupResolver { return &spendingGroupResolver{r} }
+
+// StatementProfile returns generated.StatementProfileResolver implementation.
+func (r *Resolver) StatementProfile() generated.StatementProfileResolver {
+	return &statementProfileResolver{r}
+}
+
 // Transaction returns generated.TransactionResolver implementation.
 func (r *Resolver) Transaction() generated.TransactionResolver { return &transactionResolver{r} }
 
+// TransactionAnomaly returns generated.TransactionAnomalyResolver implementation.
+func (r *Resolver) TransactionAnomaly() generated.TransactionAnomalyResolver {
+	return &transactionAnomalyResolver{r}
+}
+
+// TransactionAttachment returns generated.TransactionAttachmentResolver implementation.
+func (r *Resolver) TransactionAttachment() generated.TransactionAttachmentResolver {
+	return &transactionAttachmentResolver{r}
+}
+
+// TransactionChangelog returns generated.TransactionChangelogResolver implementation.
+func (r *Resolver) TransactionChangelog() generated.TransactionChangelogResolver {
+	return &transactionChangelogResolver{r}
+}
+
+// TransactionExport returns generated.TransactionExportResolver implementation.
+func (r *Resolver) TransactionExport() generated.TransactionExportResolver {
+	return &transactionExportResolver{r}
+}
+
+// TransactionRelation returns generated.TransactionRelationResolver implementation.
+func (r *Resolver) TransactionRelation() generated.TransactionRelationResolver {
+	return &transactionRelationResolver{r}
+}
+
+// User returns generated.UserResolver implementation.
+func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
+
+// WebhookDelivery returns generated.WebhookDeliveryResolver implementation.
+func (r *Resolver) WebhookDelivery() generated.WebhookDeliveryResolver {
+	return &webhookDeliveryResolver{r}
+}
+
+// WebhookEndpoint returns generated.WebhookEndpointResolver implementation.
+func (r *Resolver) WebhookEndpoint() generated.WebhookEndpointResolver {
+	return &webhookEndpointResolver{r}
+}
+
+type accountResolver struct{ *Resolver }
+type accountBalanceResolver struct{ *Resolver }
+type accountForecastResolver struct{ *Resolver }
+type alertResolver struct{ *Resolver }
+type alertRuleResolver struct{ *Resolver }
+type anomalyReasonResolver struct{ *Resolver }
+type budgetResolver struct{ *Resolver }
+type forecastCategoryResolver struct{ *Resolver }
 type itemResolver struct{ *Resolver }
+type journalAccountMappingResolver struct{ *Resolver }
 type linkStateResolver struct{ *Resolver }
 type merchantResolver struct{ *Resolver }
+type notificationResolver struct{ *Resolver }
+type notificationPreferencesResolver struct{ *Resolver }
 type plaidCategoryResolver struct{ *Resolver }
+type projectedBalanceResolver struct{ *Resolver }
+type receiptResolver struct{ *Resolver }
+type recurringSeriesResolver struct{ *Resolver }
+type spendingGroupResolver struct{ *Resolver }
+type statementProfileResolver struct{ *Resolver }
 type transactionResolver struct{ *Resolver }
+type transactionAnomalyResolver struct{ *Resolver }
+type transactionAttachmentResolver struct{ *Resolver }
+type transactionChangelogResolver struct{ *Resolver }
+type transactionExportResolver struct{ *Resolver }
+type transactionRelationResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
+type webhookDeliveryResolver struct{ *Resolver }
+type webhookEndpointResolver struct{ *Resolver }
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal"
+	"github.com/pkg/errors"
+)
+
+// handleGetJournalExport streams the transactions of the user as a ledger, hledger or beancount
+// journal. The transactions are filtered with the same query string as the CSV export
+func (s *server) handleGetJournalExport(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	values := r.URL.Query()
+
+	format := ledger.JournalFormat(values.Get("format"))
+	if !format.Valid() {
+		s.writeError(ctx, w, http.StatusBadRequest, errors.New("format must be one of ledger, hledger or beancount"))
+		return
+	}
+
+	var filters = new(ledger.TransactionFilter)
+	err := filters.BuildFromURLValues(values)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("content-type", "text/plain; charset=utf-8")
+	w.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="transactions.%s"`, format.Extension()))
+
+	// Transactions are streamed, so by the time an error occurs the response has already been started
+	err = s.journal.WriteJournal(ctx, w, user.ID, format, filters)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error("failed to write journal export")
+	}
+
+}
@@ -11,16 +11,29 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/ddouglas/ledger"
 	"github.com/ddouglas/ledger/internal/account"
+	"github.com/ddouglas/ledger/internal/alert"
+	"github.com/ddouglas/ledger/internal/anomaly"
 	"github.com/ddouglas/ledger/internal/auth"
+	"github.com/ddouglas/ledger/internal/budget"
+	"github.com/ddouglas/ledger/internal/currency"
+	"github.com/ddouglas/ledger/internal/export"
+	"github.com/ddouglas/ledger/internal/forecast"
 	"github.com/ddouglas/ledger/internal/gateway"
 	"github.com/ddouglas/ledger/internal/importer"
 	"github.com/ddouglas/ledger/internal/item"
+	"github.com/ddouglas/ledger/internal/journal"
+	"github.com/ddouglas/ledger/internal/notification"
+	"github.com/ddouglas/ledger/internal/recurring"
+	"github.com/ddouglas/ledger/internal/report"
 	resolvers "github.com/ddouglas/ledger/internal/server/gql"
 	"github.com/ddouglas/ledger/internal/server/gql/dataloaders"
 	"github.com/ddouglas/ledger/internal/server/gql/generated"
+	"github.com/ddouglas/ledger/internal/statement"
 	"github.com/ddouglas/ledger/internal/transaction"
 	"github.com/ddouglas/ledger/internal/user"
+	"github.com/ddouglas/ledger/internal/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/newrelic/go-agent/v3/newrelic"
@@ -28,17 +41,30 @@ import (
 )
 
 type server struct {
-	port        uint
-	logger      *logrus.Logger
-	auth        auth.Service
-	loaders     dataloaders.Service
-	importer    importer.Service
-	gateway     gateway.Service
-	newrelic    *newrelic.Application
-	user        user.Service
-	account     account.Service
-	item        item.Service
-	transaction transaction.Service
+	port         uint
+	logger       *logrus.Logger
+	auth         auth.Service
+	loaders      dataloaders.Service
+	importer     importer.Service
+	gateway      gateway.Service
+	newrelic     *newrelic.Application
+	user         user.Service
+	account      account.Service
+	item         item.Service
+	transaction  transaction.Service
+	currency     currency.Service
+	report       report.Service
+	budget       budget.Service
+	recurring    recurring.Service
+	anomaly      anomaly.Service
+	forecast     forecast.Service
+	export       export.Service
+	journal      journal.Service
+	statement    statement.Service
+	notification notification.Service
+	alert        alert.Service
+	webhook      webhook.Service
+	blobs        ledger.BlobStore
 
 	server *http.Server
 }
@@ -56,21 +82,47 @@ func New(
 	account account.Service,
 	item item.Service,
 	transaction transaction.Service,
+	currency currency.Service,
+	report report.Service,
+	budget budget.Service,
+	recurring recurring.Service,
+	anomaly anomaly.Service,
+	forecast forecast.Service,
+	export export.Service,
+	journal journal.Service,
+	statement statement.Service,
+	notification notification.Service,
+	alert alert.Service,
+	webhook webhook.Service,
+	blobs ledger.BlobStore,
 
 ) *server {
 
 	s := &server{
-		newrelic:    newrelic,
-		port:        port,
-		logger:      logger,
-		auth:        auth,
-		loaders:     loaders,
-		gateway:     gateway,
-		user:        user,
-		importer:    importer,
-		account:     account,
-		item:        item,
-		transaction: transaction,
+		newrelic:     newrelic,
+		port:         port,
+		logger:       logger,
+		auth:         auth,
+		loaders:      loaders,
+		gateway:      gateway,
+		user:         user,
+		importer:     importer,
+		account:      account,
+		item:         item,
+		transaction:  transaction,
+		currency:     currency,
+		report:       report,
+		budget:       budget,
+		recurring:    recurring,
+		anomaly:      anomaly,
+		forecast:     forecast,
+		export:       export,
+		journal:      journal,
+		statement:    statement,
+		notification: notification,
+		alert:        alert,
+		webhook:      webhook,
+		blobs:        blobs,
 	}
 
 	s.server = &http.Server{
@@ -112,6 +164,18 @@ func (s *server) buildRouter() *chi.Mux {
 
 	r.Post("/external/auth0/v1/exchange", s.handleAuth0PostCodeExchange)
 
+	// The calendar feed is authorized by the token in the url
+	r.Get("/calendar/{token}.ics", s.handleGetCalendar)
+
+	// Exports authorize themselves with either the token of a requested export or a bearer token
+	r.Get("/exports/transactions.csv", s.handleGetTransactionsExport)
+
+	// Blob stores that cannot generate their own signed urls, such as the local disk driver,
+	// serve downloads through the API and verify the signature themselves
+	if handler, ok := s.blobs.(http.Handler); ok {
+		r.Mount("/blobs", http.StripPrefix("/blobs", handler))
+	}
+
 	r.Group(func(r chi.Router) {
 		r.Use(s.authorization)
 		r.Get("/retool/auth", func(w http.ResponseWriter, r *http.Request) {
@@ -134,6 +198,10 @@ func (s *server) buildRouter() *chi.Mux {
 		r.Post("/items/{itemID}/accounts/{accountID}/transactions/{transactionID}/receipt", s.handlePostAccountTransactionReceipt)
 		r.Delete("/items/{itemID}/accounts/{accountID}/transactions/{transactionID}/receipt", s.handleDeleteAccountTransactionReceipt)
 
+		r.Post("/items/{itemID}/accounts/{accountID}/statements", s.handlePostAccountStatement)
+
+		r.Get("/exports/journal", s.handleGetJournalExport)
+
 		// ##### GraphQL Handler #####
 		handler := handler.New(
 			generated.NewExecutableSchema(
@@ -141,10 +209,23 @@ func (s *server) buildRouter() *chi.Mux {
 					Resolvers: resolvers.New(
 						s.logger,
 						s.account,
+						s.alert,
+						s.anomaly,
+						s.budget,
+						s.currency,
+						s.export,
+						s.forecast,
 						s.gateway,
 						s.item,
+						s.journal,
 						s.loaders,
+						s.notification,
+						s.recurring,
+						s.report,
+						s.statement,
 						s.transaction,
+						s.user,
+						s.webhook,
 					),
 				},
 			),
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/ddouglas/ledger/internal"
+	"github.com/ddouglas/ledger/internal/statement"
+	"github.com/go-chi/chi/v5"
+	"github.com/pkg/errors"
+)
+
+// maxStatementSize bounds the statement files that are read into memory to be imported
+const maxStatementSize = 10 << 20
+
+// handlePostAccountStatement imports an OFX, QFX or CSV statement uploaded as the file field of a
+// multipart form into the account
+func (s *server) handlePostAccountStatement(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+	user := internal.UserFromContext(ctx)
+
+	itemID := chi.URLParam(r, "itemID")
+	item, err := s.item.ItemByUserID(ctx, user.ID, itemID)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, errors.New("failed to verify ownership of item"))
+		return
+	}
+
+	accountID := chi.URLParam(r, "accountID")
+	account, err := s.account.Account(ctx, item.ItemID, accountID)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, errors.New("failed to fetch account"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementSize)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, errors.New("a statement must be uploaded as the file field of a multipart form, and be no larger than 10MB"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusBadRequest, errors.New("failed to read statement"))
+		return
+	}
+
+	result, err := s.statement.ImportStatement(ctx, item, account, header.Filename, data)
+	if errors.Is(err, statement.ErrInvalidStatement) {
+		s.writeError(ctx, w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		GetLogEntry(r).WithError(err).Error()
+		s.writeError(ctx, w, http.StatusInternalServerError, errors.New("failed to import statement"))
+		return
+	}
+
+	s.writeResponse(ctx, w, http.StatusOK, result)
+
+}
@@ -0,0 +1,186 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/pkg/errors"
+)
+
+// csvDateLayouts accept days and months with or without a leading zero, which bank exports are
+// inconsistent about
+var csvDateLayouts = map[ledger.ExportDateFormat]string{
+	ledger.ExportDateFormatISO: "2006-1-2",
+	ledger.ExportDateFormatUS:  "1/2/2006",
+	ledger.ExportDateFormatEU:  "2/1/2006",
+}
+
+// parseCSV reads the transactions of a CSV statement laid out as described by profile. Blank rows
+// are skipped
+func parseCSV(data []byte, profile *ledger.StatementProfile) (*statement, error) {
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	if profile.Delimiter != "" {
+		reader.Comma = rune(profile.Delimiter[0])
+	}
+
+	var result = new(statement)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read row %d", row)
+		}
+
+		if row <= profile.SkipRows || strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		transaction, err := parseCSVRecord(record, profile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "row %d", row)
+		}
+
+		result.Transactions = append(result.Transactions, transaction)
+	}
+
+	return result, nil
+
+}
+
+func parseCSVRecord(record []string, profile *ledger.StatementProfile) (*statementTransaction, error) {
+
+	column := func(index int) (string, error) {
+		if index >= len(record) {
+			return "", errors.Errorf("has %d columns, column %d is out of range", len(record), index)
+		}
+
+		return strings.TrimSpace(record[index]), nil
+	}
+
+	value, err := column(profile.DateColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse(csvDateLayouts[profile.DateFormat], value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse date %q", value)
+	}
+
+	description, err := column(profile.DescriptionColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	var transaction = &statementTransaction{
+		Date:        date,
+		Description: description,
+	}
+
+	if profile.IDColumn.Valid {
+		transaction.ID, err = column(profile.IDColumn.Int)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if profile.AmountColumn.Valid {
+		value, err := column(profile.AmountColumn.Int)
+		if err != nil {
+			return nil, err
+		}
+
+		transaction.Amount, err = parseCSVAmount(value)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Debits and credits are usually both written as positive amounts, only the column
+		// they are in says which way the money moved
+		if profile.DebitColumn.Valid {
+			value, err := column(profile.DebitColumn.Int)
+			if err != nil {
+				return nil, err
+			}
+
+			debit, err := parseCSVAmount(value)
+			if err != nil {
+				return nil, err
+			}
+
+			transaction.Amount -= math.Abs(debit)
+		}
+
+		if profile.CreditColumn.Valid {
+			value, err := column(profile.CreditColumn.Int)
+			if err != nil {
+				return nil, err
+			}
+
+			credit, err := parseCSVAmount(value)
+			if err != nil {
+				return nil, err
+			}
+
+			transaction.Amount += math.Abs(credit)
+		}
+	}
+
+	if profile.InvertAmounts {
+		transaction.Amount = -transaction.Amount
+	}
+
+	return transaction, nil
+
+}
+
+// parseCSVAmount reads an amount written the way a spreadsheet displays it, with currency symbols,
+// thousands separators and negative amounts in parentheses. Empty cells are read as zero
+func parseCSVAmount(value string) (float64, error) {
+
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+
+	// A comma after the last dot is a decimal separator, unless it is followed by exactly three
+	// digits, which is how thousands are grouped when there is no decimal part
+	cleaned := value
+	comma, dot := strings.LastIndexByte(cleaned, ','), strings.LastIndexByte(cleaned, '.')
+	if comma > dot && len(strings.TrimRight(cleaned[comma+1:], " )")) != 3 {
+		cleaned = strings.NewReplacer(".", "", ",", ".").Replace(cleaned)
+	}
+
+	cleaned = strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || strings.ContainsRune(".-()", r) {
+			return r
+		}
+		return -1
+	}, cleaned)
+
+	negative := strings.HasPrefix(cleaned, "(") && strings.HasSuffix(cleaned, ")")
+	cleaned = strings.Trim(cleaned, "()")
+
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, errors.Errorf("failed to parse amount %q", value)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+
+}
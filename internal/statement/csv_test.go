@@ -0,0 +1,127 @@
+package statement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/volatiletech/null"
+)
+
+func TestParseCSV(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		data    string
+		profile ledger.StatementProfile
+		want    []statementTransaction
+		err     bool
+	}{
+		{
+			name:    "amount column with header",
+			data:    "\xef\xbb\xbfDate,Description,Amount\n2021-11-03,Corner Cafe,-42.17\n\n2021-11-04,Payroll,\"1,500.00\"\n",
+			profile: ledger.StatementProfile{SkipRows: 1, DateColumn: 0, DescriptionColumn: 1, AmountColumn: null.IntFrom(2), DateFormat: ledger.ExportDateFormatISO},
+			want: []statementTransaction{
+				{Date: time.Date(2021, 11, 3, 0, 0, 0, 0, time.UTC), Amount: -42.17, Description: "Corner Cafe"},
+				{Date: time.Date(2021, 11, 4, 0, 0, 0, 0, time.UTC), Amount: 1500, Description: "Payroll"},
+			},
+		},
+		{
+			name:    "debit and credit columns",
+			data:    "11/3/2021,Corner Cafe,42.17,\n11/4/2021,Payroll,,1500.00\n",
+			profile: ledger.StatementProfile{DateColumn: 0, DescriptionColumn: 1, DebitColumn: null.IntFrom(2), CreditColumn: null.IntFrom(3), DateFormat: ledger.ExportDateFormatUS},
+			want: []statementTransaction{
+				{Date: time.Date(2021, 11, 3, 0, 0, 0, 0, time.UTC), Amount: -42.17, Description: "Corner Cafe"},
+				{Date: time.Date(2021, 11, 4, 0, 0, 0, 0, time.UTC), Amount: 1500, Description: "Payroll"},
+			},
+		},
+		{
+			name:    "inverted amounts, eu dates and ids",
+			data:    "3/11/2021;T1;Corner Cafe;42,17\n",
+			profile: ledger.StatementProfile{Delimiter: ";", DateColumn: 0, IDColumn: null.IntFrom(1), DescriptionColumn: 2, AmountColumn: null.IntFrom(3), DateFormat: ledger.ExportDateFormatEU, InvertAmounts: true},
+			want: []statementTransaction{
+				{ID: "T1", Date: time.Date(2021, 11, 3, 0, 0, 0, 0, time.UTC), Amount: -42.17, Description: "Corner Cafe"},
+			},
+		},
+		{
+			name:    "malformed date",
+			data:    "2021-13-45,Corner Cafe,-42.17\n",
+			profile: ledger.StatementProfile{DateColumn: 0, DescriptionColumn: 1, AmountColumn: null.IntFrom(2), DateFormat: ledger.ExportDateFormatISO},
+			err:     true,
+		},
+		{
+			name:    "date in the wrong format",
+			data:    "11/03/2021,Corner Cafe,-42.17\n",
+			profile: ledger.StatementProfile{DateColumn: 0, DescriptionColumn: 1, AmountColumn: null.IntFrom(2), DateFormat: ledger.ExportDateFormatISO},
+			err:     true,
+		},
+		{
+			name:    "malformed amount",
+			data:    "2021-11-03,Corner Cafe,abc\n",
+			profile: ledger.StatementProfile{DateColumn: 0, DescriptionColumn: 1, AmountColumn: null.IntFrom(2), DateFormat: ledger.ExportDateFormatISO},
+			err:     true,
+		},
+		{
+			name:    "missing column",
+			data:    "2021-11-03,Corner Cafe\n",
+			profile: ledger.StatementProfile{DateColumn: 0, DescriptionColumn: 1, AmountColumn: null.IntFrom(2), DateFormat: ledger.ExportDateFormatISO},
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCSV([]byte(tt.data), &tt.profile)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %t", err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+
+			if len(got.Transactions) != len(tt.want) {
+				t.Fatalf("parsed %d transactions, want %d", len(got.Transactions), len(tt.want))
+			}
+			for i, transaction := range got.Transactions {
+				if *transaction != tt.want[i] {
+					t.Errorf("transaction %d = %+v, want %+v", i, *transaction, tt.want[i])
+				}
+			}
+		})
+	}
+
+}
+
+func TestParseCSVAmount(t *testing.T) {
+
+	tests := []struct {
+		value string
+		want  float64
+		err   bool
+	}{
+		{value: "", want: 0},
+		{value: "12.50", want: 12.5},
+		{value: "-12.50", want: -12.5},
+		{value: "$1,234.56", want: 1234.56},
+		{value: "(1,234.56)", want: -1234.56},
+		{value: "$ (12.00)", want: -12},
+		{value: "1.234,56 €", want: 1234.56},
+		{value: "12,50", want: 12.5},
+		{value: "1,234", want: 1234},
+		{value: "-", err: true},
+		{value: "twelve", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseCSVAmount(tt.value)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %t", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("amount = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,141 @@
+package statement
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+// parseOFX reads the statements of an OFX or QFX file, one for each account in it. OFX 1.x files are
+// SGML and leave the tags of single values unclosed, while OFX 2.x files are XML. Both are read by
+// splitting the file on tags and taking the text after each tag as its value, so closing tags are
+// never needed
+func parseOFX(data []byte) ([]*statement, error) {
+
+	body := string(data)
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("file is not an OFX statement, the OFX element is missing")
+	}
+
+	var statements = make([]*statement, 0, 1)
+	var result *statement
+	var current *statementTransaction
+	var inLedgerBalance bool
+
+	for _, token := range strings.Split(body[start:], "<") {
+		end := strings.IndexByte(token, '>')
+		if end < 0 {
+			continue
+		}
+
+		tag := strings.ToUpper(strings.TrimSpace(token[:end]))
+		value := strings.TrimSpace(html.UnescapeString(token[end+1:]))
+
+		// Bank and credit card statements each describe a single account, a file downloaded for
+		// several accounts at once has one of them per account
+		if tag == "STMTRS" || tag == "CCSTMTRS" || (result == nil && tag == "STMTTRN") {
+			result = new(statement)
+			statements = append(statements, result)
+		}
+
+		if result == nil {
+			continue
+		}
+
+		switch tag {
+		case "STMTTRN":
+			current = new(statementTransaction)
+		case "/STMTTRN":
+			if current == nil {
+				continue
+			}
+
+			if current.Date.IsZero() {
+				return nil, errors.Errorf("transaction %q does not have a posted date", current.ID)
+			}
+
+			result.Transactions = append(result.Transactions, current)
+			current = nil
+		case "/STMTRS", "/CCSTMTRS":
+			result = nil
+		case "ACCTID":
+			// Transfers name the account on the other side inside the transaction
+			if current == nil && result.AccountNumber == "" {
+				result.AccountNumber = value
+			}
+		case "LEDGERBAL":
+			inLedgerBalance = true
+		case "/LEDGERBAL":
+			inLedgerBalance = false
+		case "CURDEF":
+			result.Currency = strings.ToUpper(value)
+		case "BALAMT":
+			if !inLedgerBalance {
+				continue
+			}
+
+			amount, err := parseOFXAmount(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse ledger balance")
+			}
+
+			result.Balance = null.Float64From(amount)
+		}
+
+		if current == nil {
+			continue
+		}
+
+		switch tag {
+		case "FITID":
+			current.ID = value
+		case "DTPOSTED":
+			date, err := parseOFXDate(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse posted date of transaction %q", current.ID)
+			}
+
+			current.Date = date
+		case "TRNAMT":
+			amount, err := parseOFXAmount(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse amount of transaction %q", current.ID)
+			}
+
+			current.Amount = amount
+		case "NAME":
+			current.Description = value
+		case "MEMO":
+			if current.Description == "" {
+				current.Description = value
+			}
+		}
+	}
+
+	if len(statements) == 0 {
+		statements = append(statements, new(statement))
+	}
+
+	return statements, nil
+
+}
+
+// parseOFXDate reads the date of an OFX datetime, which is formatted as YYYYMMDD followed by an
+// optional time and timezone. Statements only care about the day the transaction posted on
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.Errorf("%q is not a valid OFX date", value)
+	}
+
+	return time.Parse("20060102", value[:8])
+}
+
+// parseOFXAmount reads an OFX amount, which some institutions write with a comma as the decimal separator
+func parseOFXAmount(value string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+}
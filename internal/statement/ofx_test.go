@@ -0,0 +1,133 @@
+package statement
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOFX(t *testing.T) {
+
+	type want struct {
+		account      string
+		currency     string
+		balance      float64
+		transactions []statementTransaction
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want []want
+		err  bool
+	}{
+		{
+			name: "not ofx",
+			data: "Date,Description,Amount\n",
+			err:  true,
+		},
+		{
+			name: "sgml",
+			data: `OFXHEADER:100
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>usd
+<BANKACCTFROM><ACCTID>000123456789</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20211103120000[-5:EST]<TRNAMT>-42.17<FITID>A1<NAME>Corner Cafe &amp; Bar</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20211104<TRNAMT>1500,00<FITID>A2<MEMO>Payroll</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>1834.22<DTASOF>20211105</LEDGERBAL>
+<AVAILBAL><BALAMT>1700.00</AVAILBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`,
+			want: []want{{
+				account:  "000123456789",
+				currency: "USD",
+				balance:  1834.22,
+				transactions: []statementTransaction{
+					{ID: "A1", Date: time.Date(2021, 11, 3, 0, 0, 0, 0, time.UTC), Amount: -42.17, Description: "Corner Cafe & Bar"},
+					{ID: "A2", Date: time.Date(2021, 11, 4, 0, 0, 0, 0, time.UTC), Amount: 1500, Description: "Payroll"},
+				},
+			}},
+		},
+		{
+			name: "xml with several accounts",
+			data: `<?xml version="1.0"?><?OFX OFXHEADER="200"?>
+<OFX><BANKMSGSRSV1>
+<STMTTRNRS><STMTRS><CURDEF>USD</CURDEF><BANKACCTFROM><ACCTID>1111</ACCTID></BANKACCTFROM><BANKTRANLIST>
+<STMTTRN><DTPOSTED>20211101</DTPOSTED><TRNAMT>-5.00</TRNAMT><FITID>B1</FITID><NAME>Transfer</NAME><BANKACCTTO><ACCTID>2222</ACCTID></BANKACCTTO></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS>
+</BANKMSGSRSV1><CREDITCARDMSGSRSV1>
+<CCSTMTTRNRS><CCSTMTRS><CURDEF>CAD</CURDEF><CCACCTFROM><ACCTID>4444</ACCTID></CCACCTFROM><BANKTRANLIST>
+<STMTTRN><DTPOSTED>20211102</DTPOSTED><TRNAMT>-9.99</TRNAMT><FITID>C1</FITID><NAME>Streaming</NAME></STMTTRN>
+</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS>
+</CREDITCARDMSGSRSV1></OFX>`,
+			want: []want{
+				{
+					account:  "1111",
+					currency: "USD",
+					transactions: []statementTransaction{
+						{ID: "B1", Date: time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC), Amount: -5, Description: "Transfer"},
+					},
+				},
+				{
+					account:  "4444",
+					currency: "CAD",
+					transactions: []statementTransaction{
+						{ID: "C1", Date: time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC), Amount: -9.99, Description: "Streaming"},
+					},
+				},
+			},
+		},
+		{
+			name: "missing posted date",
+			data: `<OFX><STMTRS><STMTTRN><TRNAMT>-1.00<FITID>D1</STMTTRN></STMTRS></OFX>`,
+			err:  true,
+		},
+		{
+			name: "malformed amount",
+			data: `<OFX><STMTRS><STMTTRN><DTPOSTED>20211101<TRNAMT>ten<FITID>D2</STMTTRN></STMTRS></OFX>`,
+			err:  true,
+		},
+		{
+			name: "malformed date",
+			data: `<OFX><STMTRS><STMTTRN><DTPOSTED>2021<TRNAMT>-1.00<FITID>D3</STMTTRN></STMTRS></OFX>`,
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOFX([]byte(tt.data))
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %t", err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parsed %d statements, want %d", len(got), len(tt.want))
+			}
+
+			for i, statement := range got {
+				want := tt.want[i]
+				if statement.AccountNumber != want.account {
+					t.Errorf("statement %d account = %q, want %q", i, statement.AccountNumber, want.account)
+				}
+				if statement.Currency != want.currency {
+					t.Errorf("statement %d currency = %q, want %q", i, statement.Currency, want.currency)
+				}
+				if statement.Balance.Float64 != want.balance {
+					t.Errorf("statement %d balance = %v, want %v", i, statement.Balance.Float64, want.balance)
+				}
+				if len(statement.Transactions) != len(want.transactions) {
+					t.Fatalf("statement %d has %d transactions, want %d", i, len(statement.Transactions), len(want.transactions))
+				}
+				for j, transaction := range statement.Transactions {
+					if *transaction != want.transactions[j] {
+						t.Errorf("statement %d transaction %d = %+v, want %+v", i, j, *transaction, want.transactions[j])
+					}
+				}
+			}
+		})
+	}
+
+}
@@ -0,0 +1,390 @@
+// Package statement provides service access to the import of OFX, QFX and CSV statement files
+package statement
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/account"
+	"github.com/ddouglas/ledger/internal/event"
+	"github.com/ddouglas/ledger/internal/transaction"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+// ErrInvalidStatement is returned when a statement cannot be imported because of what is in it. The
+// errors it is wrapped in are safe to show to the user
+var ErrInvalidStatement = errors.New("invalid statement")
+
+type Service interface {
+	ImportStatement(ctx context.Context, item *ledger.Item, account *ledger.Account, filename string, data []byte) (*ledger.StatementImport, error)
+	ledger.StatementRepository
+}
+
+type service struct {
+	account     account.Service
+	event       event.Service
+	transaction transaction.Service
+
+	ledger.StatementRepository
+}
+
+func New(account account.Service, event event.Service, transaction transaction.Service, statements ledger.StatementRepository) Service {
+	return &service{
+		account:             account,
+		event:               event,
+		transaction:         transaction,
+		StatementRepository: statements,
+	}
+}
+
+// statement is the statement of a single account in a statement file. AccountNumber, Currency and
+// Balance are only known for OFX statements
+type statement struct {
+	AccountNumber string
+	Currency      string
+	Balance       null.Float64
+	Transactions  []*statementTransaction
+}
+
+// statementTransaction is a transaction read from a statement. ID is the id the institution gave the
+// transaction, the FITID of an OFX statement, and is empty when the statement does not have one
+type statementTransaction struct {
+	ID          string
+	Date        time.Time
+	Amount      float64
+	Description string
+}
+
+func (s *service) SaveStatementProfile(ctx context.Context, profile *ledger.StatementProfile) (*ledger.StatementProfile, error) {
+
+	if profile.Delimiter == "" {
+		profile.Delimiter = ","
+	}
+
+	if len(profile.Delimiter) != 1 || strings.ContainsAny(profile.Delimiter, "\"\r\n") {
+		return nil, errors.Errorf("%q is not a valid delimiter, delimiters must be a single character", profile.Delimiter)
+	}
+
+	if profile.DateFormat == "" {
+		profile.DateFormat = ledger.ExportDateFormatISO
+	}
+
+	if !profile.DateFormat.Valid() {
+		return nil, errors.Errorf("%s is not a valid date format, valid formats are iso, us and eu", profile.DateFormat)
+	}
+
+	if !profile.AmountColumn.Valid && !profile.DebitColumn.Valid && !profile.CreditColumn.Valid {
+		return nil, errors.New("either an amount column or a debit and credit column is required")
+	}
+
+	for _, value := range []null.Int{
+		null.IntFrom(profile.SkipRows), null.IntFrom(profile.DateColumn), null.IntFrom(profile.DescriptionColumn),
+		profile.AmountColumn, profile.DebitColumn, profile.CreditColumn, profile.IDColumn,
+	} {
+		if value.Valid && value.Int < 0 {
+			return nil, errors.New("rows and columns cannot be negative")
+		}
+	}
+
+	return s.StatementRepository.SaveStatementProfile(ctx, profile)
+
+}
+
+// ImportStatement imports the transactions of an OFX, QFX or CSV statement into account. CSV statements
+// are read with the statement profile of the account. Transactions go through the same processing as
+// the transactions Plaid sends, and are given ids derived from the statement so that importing an
+// overlapping statement skips the transactions that were already imported. Transactions already on the
+// account under another id, such as those Plaid sent, are skipped when their date, amount and name
+// match. The balance of a manual account is updated from the ledger balance of an OFX statement
+func (s *service) ImportStatement(ctx context.Context, item *ledger.Item, account *ledger.Account, filename string, data []byte) (*ledger.StatementImport, error) {
+
+	var result = &ledger.StatementImport{Format: detectFormat(filename, data)}
+
+	var parsed *statement
+	var err error
+	switch result.Format {
+	case ledger.StatementFormatOFX:
+		var statements []*statement
+		statements, err = parseOFX(data)
+		if err == nil {
+			parsed, err = statementForAccount(statements, account)
+		}
+	case ledger.StatementFormatCSV:
+		var profile *ledger.StatementProfile
+		profile, err = s.StatementProfile(ctx, account.ItemID, account.AccountID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(ErrInvalidStatement, "a statement profile describing the columns of the CSV must be saved for the account before it can be imported")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "[statement.ImportStatement] failed to fetch statement profile")
+		}
+
+		parsed, err = parseCSV(data, profile)
+	}
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidStatement, err.Error())
+	}
+
+	isoCurrency, unofficialCurrency := parsed.Currency, ""
+	if isoCurrency == "" && account.Balance != nil {
+		isoCurrency, unofficialCurrency = account.Balance.ISOCurrencyCode, account.Balance.UnofficialCurrencyCode.String
+	}
+
+	// Without an id from the institution, identical transactions on the same day are told apart
+	// by the order they appear in the statement
+	var ids = make([]string, len(parsed.Transactions))
+	var statementIDs = make(map[string]bool, len(parsed.Transactions))
+	var occurrences = make(map[string]int)
+	for i, row := range parsed.Transactions {
+		row.Amount = math.Round(row.Amount*100) / 100
+
+		ids[i] = transactionID(account.AccountID, "id", row.ID)
+		if row.ID == "" {
+			key := strings.Join([]string{row.Date.Format("2006-01-02"), strconv.FormatFloat(row.Amount, 'f', 2, 64), row.Description}, "\x00")
+			occurrences[key]++
+			ids[i] = transactionID(account.AccountID, "row", key, strconv.Itoa(occurrences[key]))
+		}
+		statementIDs[ids[i]] = true
+	}
+
+	existing, err := s.existingTransactions(ctx, account, parsed.Transactions, statementIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions = make([]*ledger.Transaction, 0, len(parsed.Transactions))
+	var seen = make(map[string]bool)
+	for i, row := range parsed.Transactions {
+		id := ids[i]
+		result.Total++
+
+		_, err := s.transaction.Transaction(ctx, account.ItemID, id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(err, "[statement.ImportStatement] failed to check for existing transaction")
+		}
+
+		if err == nil || seen[id] {
+			result.Duplicates++
+			continue
+		}
+		seen[id] = true
+
+		// The same transaction may already have been imported by Plaid, or from a statement in
+		// another format, under an id of its own
+		if existing.consume(row) {
+			result.Duplicates++
+			continue
+		}
+
+		transaction := &ledger.Transaction{
+			ItemID:         account.ItemID,
+			AccountID:      account.AccountID,
+			TransactionID:  id,
+			Name:           row.Description,
+			MerchantName:   null.NewString(row.Description, row.Description != ""),
+			PaymentChannel: "other",
+			Amount:         row.Amount,
+			Date:           row.Date,
+
+			ISOCurrencyCode:        null.NewString(isoCurrency, isoCurrency != ""),
+			UnofficialCurrencyCode: null.NewString(unofficialCurrency, isoCurrency == "" && unofficialCurrency != ""),
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	err = s.transaction.ProcessTransactions(ctx, item, transactions)
+	if err != nil {
+		return nil, errors.Wrap(err, "[statement.ImportStatement] failed to process transactions")
+	}
+
+	// Transactions that fail to process are logged and skipped, only count the ones that were saved
+	for _, transaction := range transactions {
+		_, err := s.transaction.Transaction(ctx, account.ItemID, transaction.TransactionID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(err, "[statement.ImportStatement] failed to check for imported transaction")
+		}
+
+		if err != nil {
+			result.Failed++
+			continue
+		}
+
+		result.Imported++
+	}
+
+	if item.IsManual() && parsed.Balance.Valid && account.Balance != nil {
+		account.Balance.Current = parsed.Balance.Float64
+		account.Balance.Available = parsed.Balance.Float64
+		account.Balance.LastUpdated = null.TimeFrom(time.Now())
+
+		account, err = s.account.UpdateAccount(ctx, account.ItemID, account.AccountID, account)
+		if err != nil {
+			return nil, errors.Wrap(err, "[statement.ImportStatement] failed to update account balance")
+		}
+
+		err = s.account.SnapshotAccountBalance(ctx, account)
+		if err != nil {
+			return nil, errors.Wrap(err, "[statement.ImportStatement] failed to snapshot account balance")
+		}
+	}
+
+	err = s.event.EmitForItem(ctx, item.ItemID, ledger.EventImportCompleted, &ledger.ImportCompletedEvent{
+		ItemID:          item.ItemID,
+		Source:          ledger.ImportSourceStatement,
+		Transactions:    result.Total,
+		NewTransactions: result.Imported,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "[statement.ImportStatement] failed to emit import completed event")
+	}
+
+	return result, nil
+
+}
+
+// accountTransactions are the normalized names of the transactions of an account that were not
+// imported from the statement being imported, keyed by date and amount
+type accountTransactions map[string][]string
+
+func existingKey(date time.Time, amount float64) string {
+	return date.Format("2006-01-02") + ":" + strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// consume reports whether there is a transaction with the date, amount and name of row, and removes
+// it so that it is not matched again. Names match when one contains the other once normalized,
+// institutions often shorten or expand the description Plaid returns
+func (e accountTransactions) consume(row *statementTransaction) bool {
+
+	key := existingKey(row.Date, row.Amount)
+	name := normalizeName(row.Description)
+	for i, candidate := range e[key] {
+		if candidate == name || (candidate != "" && name != "" && (strings.Contains(candidate, name) || strings.Contains(name, candidate))) {
+			e[key] = append(e[key][:i], e[key][i+1:]...)
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// existingTransactions returns the transactions already on account between the first and last
+// date of rows, hidden ones included, leaving out the ones with an id in statementIDs, which are
+// matched by their id
+func (s *service) existingTransactions(ctx context.Context, account *ledger.Account, rows []*statementTransaction, statementIDs map[string]bool) (accountTransactions, error) {
+
+	var result = make(accountTransactions)
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	from, to := rows[0].Date, rows[0].Date
+	for _, row := range rows {
+		if row.Date.Before(from) {
+			from = row.Date
+		}
+		if row.Date.After(to) {
+			to = row.Date
+		}
+	}
+
+	transactions, err := s.transaction.StatementCandidates(ctx, account.ItemID, account.AccountID, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "[statement.existingTransactions] failed to fetch transactions of account")
+	}
+
+	for _, transaction := range transactions {
+		if statementIDs[transaction.TransactionID] {
+			continue
+		}
+
+		key := existingKey(transaction.Date, transaction.Amount)
+		result[key] = append(result[key], normalizeName(transaction.Name))
+	}
+
+	return result, nil
+
+}
+
+// normalizeName lowercases name and drops everything but letters and digits
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// statementForAccount returns the statement of account out of the statements of a file. A file with
+// statements for several accounts must have exactly one whose account number ends with the mask of
+// account, otherwise there is no telling which one to import
+func statementForAccount(statements []*statement, account *ledger.Account) (*statement, error) {
+
+	if len(statements) == 1 {
+		return statements[0], nil
+	}
+
+	var match *statement
+	for _, statement := range statements {
+		if !account.Mask.Valid || account.Mask.String == "" || !strings.HasSuffix(statement.AccountNumber, account.Mask.String) {
+			continue
+		}
+
+		if match != nil {
+			return nil, errors.Errorf("file contains statements for %d accounts and more than one of them ends in %s, export the account on its own", len(statements), account.Mask.String)
+		}
+		match = statement
+	}
+
+	if match == nil {
+		return nil, errors.Errorf("file contains statements for %d accounts and none of them match the account, export the account on its own", len(statements))
+	}
+
+	return match, nil
+
+}
+
+// detectFormat returns the format of a statement from the extension of its filename, falling back to
+// looking for the OFX header in the file when the extension is not known
+func detectFormat(filename string, data []byte) ledger.StatementFormat {
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ofx", ".qfx":
+		return ledger.StatementFormatOFX
+	case ".csv":
+		return ledger.StatementFormatCSV
+	}
+
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+
+	head = bytes.ToUpper(head)
+	if bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")) {
+		return ledger.StatementFormatOFX
+	}
+
+	return ledger.StatementFormatCSV
+
+}
+
+// transactionID derives the id of a statement transaction from the account it belongs to and parts
+// that identify it within the account
+func transactionID(accountID string, parts ...string) string {
+	hash := sha256.Sum256([]byte(accountID + "\x00" + strings.Join(parts, "\x00")))
+	return "stmt-" + hex.EncodeToString(hash[:])[:48]
+}
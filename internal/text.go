@@ -0,0 +1,17 @@
+package internal
+
+import "unicode/utf8"
+
+// Truncate returns s cut to at most n bytes. The cut is moved back to the start of the character it
+// would split, so the result is always valid UTF-8 and fits a column of n characters
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,139 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+var fileExtensions = map[string]string{
+	"application/pdf": "pdf",
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+}
+
+func attachmentObjectKey(itemID, transactionID, attachmentID, contentType string) string {
+	return fmt.Sprintf("attachments/%s/%s/%s.%s", itemID, transactionID, attachmentID, fileExtensions[contentType])
+}
+
+// attachmentFilename returns the name of the uploaded file, updating the extension of images that
+// were converted to another format
+func attachmentFilename(filename, uploadedType, contentType string) string {
+	filename = path.Base(filename)
+	if uploadedType == contentType {
+		return filename
+	}
+
+	return strings.TrimSuffix(filename, path.Ext(filename)) + "." + fileExtensions[contentType]
+}
+
+func (s *service) AddTransactionAttachment(ctx context.Context, itemID, transactionID string, file graphql.Upload) (*ledger.TransactionAttachment, error) {
+
+	transaction, err := s.Transaction(ctx, itemID, transactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AddTransactionAttachment] failed to fetch transaction")
+	}
+
+	err = validateContentType(file.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(file.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AddTransactionAttachment] failed to read file")
+	}
+
+	upload, err := processUpload(data, file.ContentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AddTransactionAttachment]")
+	}
+
+	checksum := sha256.Sum256(upload.Data)
+	attachmentID := uuid.Must(uuid.NewV4()).String()
+	attachment := &ledger.TransactionAttachment{
+		AttachmentID:  attachmentID,
+		ItemID:        transaction.ItemID,
+		TransactionID: transaction.TransactionID,
+		ObjectKey:     attachmentObjectKey(transaction.ItemID, transaction.TransactionID, attachmentID, upload.ContentType),
+		Filename:      attachmentFilename(file.Filename, file.ContentType, upload.ContentType),
+		ContentType:   upload.ContentType,
+		Size:          int64(len(upload.Data)),
+		Checksum:      hex.EncodeToString(checksum[:]),
+	}
+
+	err = s.blobs.Put(ctx, attachment.ObjectKey, bytes.NewReader(upload.Data), attachment.Size, attachment.ContentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AddTransactionAttachment] failed to write file to blob store")
+	}
+
+	attachment.ThumbnailKey, err = s.putThumbnail(ctx, attachment.ObjectKey, upload.Thumbnail)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AddTransactionAttachment]")
+	}
+
+	attachment, err = s.CreateTransactionAttachment(ctx, attachment)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AddTransactionAttachment] failed to record attachment")
+	}
+
+	return attachment, nil
+
+}
+
+func (s *service) RemoveTransactionAttachment(ctx context.Context, itemID, attachmentID string) error {
+
+	attachment, err := s.TransactionAttachment(ctx, itemID, attachmentID)
+	if err != nil {
+		return errors.Wrap(err, "[transaction.RemoveTransactionAttachment] failed to fetch attachment")
+	}
+
+	err = s.blobs.Delete(ctx, attachment.ObjectKey)
+	if err != nil {
+		return errors.Wrap(err, "[transaction.RemoveTransactionAttachment] failed to delete attachment from blob store")
+	}
+
+	s.deleteThumbnail(ctx, attachment.ThumbnailKey)
+
+	err = s.DeleteTransactionAttachment(ctx, itemID, attachmentID)
+	if err != nil {
+		return errors.Wrap(err, "[transaction.RemoveTransactionAttachment] failed to delete attachment record")
+	}
+
+	return nil
+
+}
+
+// TransactionAttachmentURL returns a signed url that can be used to download the attachment
+// for the next ten minutes
+func (s *service) TransactionAttachmentURL(ctx context.Context, attachment *ledger.TransactionAttachment) (string, error) {
+
+	url, err := s.blobs.SignedURL(ctx, attachment.ObjectKey, time.Minute*10)
+	if err != nil {
+		return "", errors.Wrap(err, "[transaction.TransactionAttachmentURL] failed to generate signed url for attachment")
+	}
+
+	return url, nil
+
+}
+
+// TransactionAttachmentThumbnailURL returns a signed url for the thumbnail of the attachment.
+// Only images have a thumbnail
+func (s *service) TransactionAttachmentThumbnailURL(ctx context.Context, attachment *ledger.TransactionAttachment) (null.String, error) {
+
+	url, err := s.thumbnailURL(ctx, attachment.ThumbnailKey)
+	return url, errors.Wrap(err, "[transaction.TransactionAttachmentThumbnailURL]")
+
+}
@@ -0,0 +1,97 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger/internal/receipt"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+// processUpload normalizes uploaded images and generates their thumbnail. Other files, such as
+// PDFs, are returned unchanged without a thumbnail
+func processUpload(data []byte, contentType string) (*receipt.Image, error) {
+
+	if !receipt.IsImage(contentType) {
+		return &receipt.Image{Data: data, ContentType: contentType}, nil
+	}
+
+	img, err := receipt.ProcessImage(data, contentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to process image")
+	}
+
+	return img, nil
+
+}
+
+// thumbnailKey returns the key the thumbnail of the object stored under objectKey is stored under
+func thumbnailKey(objectKey string) string {
+	return strings.TrimSuffix(objectKey, path.Ext(objectKey)) + ".thumb.jpg"
+}
+
+// putThumbnail writes the thumbnail of the object stored under objectKey, returning the key it was
+// written to. Files without a thumbnail return an invalid key
+func (s *service) putThumbnail(ctx context.Context, objectKey string, thumbnail []byte) (null.String, error) {
+
+	if len(thumbnail) == 0 {
+		return null.NewString("", false), nil
+	}
+
+	key := thumbnailKey(objectKey)
+	err := s.blobs.Put(ctx, key, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg")
+	if err != nil {
+		return null.NewString("", false), errors.Wrap(err, "failed to write thumbnail to blob store")
+	}
+
+	return null.StringFrom(key), nil
+
+}
+
+// deleteThumbnail removes a thumbnail that is no longer referenced. A left over thumbnail is
+// harmless, so failures are logged rather than returned
+func (s *service) deleteThumbnail(ctx context.Context, key null.String) {
+
+	if !key.Valid {
+		return
+	}
+
+	err := s.blobs.Delete(ctx, key.String)
+	if err != nil {
+		s.logger.WithError(err).WithField("key", key.String).Error("failed to delete thumbnail")
+	}
+
+}
+
+func (s *service) readBlob(ctx context.Context, key string) ([]byte, error) {
+
+	reader, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+
+}
+
+// thumbnailURL returns a signed url that can be used to download the thumbnail for the next ten minutes
+func (s *service) thumbnailURL(ctx context.Context, key null.String) (null.String, error) {
+
+	if !key.Valid {
+		return null.NewString("", false), nil
+	}
+
+	url, err := s.blobs.SignedURL(ctx, key.String, time.Minute*10)
+	if err != nil {
+		return null.NewString("", false), errors.Wrap(err, "failed to generate signed url for thumbnail")
+	}
+
+	return null.StringFrom(url), nil
+
+}
@@ -0,0 +1,282 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/receipt"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/volatiletech/null"
+)
+
+const (
+	// receiptAmountTolerance absorbs rounding differences between the receipt and the transaction
+	receiptAmountTolerance = 0.01
+	// receiptDateTolerance allows for transactions that post a few days after the purchase
+	receiptDateTolerance = time.Hour * 24 * 3
+	// receiptSearchWindow is how far back suggestions are searched when a receipt has no date
+	receiptSearchWindow = time.Hour * 24 * 60
+)
+
+func unmatchedReceiptKey(userID uuid.UUID, receiptID, contentType string) string {
+	return fmt.Sprintf("receipts/unmatched/%s/%s.%s", userID, receiptID, fileExtensions[contentType])
+}
+
+// extractReceiptDetails parses the text of PDF receipts. Images and PDFs without a text layer
+// are stored without any details
+func (s *service) extractReceiptDetails(record *ledger.Receipt, data []byte) {
+
+	record.Text = null.NewString("", false)
+	record.Total = null.NewFloat64(0, false)
+	record.Date = null.NewTime(time.Time{}, false)
+	record.MerchantName = null.NewString("", false)
+
+	if record.ContentType != "application/pdf" {
+		return
+	}
+
+	text, err := receipt.ExtractPDFText(data)
+	if err != nil {
+		s.logger.WithError(err).WithField("receipt_id", record.ReceiptID).Debug("unable to extract text from receipt")
+		return
+	}
+
+	details := receipt.Parse(text)
+	record.Text = null.StringFrom(text)
+	record.Total = details.Total
+	record.Date = details.Date
+	record.MerchantName = details.MerchantName
+
+}
+
+// compareReceipt flags receipts whose total or date disagree with the transaction they are attached to
+func compareReceipt(record *ledger.Receipt, transaction *ledger.Transaction) {
+
+	record.AmountMismatch = record.Total.Valid &&
+		math.Abs(record.Total.Float64-math.Abs(transaction.Amount)) > receiptAmountTolerance
+
+	record.DateMismatch = record.Date.Valid &&
+		math.Abs(float64(transaction.Date.Sub(record.Date.Time))) > float64(receiptDateTolerance)
+
+}
+
+func (s *service) saveReceipt(ctx context.Context, record *ledger.Receipt) error {
+
+	_, err := s.Receipt(ctx, record.UserID, record.ReceiptID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err != nil {
+		_, err = s.CreateReceipt(ctx, record)
+		return err
+	}
+
+	_, err = s.UpdateReceipt(ctx, record.ReceiptID, record)
+	return err
+
+}
+
+// UploadUnmatchedReceipt stores a receipt that has not been assigned to a transaction yet.
+// It is held in the users unmatched receipts inbox until it is assigned
+func (s *service) UploadUnmatchedReceipt(ctx context.Context, userID uuid.UUID, file graphql.Upload) (*ledger.Receipt, error) {
+
+	err := validateContentType(file.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(file.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UploadUnmatchedReceipt] failed to read file")
+	}
+
+	upload, err := processUpload(data, file.ContentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UploadUnmatchedReceipt]")
+	}
+
+	receiptID := uuid.Must(uuid.NewV4()).String()
+	record := &ledger.Receipt{
+		ReceiptID:   receiptID,
+		UserID:      userID,
+		ObjectKey:   unmatchedReceiptKey(userID, receiptID, upload.ContentType),
+		ContentType: upload.ContentType,
+	}
+
+	err = s.blobs.Put(ctx, record.ObjectKey, bytes.NewReader(upload.Data), int64(len(upload.Data)), record.ContentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UploadUnmatchedReceipt] failed to write file to blob store")
+	}
+
+	record.ThumbnailKey, err = s.putThumbnail(ctx, record.ObjectKey, upload.Thumbnail)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UploadUnmatchedReceipt]")
+	}
+
+	s.extractReceiptDetails(record, upload.Data)
+
+	record, err = s.CreateReceipt(ctx, record)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UploadUnmatchedReceipt] failed to save receipt")
+	}
+
+	return record, nil
+
+}
+
+// ReceiptSuggestions returns the transactions an unmatched receipt most likely belongs to, closest
+// to the date on the receipt first. Receipts that a total could not be extracted from have no suggestions
+func (s *service) ReceiptSuggestions(ctx context.Context, record *ledger.Receipt) ([]*ledger.Transaction, error) {
+
+	if record.TransactionID.Valid || !record.Total.Valid {
+		return nil, nil
+	}
+
+	from, to := record.CreatedAt.Add(-receiptSearchWindow), record.CreatedAt
+	if record.Date.Valid {
+		from, to = record.Date.Time.Add(-receiptDateTolerance), record.Date.Time.Add(receiptDateTolerance)
+	}
+
+	candidates, err := s.ReceiptCandidates(ctx, record.UserID, record.Total.Float64, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.ReceiptSuggestions] failed to fetch candidates")
+	}
+
+	if record.Date.Valid {
+		distance := func(t *ledger.Transaction) time.Duration {
+			d := t.Date.Sub(record.Date.Time)
+			if d < 0 {
+				return -d
+			}
+			return d
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return distance(candidates[i]) < distance(candidates[j])
+		})
+	}
+
+	return candidates, nil
+
+}
+
+// AssignReceipt moves a receipt out of the unmatched inbox and attaches it to the transaction
+func (s *service) AssignReceipt(ctx context.Context, userID uuid.UUID, receiptID, itemID, transactionID string) (*ledger.Transaction, error) {
+
+	record, err := s.Receipt(ctx, userID, receiptID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AssignReceipt] failed to fetch receipt")
+	}
+
+	if record.TransactionID.Valid {
+		return nil, errors.New("[transaction.AssignReceipt] receipt has already been assigned to a transaction")
+	}
+
+	transaction, err := s.Transaction(ctx, itemID, transactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AssignReceipt] failed to fetch transaction")
+	}
+
+	reader, err := s.blobs.Get(ctx, record.ObjectKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AssignReceipt] failed to fetch receipt from blob store")
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AssignReceipt] failed to read receipt")
+	}
+
+	// The receipt was normalized when it was uploaded to the inbox, only the thumbnail needs to be carried over
+	upload := &receipt.Image{Data: data, ContentType: record.ContentType}
+	if record.ThumbnailKey.Valid {
+		upload.Thumbnail, err = s.readBlob(ctx, record.ThumbnailKey.String)
+		if err != nil && !errors.Is(err, ledger.ErrBlobNotFound) {
+			return nil, errors.Wrap(err, "[transaction.AssignReceipt] failed to read thumbnail")
+		}
+	}
+
+	// A transaction only has a single receipt, so any receipt it already has is replaced
+	existing, err := s.ReceiptByTransactionID(ctx, itemID, transactionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "[transaction.AssignReceipt] failed to fetch existing receipt")
+	}
+	if err == nil {
+		err = s.DeleteReceipt(ctx, existing.ReceiptID)
+		if err != nil {
+			return nil, errors.Wrap(err, "[transaction.AssignReceipt] failed to delete existing receipt")
+		}
+
+		// The existing thumbnail is overwritten unless the assigned receipt does not have one
+		if len(upload.Thumbnail) == 0 {
+			s.deleteThumbnail(ctx, existing.ThumbnailKey)
+		}
+	}
+
+	unmatchedKey := record.ObjectKey
+
+	transaction, err = s.storeReceipt(ctx, transaction, record, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.blobs.Delete(ctx, unmatchedKey)
+	if err != nil {
+		s.logger.WithError(err).WithField("key", unmatchedKey).Error("failed to delete assigned receipt from unmatched inbox")
+	}
+
+	return transaction, nil
+
+}
+
+func (s *service) RemoveUnmatchedReceipt(ctx context.Context, userID uuid.UUID, receiptID string) error {
+
+	record, err := s.Receipt(ctx, userID, receiptID)
+	if err != nil {
+		return errors.Wrap(err, "[transaction.RemoveUnmatchedReceipt] failed to fetch receipt")
+	}
+
+	if record.TransactionID.Valid {
+		return errors.New("[transaction.RemoveUnmatchedReceipt] receipt is assigned to a transaction, remove it from the transaction instead")
+	}
+
+	err = s.blobs.Delete(ctx, record.ObjectKey)
+	if err != nil {
+		return errors.Wrap(err, "[transaction.RemoveUnmatchedReceipt] failed to delete receipt from blob store")
+	}
+
+	s.deleteThumbnail(ctx, record.ThumbnailKey)
+
+	return errors.Wrap(s.DeleteReceipt(ctx, receiptID), "[transaction.RemoveUnmatchedReceipt] failed to delete receipt")
+
+}
+
+// ReceiptURL returns a signed url that can be used to download the receipt for the next ten minutes
+func (s *service) ReceiptURL(ctx context.Context, record *ledger.Receipt) (string, error) {
+
+	url, err := s.blobs.SignedURL(ctx, record.ObjectKey, time.Minute*10)
+	if err != nil {
+		return "", errors.Wrap(err, "[transaction.ReceiptURL] failed to generate signed url for receipt")
+	}
+
+	return url, nil
+
+}
+
+// ReceiptThumbnailURL returns a signed url for the thumbnail of the receipt. Only images have a thumbnail
+func (s *service) ReceiptThumbnailURL(ctx context.Context, record *ledger.Receipt) (null.String, error) {
+
+	url, err := s.thumbnailURL(ctx, record.ThumbnailKey)
+	return url, errors.Wrap(err, "[transaction.ReceiptThumbnailURL]")
+
+}
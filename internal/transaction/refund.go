@@ -0,0 +1,104 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"github.com/ddouglas/ledger"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+// matchRefund looks for the charge that a newly created positive transaction most likely refunds
+// and records the pair as a suggested refund relation. The closest amount wins, with ties going
+// to the most recent charge. A charge is only offered to later refunds while the refunds matched to
+// it, leaving out rejected matches, add up to less than the charge
+func (s *service) matchRefund(ctx context.Context, refund *ledger.Transaction) error {
+
+	if refund.Amount <= 0 || refund.Pending || refund.MerchantID == "" {
+		return nil
+	}
+
+	candidates, err := s.RefundCandidates(ctx, refund, s.refundWindow)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch refund candidates")
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var match *ledger.Transaction
+	for _, candidate := range candidates {
+		if match == nil || math.Abs(candidate.Amount+refund.Amount) < math.Abs(match.Amount+refund.Amount) {
+			match = candidate
+		}
+	}
+
+	_, err = s.CreateTransactionRelation(ctx, &ledger.TransactionRelation{
+		RelationID:           uuid.Must(uuid.NewV4()).String(),
+		ItemID:               refund.ItemID,
+		TransactionID:        refund.TransactionID,
+		RelatedTransactionID: match.TransactionID,
+		Type:                 ledger.TransactionRelationTypeRefund,
+		Status:               ledger.TransactionRelationStatusSuggested,
+	})
+
+	return errors.Wrap(err, "failed to create refund relation")
+
+}
+
+func (s *service) UpdateRefundMatchStatus(ctx context.Context, itemID, relationID string, status ledger.TransactionRelationStatus) (*ledger.TransactionRelation, error) {
+
+	if !status.IsValid() {
+		return nil, errors.Errorf("%s is not a valid relation status", status)
+	}
+
+	relation, err := s.TransactionRelation(ctx, itemID, relationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UpdateRefundMatchStatus] failed to fetch relation")
+	}
+
+	if relation.Type != ledger.TransactionRelationTypeRefund {
+		return nil, errors.Errorf("relation %s is not a refund", relationID)
+	}
+
+	relation.Status = status
+
+	return s.UpdateTransactionRelation(ctx, relationID, relation)
+
+}
+
+// TransactionNetAmount returns the amount of the transaction after any refunds that have
+// not been rejected are applied to it
+func (s *service) TransactionNetAmount(ctx context.Context, transaction *ledger.Transaction) (float64, error) {
+
+	relations, err := s.TransactionRelations(ctx, transaction.ItemID, transaction.TransactionID)
+	if err != nil {
+		return 0, errors.Wrap(err, "[transaction.TransactionNetAmount] failed to fetch relations")
+	}
+
+	var amount = transaction.Amount
+	for _, relation := range relations {
+		if relation.Type != ledger.TransactionRelationTypeRefund ||
+			relation.Status == ledger.TransactionRelationStatusRejected ||
+			relation.RelatedTransactionID != transaction.TransactionID {
+			continue
+		}
+
+		refund, err := s.Transaction(ctx, relation.ItemID, relation.TransactionID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, errors.Wrap(err, "[transaction.TransactionNetAmount] failed to fetch refund")
+		}
+
+		if err != nil {
+			continue
+		}
+
+		amount += refund.Amount
+	}
+
+	return amount, nil
+
+}
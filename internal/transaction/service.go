@@ -2,21 +2,25 @@
 package transaction
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/pkg/errors"
 	"github.com/volatiletech/null"
 
 	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal/anomaly"
 	"github.com/ddouglas/ledger/internal/cache"
+	"github.com/ddouglas/ledger/internal/event"
 	"github.com/ddouglas/ledger/internal/gateway"
+	"github.com/ddouglas/ledger/internal/receipt"
+	"github.com/gofrs/uuid"
 	"github.com/r3labs/diff"
 	"github.com/sirupsen/logrus"
 	"github.com/ulule/deepcopier"
@@ -26,46 +30,71 @@ type Service interface {
 	ConvertMerchantToAlias(ctx context.Context, parentMerchantID, childMerchantID string) (*ledger.Merchant, error)
 	ProcessTransactions(ctx context.Context, item *ledger.Item, newTrans []*ledger.Transaction) error
 	TransactionReceiptPresignedURL(ctx context.Context, itemID, transactionID string) (*ledger.TransactionReceipt, error)
-	AddReceiptToTransaction(ctx context.Context, itemID, transactionID string, file graphql.Upload) error
+	AddReceiptToTransaction(ctx context.Context, userID uuid.UUID, itemID, transactionID string, file graphql.Upload) (*ledger.Transaction, error)
+	UploadUnmatchedReceipt(ctx context.Context, userID uuid.UUID, file graphql.Upload) (*ledger.Receipt, error)
+	AssignReceipt(ctx context.Context, userID uuid.UUID, receiptID, itemID, transactionID string) (*ledger.Transaction, error)
+	RemoveUnmatchedReceipt(ctx context.Context, userID uuid.UUID, receiptID string) error
+	ReceiptSuggestions(ctx context.Context, receipt *ledger.Receipt) ([]*ledger.Transaction, error)
+	ReceiptURL(ctx context.Context, receipt *ledger.Receipt) (string, error)
+	ReceiptThumbnailURL(ctx context.Context, receipt *ledger.Receipt) (null.String, error)
 	RemoveReceiptFromTransaction(ctx context.Context, itemID, transactionID string) error
+	AddTransactionAttachment(ctx context.Context, itemID, transactionID string, file graphql.Upload) (*ledger.TransactionAttachment, error)
+	RemoveTransactionAttachment(ctx context.Context, itemID, attachmentID string) error
+	TransactionAttachmentURL(ctx context.Context, attachment *ledger.TransactionAttachment) (string, error)
+	TransactionAttachmentThumbnailURL(ctx context.Context, attachment *ledger.TransactionAttachment) (null.String, error)
+	UpdateTransactionWithChangelog(ctx context.Context, source ledger.TransactionChangeSource, actor string, transaction *ledger.Transaction) (*ledger.Transaction, error)
+	HideTransaction(ctx context.Context, source ledger.TransactionChangeSource, actor, itemID, transactionID, reason string) (*ledger.Transaction, error)
+	UnhideTransaction(ctx context.Context, source ledger.TransactionChangeSource, actor, itemID, transactionID string) (*ledger.Transaction, error)
+	UpdateRefundMatchStatus(ctx context.Context, itemID, relationID string, status ledger.TransactionRelationStatus) (*ledger.TransactionRelation, error)
+	TransactionNetAmount(ctx context.Context, transaction *ledger.Transaction) (float64, error)
 	ledger.TransactionRepository
 	ledger.MerchantRepository
+	ledger.ReceiptRepository
 }
 
 type service struct {
-	logger  *logrus.Logger
-	cache   cache.Service
-	s3      *s3.Client
-	gateway gateway.Service
-	bucket  string
-	starter ledger.Starter
+	logger       *logrus.Logger
+	anomaly      anomaly.Service
+	cache        cache.Service
+	event        event.Service
+	blobs        ledger.BlobStore
+	gateway      gateway.Service
+	refundWindow time.Duration
+	starter      ledger.Starter
 
 	ledger.TransactionRepository
 	ledger.MerchantRepository
+	ledger.ReceiptRepository
 }
 
 var allowedFileTypes = []string{
-	"application/pdf", "image/jpeg",
+	"application/pdf", "image/jpeg", "image/png", "image/webp", "image/heic", "image/heif",
 }
 
 func New(
-	s3 *s3.Client,
+	blobs ledger.BlobStore,
 	logger *logrus.Logger,
+	anomaly anomaly.Service,
 	gateway gateway.Service,
 	cache cache.Service,
-	bucket string,
+	event event.Service,
+	refundWindow time.Duration,
 	starter ledger.Starter,
 	transaction ledger.TransactionRepository,
 	merchants ledger.MerchantRepository,
+	receipts ledger.ReceiptRepository,
 ) Service {
 	return &service{
+		anomaly:               anomaly,
 		gateway:               gateway,
 		cache:                 cache,
-		s3:                    s3,
-		bucket:                bucket,
+		event:                 event,
+		blobs:                 blobs,
+		refundWindow:          refundWindow,
 		starter:               starter,
 		TransactionRepository: transaction,
 		MerchantRepository:    merchants,
+		ReceiptRepository:     receipts,
 		logger:                logger,
 	}
 
@@ -205,6 +234,35 @@ func (s *service) processTransaction(ctx context.Context, item *ledger.Item, pla
 			return errors.Errorf("failed to insert transaction %s into DB", plaidTransaction.TransactionID)
 		}
 
+		if plaidTransaction.Location != nil && !plaidTransaction.Location.IsEmpty() {
+			plaidTransaction.Location.ItemID = plaidTransaction.ItemID
+			plaidTransaction.Location.TransactionID = plaidTransaction.TransactionID
+			err = s.CreateTransactionLocation(ctx, plaidTransaction.Location)
+			if err != nil {
+				entry.WithError(err).Error("failed to store transaction location")
+			}
+		}
+
+		err = s.matchRefund(ctx, plaidTransaction)
+		if err != nil {
+			entry.WithError(err).Error("failed to match refund to original charge")
+		}
+
+		_, err = s.anomaly.ScoreTransaction(ctx, plaidTransaction)
+		if err != nil {
+			entry.WithError(err).Error("failed to score transaction for anomalies")
+		}
+
+		created, err := s.Transaction(ctx, plaidTransaction.ItemID, plaidTransaction.TransactionID)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch created transaction")
+		} else {
+			err = s.event.EmitForItem(ctx, created.ItemID, ledger.EventTransactionCreated, created)
+			if err != nil {
+				entry.WithError(err).Error("failed to emit transaction created event")
+			}
+		}
+
 		if plaidTransaction.PendingTransactionID.Valid {
 			entry = entry.WithField("pending_transaction_id", plaidTransaction.PendingTransactionID.String)
 
@@ -218,8 +276,8 @@ func (s *service) processTransaction(ctx context.Context, item *ledger.Item, pla
 				return nil
 			}
 
-			pendingTransaction.HiddenAt.SetValid(time.Now())
-			_, err = s.UpdateTransaction(ctx, pendingTransaction.TransactionID, pendingTransaction)
+			reason := fmt.Sprintf("superseded by posted transaction %s", plaidTransaction.TransactionID)
+			_, err = s.hideTransaction(ctx, ledger.TransactionChangeSourceImporter, item.ItemID, pendingTransaction, reason)
 			if err != nil {
 				entry.WithError(err).Error()
 				return errors.Errorf("failed to update transaction %s", pendingTransaction.TransactionID)
@@ -241,13 +299,19 @@ func (s *service) processTransaction(ctx context.Context, item *ledger.Item, pla
 
 	entry.Info("existing transaction discovered, updating record")
 
-	changelog, err := diff.Diff(transaction, plaidTransaction)
+	err = s.handleTransactionMerchant(ctx, plaidTransaction)
+	if err != nil {
+		entry.WithError(err).Error()
+		return errors.Wrap(err, "failed to process merchant")
+	}
+
+	changes, err := diff.Diff(transaction, plaidTransaction)
 	if err != nil {
 		entry.WithError(err).Error()
 		return errors.New("unable to determine updated attributes of transaction")
 	}
 
-	if len(changelog) == 0 {
+	if len(changes) == 0 {
 		return nil
 	}
 
@@ -257,7 +321,11 @@ func (s *service) processTransaction(ctx context.Context, item *ledger.Item, pla
 		return errors.Errorf("failed to copy plaidTransaction to ledgerTransaction")
 	}
 
-	_, err = s.UpdateTransaction(ctx, transaction.TransactionID, transaction)
+	_, err = s.updateTransaction(ctx, transaction, &ledger.TransactionChangelog{
+		Source:    ledger.TransactionChangeSourceImporter,
+		Actor:     item.ItemID,
+		Changelog: ledger.TransactionChanges(changes),
+	})
 	if err != nil {
 		entry.WithError(err).Error()
 		return errors.Errorf("failed to update transaction %s", transaction.TransactionID)
@@ -268,6 +336,141 @@ func (s *service) processTransaction(ctx context.Context, item *ledger.Item, pla
 
 }
 
+// UpdateTransactionWithChangelog diffs the provided transaction against the stored record and persists
+// it alongside a changelog attributing the change to the provided source and actor
+func (s *service) UpdateTransactionWithChangelog(ctx context.Context, source ledger.TransactionChangeSource, actor string, transaction *ledger.Transaction) (*ledger.Transaction, error) {
+
+	existing, err := s.Transaction(ctx, transaction.ItemID, transaction.TransactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UpdateTransactionWithChangelog] failed to fetch transaction")
+	}
+
+	changes, err := diff.Diff(existing, transaction)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UpdateTransactionWithChangelog] failed to diff transaction")
+	}
+
+	return s.updateTransaction(ctx, transaction, &ledger.TransactionChangelog{
+		Source:    source,
+		Actor:     actor,
+		Changelog: ledger.TransactionChanges(changes),
+	})
+
+}
+
+// updateTransaction persists the transaction and its changelog within a single db transaction.
+// Changelogs without any changes are not recorded
+func (s *service) updateTransaction(ctx context.Context, transaction *ledger.Transaction, changelog *ledger.TransactionChangelog) (*ledger.Transaction, error) {
+
+	txn, err := s.starter.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start transaction")
+	}
+
+	err = s.UpdateTransactionTx(ctx, txn, transaction.TransactionID, transaction)
+	if err != nil {
+		_ = txn.Rollback()
+		return nil, errors.Wrap(err, "failed to update transaction")
+	}
+
+	if changelog != nil && len(changelog.Changelog) > 0 {
+		changelog.ItemID = transaction.ItemID
+		changelog.TransactionID = transaction.TransactionID
+
+		err = s.CreateTransactionChangelogTx(ctx, txn, changelog)
+		if err != nil {
+			_ = txn.Rollback()
+			return nil, errors.Wrap(err, "failed to record transaction changelog")
+		}
+	}
+
+	err = txn.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	updated, err := s.Transaction(ctx, transaction.ItemID, transaction.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// An update without any changes, such as saving a transaction as it is, is not announced
+	if changelog != nil && len(changelog.Changelog) > 0 {
+		err = s.event.EmitForItem(ctx, updated.ItemID, ledger.EventTransactionUpdated, updated)
+		if err != nil {
+			s.logger.WithContext(ctx).WithError(err).WithField("transaction_id", updated.TransactionID).Error("failed to emit transaction updated event")
+		}
+	}
+
+	return updated, nil
+
+}
+
+func (s *service) HideTransaction(ctx context.Context, source ledger.TransactionChangeSource, actor, itemID, transactionID, reason string) (*ledger.Transaction, error) {
+
+	if reason == "" {
+		return nil, errors.New("a reason is required to hide a transaction")
+	}
+
+	transaction, err := s.Transaction(ctx, itemID, transactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.HideTransaction] failed to fetch transaction")
+	}
+
+	if transaction.HiddenAt.Valid {
+		return transaction, nil
+	}
+
+	return s.hideTransaction(ctx, source, actor, transaction, reason)
+
+}
+
+func (s *service) hideTransaction(ctx context.Context, source ledger.TransactionChangeSource, actor string, transaction *ledger.Transaction, reason string) (*ledger.Transaction, error) {
+
+	now := time.Now()
+	changelog := &ledger.TransactionChangelog{
+		Source: source,
+		Actor:  actor,
+		Changelog: ledger.TransactionChanges{
+			{Type: diff.UPDATE, Path: []string{"HiddenAt"}, From: transaction.HiddenAt.Ptr(), To: now},
+			{Type: diff.UPDATE, Path: []string{"HiddenReason"}, From: transaction.HiddenReason.Ptr(), To: reason},
+		},
+	}
+
+	transaction.HiddenAt.SetValid(now)
+	transaction.HiddenReason.SetValid(reason)
+
+	return s.updateTransaction(ctx, transaction, changelog)
+
+}
+
+func (s *service) UnhideTransaction(ctx context.Context, source ledger.TransactionChangeSource, actor, itemID, transactionID string) (*ledger.Transaction, error) {
+
+	transaction, err := s.Transaction(ctx, itemID, transactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.UnhideTransaction] failed to fetch transaction")
+	}
+
+	if !transaction.HiddenAt.Valid {
+		return transaction, nil
+	}
+
+	changelog := &ledger.TransactionChangelog{
+		Source: source,
+		Actor:  actor,
+		Changelog: ledger.TransactionChanges{
+			{Type: diff.UPDATE, Path: []string{"HiddenAt"}, From: transaction.HiddenAt.Ptr(), To: nil},
+			{Type: diff.UPDATE, Path: []string{"HiddenReason"}, From: transaction.HiddenReason.Ptr(), To: nil},
+		},
+	}
+
+	transaction.HiddenAt = null.NewTime(time.Time{}, false)
+	transaction.HiddenReason = null.NewString("", false)
+
+	return s.updateTransaction(ctx, transaction, changelog)
+
+}
+
 func (s *service) handleTransactionMerchant(ctx context.Context, transaction *ledger.Transaction) error {
 
 	merchantName := transaction.MerchantName.String
@@ -314,95 +517,133 @@ func (s *service) handleTransactionMerchant(ctx context.Context, transaction *le
 
 func (s *service) TransactionReceiptPresignedURL(ctx context.Context, itemID, transactionID string) (*ledger.TransactionReceipt, error) {
 
-	// urlStr, err := s.cache.FetchPresignedURL(ctx, transactionID)
-	// if err != nil {
-	// 	s.logger.WithError(err).Error("failed to fetch url from cache")
-	// }
-
-	// if urlStr != "" {
-	// 	return urlStr, nil
-	// }
-
 	transaction, err := s.Transaction(ctx, itemID, transactionID)
 	if err != nil {
 		return nil, errors.Wrap(err, "[transaction.TransactionReceiptPresignedURL] failed to fetch transaction")
 	}
 
-	psClient := s3.NewPresignClient(s.s3)
-	expireDuration := time.Minute * 10
-
 	receipt := new(ledger.TransactionReceipt)
+	if !transaction.HasReceipt {
+		return receipt, nil
+	}
 
-	_, err = s.s3.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(transaction.Filename()),
-	})
-	if err == nil {
-		get, err := psClient.PresignGetObject(ctx, &s3.GetObjectInput{
-			Bucket: aws.String(s.bucket),
-			Key:    aws.String(transaction.Filename()),
-		}, s3.WithPresignExpires(expireDuration))
-		if err != nil {
-			return nil, errors.Wrap(err, "[transaction.TransactionReceiptPresignedURL] failed to generate presigned get url for object")
-		}
+	get, err := s.blobs.SignedURL(ctx, transaction.ReceiptKey(), time.Minute*10)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.TransactionReceiptPresignedURL] failed to generate signed url for receipt")
+	}
 
-		receipt.Get = null.NewString(get.URL, get.URL != "")
+	receipt.Get = null.NewString(get, get != "")
+
+	record, err := s.ReceiptByTransactionID(ctx, itemID, transactionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "[transaction.TransactionReceiptPresignedURL] failed to fetch receipt details")
 	}
 
-	put, err := psClient.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(transaction.Filename()),
-	}, s3.WithPresignExpires(expireDuration))
-	if err != nil {
-		return nil, errors.Wrap(err, "[transaction.TransactionReceiptPresignedURL] failed to generate presigned put url for object")
+	// Receipts uploaded before thumbnails were generated do not have a record
+	if err == nil {
+		receipt.ThumbnailURL, err = s.thumbnailURL(ctx, record.ThumbnailKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "[transaction.TransactionReceiptPresignedURL]")
+		}
 	}
 
-	receipt.Put = null.NewString(put.URL, put.URL != "")
 	return receipt, nil
 
 }
 
-func (s *service) AddReceiptToTransaction(ctx context.Context, itemID, transactionID string, file graphql.Upload) error {
+// AddReceiptToTransaction stores the file as the receipt for the transaction, replacing any
+// receipt that was previously uploaded
+func (s *service) AddReceiptToTransaction(ctx context.Context, userID uuid.UUID, itemID, transactionID string, file graphql.Upload) (*ledger.Transaction, error) {
 
 	transaction, err := s.Transaction(ctx, itemID, transactionID)
 	if err != nil {
-		return errors.Wrap(err, "[transaction.AddReceiptToTransaction] failed to fetch transaction")
+		return nil, errors.Wrap(err, "[transaction.AddReceiptToTransaction] failed to fetch transaction")
 	}
 
 	err = validateContentType(file.ContentType)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	obj := s3.PutObjectInput{
-		Bucket:      aws.String(s.bucket),
-		Key:         aws.String(file.Filename),
-		Body:        file.File,
-		ContentType: aws.String(file.ContentType),
+	data, err := io.ReadAll(file.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.AddReceiptToTransaction] failed to read file")
 	}
 
-	_, err = s.s3.PutObject(ctx, &obj)
+	upload, err := processUpload(data, file.ContentType)
 	if err != nil {
-		return errors.Wrap(err, "[transaction.AddReceiptToTransaction] failed to write file to s3")
+		return nil, errors.Wrap(err, "[transaction.AddReceiptToTransaction]")
 	}
 
-	var ext string
-	switch file.ContentType {
-	case "application/pdf":
-		ext = "pdf"
-	case "image/jpeg":
-		ext = "jpg"
+	record, err := s.ReceiptByTransactionID(ctx, itemID, transactionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "[transaction.AddReceiptToTransaction] failed to fetch existing receipt")
+	}
+
+	if err != nil {
+		record = &ledger.Receipt{
+			ReceiptID: uuid.Must(uuid.NewV4()).String(),
+			UserID:    userID,
+		}
+	}
+
+	return s.storeReceipt(ctx, transaction, record, upload)
+
+}
+
+// storeReceipt writes the upload as the receipt for the transaction and records the details extracted from it
+func (s *service) storeReceipt(ctx context.Context, transaction *ledger.Transaction, record *ledger.Receipt, upload *receipt.Image) (*ledger.Transaction, error) {
+
+	var previousKey string
+	if transaction.HasReceipt {
+		previousKey = transaction.ReceiptKey()
 	}
 
 	transaction.HasReceipt = true
-	transaction.ReceiptType.SetValid(ext)
+	transaction.ReceiptType = null.StringFrom(fileExtensions[upload.ContentType])
 
-	_, err = s.UpdateTransaction(ctx, transaction.TransactionID, transaction)
+	err := s.blobs.Put(ctx, transaction.ReceiptKey(), bytes.NewReader(upload.Data), int64(len(upload.Data)), upload.ContentType)
 	if err != nil {
-		return errors.Wrap(err, "[transaction.AddReceiptToTransaction] failed to update has_receipt flag on transaction")
+		return nil, errors.Wrap(err, "[transaction.storeReceipt] failed to write file to blob store")
 	}
 
-	return nil
+	previousThumbnailKey := record.ThumbnailKey
+	record.ThumbnailKey, err = s.putThumbnail(ctx, transaction.ReceiptKey(), upload.Thumbnail)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.storeReceipt]")
+	}
+
+	if previousThumbnailKey.Valid && previousThumbnailKey != record.ThumbnailKey {
+		s.deleteThumbnail(ctx, previousThumbnailKey)
+	}
+
+	transaction, err = s.UpdateTransaction(ctx, transaction.TransactionID, transaction)
+	if err != nil {
+		return nil, errors.Wrap(err, "[transaction.storeReceipt] failed to update has_receipt flag on transaction")
+	}
+
+	// A receipt of a different type leaves the old object behind under its own extension
+	if previousKey != "" && previousKey != transaction.ReceiptKey() {
+		err = s.blobs.Delete(ctx, previousKey)
+		if err != nil {
+			s.logger.WithError(err).WithField("key", previousKey).Error("failed to delete replaced receipt")
+		}
+	}
+
+	record.ItemID = null.StringFrom(transaction.ItemID)
+	record.TransactionID = null.StringFrom(transaction.TransactionID)
+	record.ObjectKey = transaction.ReceiptKey()
+	record.ContentType = upload.ContentType
+	s.extractReceiptDetails(record, upload.Data)
+	compareReceipt(record, transaction)
+
+	err = s.saveReceipt(ctx, record)
+	if err != nil {
+		// The receipt itself has been stored, only the extracted details are missing
+		s.logger.WithError(err).WithField("transaction_id", transaction.TransactionID).Error("failed to save receipt details")
+	}
+
+	return transaction, nil
 
 }
 
@@ -413,22 +654,35 @@ func (s *service) RemoveReceiptFromTransaction(ctx context.Context, itemID, tran
 		return errors.Wrap(err, "[transaction.RemoveReceiptFromTransaction] failed to fetch transaction")
 	}
 
-	_, err = s.s3.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(transaction.Filename()),
-	})
-	if err != nil {
-		// This should be because the file does not exist.
-		s.logger.WithError(err).Error("failed to head object")
+	if !transaction.HasReceipt {
 		return nil
 	}
 
-	_, err = s.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(transaction.Filename()),
-	})
+	err = s.blobs.Delete(ctx, transaction.ReceiptKey())
+	if err != nil {
+		return errors.Wrap(err, "[transaction.RemoveReceiptFromTransaction] failed to delete receipt from blob store")
+	}
+
+	record, err := s.ReceiptByTransactionID(ctx, itemID, transactionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrap(err, "[transaction.RemoveReceiptFromTransaction] failed to fetch receipt details")
+	}
+
+	if err == nil {
+		err = s.DeleteReceipt(ctx, record.ReceiptID)
+		if err != nil {
+			return errors.Wrap(err, "[transaction.RemoveReceiptFromTransaction] failed to delete receipt details")
+		}
+
+		s.deleteThumbnail(ctx, record.ThumbnailKey)
+	}
+
+	transaction.HasReceipt = false
+	transaction.ReceiptType = null.NewString("", false)
+
+	_, err = s.UpdateTransaction(ctx, transaction.TransactionID, transaction)
 	if err != nil {
-		return errors.Wrap(err, "[transaction.RemoveReceiptFromTransaction] failed to delete transaction with S3")
+		return errors.Wrap(err, "[transaction.RemoveReceiptFromTransaction] failed to clear has_receipt flag on transaction")
 	}
 
 	return nil
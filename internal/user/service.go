@@ -2,7 +2,9 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/pkg/errors"
@@ -10,11 +12,13 @@ import (
 	"github.com/ddouglas/ledger"
 	"github.com/gofrs/uuid"
 	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/volatiletech/null"
 )
 
 type Service interface {
 	UserFromToken(ctx context.Context, token jwt.Token) (*ledger.User, error)
 	FetchOrCreateUser(ctx context.Context, user *ledger.User) (*ledger.User, error)
+	RotateCalendarToken(ctx context.Context, user *ledger.User) (*ledger.User, error)
 	ledger.UserRepository
 }
 
@@ -122,7 +126,26 @@ func (s *service) FetchOrCreateUser(ctx context.Context, newUser *ledger.User) (
 	}
 
 	newUser.ID = id
+	if newUser.BaseCurrency == "" {
+		newUser.BaseCurrency = ledger.DefaultBaseCurrency
+	}
 
 	return s.UserRepository.CreateUser(ctx, newUser)
 
 }
+
+// RotateCalendarToken generates a new token for the calendar feed of the user, which stops the
+// previous feed url from working
+func (s *service) RotateCalendarToken(ctx context.Context, user *ledger.User) (*ledger.User, error) {
+
+	var b = make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "[user.RotateCalendarToken] failed to generate token")
+	}
+
+	user.CalendarToken = null.StringFrom(hex.EncodeToString(b))
+
+	return s.UpdateUser(ctx, user.ID, user)
+
+}
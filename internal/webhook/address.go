@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// internalNetworks are the ranges events are never sent to, the loopback, private, link local,
+// unspecified and multicast addresses of IPv4 and IPv6 along with the shared address space carriers
+// use for NAT. Metadata services of cloud providers live at link local addresses
+var internalNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// internalSuffixes are the domains that only resolve inside a network
+var internalSuffixes = []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+
+	var networks = make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+
+}
+
+func isInternalIP(ip net.IP) bool {
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateHostname refuses the hosts of urls that point into a network rather than at the internet.
+// Addresses are refused whether or not they are public, so that every endpoint is named by a domain
+func validateHostname(hostname string) error {
+
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if net.ParseIP(hostname) != nil {
+		return errors.New("the url of an endpoint must use a domain name, not an ip address")
+	}
+
+	if hostname == "localhost" || !strings.Contains(hostname, ".") {
+		return errors.Errorf("%s is not a public domain name", hostname)
+	}
+
+	for _, suffix := range internalSuffixes {
+		if strings.HasSuffix(hostname, suffix) {
+			return errors.Errorf("%s is not a public domain name", hostname)
+		}
+	}
+
+	return nil
+
+}
+
+// dialControl runs after the host of an endpoint has been resolved and before it is connected to,
+// so an endpoint whose domain resolves to an internal address, including one that changed since
+// the endpoint was saved, is refused without a connection ever being made
+func dialControl(network, address string, _ syscall.RawConn) error {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || isInternalIP(ip) {
+		return errors.New("the endpoint resolves to an address that is not public")
+	}
+
+	return nil
+
+}
@@ -0,0 +1,514 @@
+// Package webhook provides service access to the endpoints users register to receive their events at,
+// and delivers those events through a queue in Redis
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ddouglas/ledger"
+	"github.com/ddouglas/ledger/internal"
+	"github.com/go-redis/redis/v8"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/volatiletech/null"
+)
+
+const (
+	// QueueDeliveries is the list deliveries wait in until the worker picks them up
+	QueueDeliveries = "webhook-deliveries"
+	// QueueDeliveriesProcessing holds the delivery the worker is making, so that it is put back on
+	// the queue if the worker stops before finishing it
+	QueueDeliveriesProcessing = "webhook-deliveries-processing"
+	// QueueDeliveriesRetry is a sorted set of the deliveries that failed, scored by when they are retried
+	QueueDeliveriesRetry = "webhook-deliveries-retry"
+
+	// maxAttempts is how many times an event is sent to an endpoint before it is given up on. With
+	// the backoff below the last attempt is made a little over two hours after the first
+	maxAttempts = 8
+	// retryBackoff is how long after the first failed attempt an event is retried, every attempt
+	// after that waits twice as long as the one before
+	retryBackoff = time.Minute
+
+	deliveryTimeout = time.Second * 10
+)
+
+type Service interface {
+	HandleEvent(ctx context.Context, event *ledger.Event)
+	Run(ctx context.Context)
+	RotateWebhookEndpointSecret(ctx context.Context, userID uuid.UUID, endpointID string) (*ledger.WebhookEndpoint, error)
+	ledger.WebhookEndpointRepository
+}
+
+type service struct {
+	logger *logrus.Logger
+	client *http.Client
+	redis  *redis.Client
+
+	ledger.WebhookEndpointRepository
+}
+
+func New(logger *logrus.Logger, client *redis.Client, endpoints ledger.WebhookEndpointRepository) Service {
+	return &service{
+		logger: logger,
+		client: &http.Client{
+			Timeout: deliveryTimeout,
+			// Environment proxies are not used, the dialer has to see the address of the endpoint
+			// to refuse internal ones
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout: deliveryTimeout,
+					Control: dialControl,
+				}).DialContext,
+				TLSHandshakeTimeout: deliveryTimeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     time.Second * 90,
+			},
+			// A redirect could send the event somewhere other than the https url the user registered,
+			// the redirect response is treated as a failed delivery instead
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		redis:                     client,
+		WebhookEndpointRepository: endpoints,
+	}
+}
+
+// delivery is an event waiting to be sent to an endpoint. Payload is encoded once, when the event
+// is queued, so that every attempt sends and signs the same body
+type delivery struct {
+	EndpointID string           `json:"endpointID"`
+	UserID     uuid.UUID        `json:"userID"`
+	EventID    string           `json:"eventID"`
+	EventType  ledger.EventType `json:"eventType"`
+	Attempt    uint             `json:"attempt"`
+	Payload    json.RawMessage  `json:"payload"`
+}
+
+func (s *service) CreateWebhookEndpoint(ctx context.Context, endpoint *ledger.WebhookEndpoint) (*ledger.WebhookEndpoint, error) {
+
+	endpoint.EndpointID = uuid.Must(uuid.NewV4()).String()
+
+	err := validateWebhookEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Secret, err = generateSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "[webhook.CreateWebhookEndpoint]")
+	}
+
+	return s.WebhookEndpointRepository.CreateWebhookEndpoint(ctx, endpoint)
+
+}
+
+func (s *service) UpdateWebhookEndpoint(ctx context.Context, endpointID string, endpoint *ledger.WebhookEndpoint) (*ledger.WebhookEndpoint, error) {
+
+	err := validateWebhookEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.WebhookEndpointRepository.UpdateWebhookEndpoint(ctx, endpointID, endpoint)
+
+}
+
+// RotateWebhookEndpointSecret replaces the secret events to the endpoint are signed with. Deliveries
+// that are already queued are signed with the new secret when they are sent
+func (s *service) RotateWebhookEndpointSecret(ctx context.Context, userID uuid.UUID, endpointID string) (*ledger.WebhookEndpoint, error) {
+
+	endpoint, err := s.WebhookEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, errors.Wrap(err, "[webhook.RotateWebhookEndpointSecret] failed to fetch endpoint")
+	}
+
+	endpoint.Secret, err = generateSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "[webhook.RotateWebhookEndpointSecret]")
+	}
+
+	return s.WebhookEndpointRepository.UpdateWebhookEndpoint(ctx, endpointID, endpoint)
+
+}
+
+func validateWebhookEndpoint(endpoint *ledger.WebhookEndpoint) error {
+
+	endpoint.URL = strings.TrimSpace(endpoint.URL)
+	if len(endpoint.URL) > 2048 {
+		return errors.New("the url of an endpoint must be at most 2048 characters")
+	}
+
+	parsed, err := url.Parse(endpoint.URL)
+	if err != nil || parsed.Hostname() == "" {
+		return errors.Errorf("%s is not a valid url", endpoint.URL)
+	}
+
+	if parsed.Scheme != "https" {
+		return errors.New("endpoints must use https")
+	}
+
+	if parsed.User != nil {
+		return errors.New("the url of an endpoint must not contain credentials, verify requests with the signing secret instead")
+	}
+
+	err = validateHostname(parsed.Hostname())
+	if err != nil {
+		return err
+	}
+
+	if endpoint.Description.Valid {
+		endpoint.Description.String = strings.TrimSpace(endpoint.Description.String)
+		endpoint.Description.Valid = endpoint.Description.String != ""
+		if len(endpoint.Description.String) > 255 {
+			return errors.New("the description of an endpoint must be at most 255 characters")
+		}
+	}
+
+	var seen = make(map[ledger.EventType]bool)
+	var eventTypes = make(ledger.EventTypes, 0, len(endpoint.EventTypes))
+	for _, eventType := range endpoint.EventTypes {
+		if !eventType.Valid() {
+			return errors.Errorf("%s is not a valid event type, valid types are transaction.created, transaction.updated, item.error, import.completed, alert.fired and subscription.detected", eventType)
+		}
+
+		if seen[eventType] {
+			continue
+		}
+		seen[eventType] = true
+		eventTypes = append(eventTypes, eventType)
+	}
+	endpoint.EventTypes = eventTypes
+
+	return nil
+
+}
+
+func generateSecret() (string, error) {
+
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate secret")
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+
+}
+
+// HandleEvent queues a delivery of the event to every enabled endpoint of its user that is subscribed
+// to it. It is subscribed to the event emitter, so errors are logged rather than returned
+func (s *service) HandleEvent(ctx context.Context, event *ledger.Event) {
+
+	entry := s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"event_id":   event.EventID,
+		"event_type": event.Type,
+		"user_id":    event.UserID,
+	})
+
+	endpoints, err := s.WebhookEndpoints(ctx, event.UserID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch webhook endpoints of user")
+		return
+	}
+
+	var payload json.RawMessage
+	for _, endpoint := range endpoints {
+		if !endpoint.Enabled || !endpoint.Subscribed(event.Type) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(event)
+			if err != nil {
+				entry.WithError(err).Error("failed to encode event")
+				return
+			}
+		}
+
+		err = s.enqueue(ctx, &delivery{
+			EndpointID: endpoint.EndpointID,
+			UserID:     event.UserID,
+			EventID:    event.EventID,
+			EventType:  event.Type,
+			Attempt:    1,
+			Payload:    payload,
+		})
+		if err != nil {
+			entry.WithError(err).WithField("endpoint_id", endpoint.EndpointID).Error("failed to queue webhook delivery")
+		}
+	}
+
+}
+
+func (s *service) enqueue(ctx context.Context, d *delivery) error {
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode delivery")
+	}
+
+	_, err = s.redis.LPush(ctx, QueueDeliveries, data).Result()
+
+	return errors.Wrap(err, "failed to push delivery")
+
+}
+
+// Run delivers queued events until ctx is cancelled. A delivery is moved onto a processing list
+// while it is being made, so deliveries that were in flight when the worker last stopped are put
+// back on the queue when it starts. This assumes a single worker delivers webhooks
+func (s *service) Run(ctx context.Context) {
+
+	entry := s.logger.WithFields(logrus.Fields{
+		"service": "Webhook",
+		"channel": QueueDeliveries,
+	})
+
+	for {
+		_, err := s.redis.RPopLPush(ctx, QueueDeliveriesProcessing, QueueDeliveries).Result()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			entry.WithError(err).Error("failed to requeue deliveries that were in flight")
+			break
+		}
+	}
+
+	entry.Info("Monitoring Redis Queue for Deliveries")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		err := s.promoteRetries(ctx)
+		if err != nil {
+			entry.WithError(err).Error("failed to move due retries onto the queue")
+		}
+
+		data, err := s.redis.RPopLPush(ctx, QueueDeliveries, QueueDeliveriesProcessing).Result()
+		if errors.Is(err, redis.Nil) {
+			sleep()
+			continue
+		}
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch deliveries from queue")
+			sleep()
+			continue
+		}
+
+		var d = new(delivery)
+		err = json.Unmarshal([]byte(data), d)
+		if err != nil {
+			entry.WithError(err).Error("failed to decode delivery, dropping it")
+		} else {
+			s.deliver(ctx, d)
+		}
+
+		_, err = s.redis.LRem(ctx, QueueDeliveriesProcessing, 1, data).Result()
+		if err != nil {
+			entry.WithError(err).Error("failed to remove delivery from processing list")
+		}
+	}
+
+}
+
+func sleep() {
+	time.Sleep(time.Second * 1)
+}
+
+// promoteRetries moves the retries that are due onto the queue
+func (s *service) promoteRetries(ctx context.Context) error {
+
+	due, err := s.redis.ZRangeByScore(ctx, QueueDeliveriesRetry, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, data := range due {
+		_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, QueueDeliveriesRetry, data)
+			pipe.LPush(ctx, QueueDeliveries, data)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+// deliver makes one attempt at sending a delivery, records it in the delivery log and schedules the
+// next attempt if it failed
+func (s *service) deliver(ctx context.Context, d *delivery) {
+
+	entry := s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"endpoint_id": d.EndpointID,
+		"event_id":    d.EventID,
+		"event_type":  d.EventType,
+		"attempt":     d.Attempt,
+	})
+
+	endpoint, err := s.WebhookEndpoint(ctx, d.UserID, d.EndpointID)
+	if errors.Is(err, sql.ErrNoRows) {
+		entry.Debug("endpoint has been deleted, dropping delivery")
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch webhook endpoint")
+		s.retry(ctx, d)
+		return
+	}
+
+	if !endpoint.Enabled {
+		entry.Debug("endpoint is disabled, dropping delivery")
+		return
+	}
+
+	record := &ledger.WebhookDelivery{
+		DeliveryID: uuid.Must(uuid.NewV4()).String(),
+		EndpointID: d.EndpointID,
+		UserID:     d.UserID,
+		EventID:    d.EventID,
+		EventType:  d.EventType,
+		Attempt:    d.Attempt,
+	}
+
+	retry := true
+	start := time.Now()
+	if endpoint.Secret == "" {
+		// Restoring a backup without its secrets leaves endpoints that cannot sign their events
+		record.Error = null.StringFrom("the endpoint does not have a signing secret, rotate its secret to resume deliveries")
+		retry = false
+	} else {
+		status, err := s.send(ctx, endpoint, record.DeliveryID, d, start)
+		if status != 0 {
+			record.StatusCode = null.IntFrom(status)
+		}
+		if err != nil {
+			record.Error = null.StringFrom(internal.Truncate(err.Error(), 1024))
+		}
+		record.Succeeded = err == nil
+	}
+	record.DurationMS = uint(time.Since(start).Milliseconds())
+
+	err = s.CreateWebhookDelivery(ctx, record)
+	if err != nil {
+		entry.WithError(err).Error("failed to record webhook delivery")
+	}
+
+	if record.Succeeded {
+		entry.Debug("webhook delivered successfully")
+		return
+	}
+
+	entry.WithField("error", record.Error.String).Warn("webhook delivery failed")
+	if retry {
+		s.retry(ctx, d)
+	}
+
+}
+
+// send posts the payload of the delivery to the endpoint, returning the status code of the response
+// when there was one. Any status other than 2xx is an error
+func (s *service) send(ctx context.Context, endpoint *ledger.WebhookEndpoint, deliveryID string, d *delivery, now time.Time) (int, error) {
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to build request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Ledger-Webhooks/1.0")
+	req.Header.Set("X-Ledger-Event", string(d.EventType))
+	req.Header.Set("X-Ledger-Delivery", deliveryID)
+	req.Header.Set("X-Ledger-Signature", fmt.Sprintf("t=%s,v1=%s", timestamp, Sign(endpoint.Secret, timestamp, d.Payload)))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// The body is read so the connection can be reused, what is in it does not matter
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, errors.Errorf("endpoint responded with %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+
+}
+
+// retry schedules the next attempt of a delivery, unless it has been attempted maxAttempts times
+func (s *service) retry(ctx context.Context, d *delivery) {
+
+	entry := s.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"endpoint_id": d.EndpointID,
+		"event_id":    d.EventID,
+		"attempt":     d.Attempt,
+	})
+
+	if d.Attempt >= maxAttempts {
+		entry.Warn("webhook delivery failed too many times, giving up")
+		return
+	}
+
+	backoff := retryBackoff << (d.Attempt - 1)
+	d.Attempt++
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		entry.WithError(err).Error("failed to encode delivery")
+		return
+	}
+
+	_, err = s.redis.ZAdd(ctx, QueueDeliveriesRetry, &redis.Z{
+		Score:  float64(time.Now().Add(backoff).Unix()),
+		Member: data,
+	}).Result()
+	if err != nil {
+		entry.WithError(err).Error("failed to schedule webhook delivery retry")
+	}
+
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the timestamp and payload, joined by a period, keyed
+// with secret. Receivers compute the same value from the X-Ledger-Signature header to verify a request
+func Sign(secret, timestamp string, payload []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -41,6 +42,20 @@ type Item struct {
 	// Institution *PlaidInstitution `json:"institution,omitempty" deepcopier:"skip"`
 }
 
+// manualItemPrefix marks the items that hold accounts created by hand rather than linked through Plaid
+const manualItemPrefix = "manual-"
+
+// ManualItemID returns the id of the item that holds the manual accounts of the user
+func ManualItemID(userID uuid.UUID) string {
+	return manualItemPrefix + userID.String()
+}
+
+// IsManual reports whether the item holds manual accounts. Manual items do not have an access token
+// and never receive webhooks, their transactions are imported from statement files
+func (i *Item) IsManual() bool {
+	return strings.HasPrefix(i.ItemID, manualItemPrefix)
+}
+
 type ItemStatus plaid.ItemStatus
 
 func (s ItemStatus) Value() (driver.Value, error) {
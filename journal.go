@@ -0,0 +1,64 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type JournalRepository interface {
+	JournalAccountMapping(ctx context.Context, userID uuid.UUID, sourceType JournalSourceType, sourceID string) (*JournalAccountMapping, error)
+	JournalAccountMappings(ctx context.Context, userID uuid.UUID) ([]*JournalAccountMapping, error)
+	SaveJournalAccountMapping(ctx context.Context, mapping *JournalAccountMapping) (*JournalAccountMapping, error)
+	DeleteJournalAccountMapping(ctx context.Context, userID uuid.UUID, sourceType JournalSourceType, sourceID string) error
+}
+
+// JournalFormat is the plain text accounting format a journal is written in
+type JournalFormat string
+
+const (
+	JournalFormatLedger    JournalFormat = "ledger"
+	JournalFormatHledger   JournalFormat = "hledger"
+	JournalFormatBeancount JournalFormat = "beancount"
+)
+
+func (f JournalFormat) Valid() bool {
+	return f == JournalFormatLedger || f == JournalFormatHledger || f == JournalFormatBeancount
+}
+
+// Extension returns the file extension journals in the format are conventionally saved with
+func (f JournalFormat) Extension() string {
+	switch f {
+	case JournalFormatHledger:
+		return "journal"
+	case JournalFormatBeancount:
+		return "beancount"
+	}
+
+	return "ledger"
+}
+
+// JournalSourceType is what a journal account mapping renames
+type JournalSourceType string
+
+const (
+	JournalSourceTypeAccount  JournalSourceType = "account"
+	JournalSourceTypeCategory JournalSourceType = "category"
+)
+
+func (t JournalSourceType) Valid() bool {
+	return t == JournalSourceTypeAccount || t == JournalSourceTypeCategory
+}
+
+// JournalAccountMapping replaces the journal account name generated for an account or a category.
+// SourceID is the id of the account or category. AccountName is a full account name with its
+// components separated by colons, such as Assets:Bank:Checking
+type JournalAccountMapping struct {
+	UserID      uuid.UUID         `db:"user_id" json:"userID"`
+	SourceType  JournalSourceType `db:"source_type" json:"sourceType"`
+	SourceID    string            `db:"source_id" json:"sourceID"`
+	AccountName string            `db:"account_name" json:"accountName"`
+	CreatedAt   time.Time         `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time         `db:"updated_at" json:"updatedAt"`
+}
@@ -0,0 +1,82 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+type NotificationRepository interface {
+	NotificationPreferences(ctx context.Context, userID uuid.UUID) (*NotificationPreferences, error)
+	NotificationPreferencesByDigest(ctx context.Context, digest DigestFrequency) ([]*NotificationPreferences, error)
+	SaveNotificationPreferences(ctx context.Context, preferences *NotificationPreferences) (*NotificationPreferences, error)
+
+	Notification(ctx context.Context, userID uuid.UUID, notificationID string) (*Notification, error)
+	Notifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit uint64) ([]*Notification, error)
+	UnreadNotificationCount(ctx context.Context, userID uuid.UUID) (int, error)
+	CreateNotification(ctx context.Context, notification *Notification) (*Notification, error)
+	MarkNotificationRead(ctx context.Context, userID uuid.UUID, notificationID string) error
+	MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) error
+}
+
+// Mailer delivers email messages
+type Mailer interface {
+	Send(ctx context.Context, message *EmailMessage) error
+}
+
+// EmailMessage is an email with a plain text body and an optional HTML alternative
+type EmailMessage struct {
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// DigestFrequency is how often a user is sent a digest of their transactions
+type DigestFrequency string
+
+const (
+	DigestFrequencyNone   DigestFrequency = "none"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+func (f DigestFrequency) Valid() bool {
+	return f == DigestFrequencyNone || f == DigestFrequencyDaily || f == DigestFrequencyWeekly
+}
+
+// NotificationPreferences are the emails a user has chosen to receive. Users without saved
+// preferences are sent new transaction emails and no digest
+type NotificationPreferences struct {
+	UserID          uuid.UUID       `db:"user_id" json:"userID"`
+	NewTransactions bool            `db:"new_transactions" json:"newTransactions"`
+	Digest          DigestFrequency `db:"digest" json:"digest"`
+	CreatedAt       time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time       `db:"updated_at" json:"updatedAt"`
+}
+
+// DefaultNotificationPreferences returns the preferences of a user that has not saved any
+func DefaultNotificationPreferences(userID uuid.UUID) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:          userID,
+		NewTransactions: true,
+		Digest:          DigestFrequencyNone,
+	}
+}
+
+// Notification is a message shown in the inbox of a user. Type is the event the notification was
+// created for, ItemID and ReferenceID point at what it is about, such as the alert or recurring series.
+// Notifications are unread until ReadAt is set
+type Notification struct {
+	NotificationID string      `db:"notification_id" json:"notificationID"`
+	UserID         uuid.UUID   `db:"user_id" json:"userID"`
+	Type           EventType   `db:"type" json:"type"`
+	Title          string      `db:"title" json:"title"`
+	Message        string      `db:"message" json:"message"`
+	ItemID         null.String `db:"item_id" json:"itemID"`
+	ReferenceID    null.String `db:"reference_id" json:"referenceID"`
+	ReadAt         null.Time   `db:"read_at" json:"readAt"`
+	CreatedAt      time.Time   `db:"created_at" json:"createdAt"`
+}
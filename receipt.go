@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+type ReceiptRepository interface {
+	Receipt(ctx context.Context, userID uuid.UUID, receiptID string) (*Receipt, error)
+	ReceiptByTransactionID(ctx context.Context, itemID, transactionID string) (*Receipt, error)
+	UnmatchedReceipts(ctx context.Context, userID uuid.UUID) ([]*Receipt, error)
+	SearchReceipts(ctx context.Context, userID uuid.UUID, term string) ([]*Receipt, error)
+	CreateReceipt(ctx context.Context, receipt *Receipt) (*Receipt, error)
+	UpdateReceipt(ctx context.Context, receiptID string, receipt *Receipt) (*Receipt, error)
+	DeleteReceipt(ctx context.Context, receiptID string) error
+}
+
+// Receipt holds the details extracted from an uploaded receipt. Receipts that were uploaded
+// before the transaction they belong to was known have a null ItemID and TransactionID until
+// they are assigned to one
+type Receipt struct {
+	ReceiptID      string       `db:"receipt_id" json:"receiptID"`
+	UserID         uuid.UUID    `db:"user_id" json:"userID"`
+	ItemID         null.String  `db:"item_id" json:"itemID"`
+	TransactionID  null.String  `db:"transaction_id" json:"transactionID"`
+	ObjectKey      string       `db:"object_key" json:"-"`
+	ThumbnailKey   null.String  `db:"thumbnail_key" json:"-"`
+	ContentType    string       `db:"content_type" json:"contentType"`
+	Text           null.String  `db:"text" json:"text"`
+	Total          null.Float64 `db:"total" json:"total"`
+	Date           null.Time    `db:"receipt_date" json:"date"`
+	MerchantName   null.String  `db:"merchant_name" json:"merchantName"`
+	AmountMismatch bool         `db:"amount_mismatch" json:"amountMismatch"`
+	DateMismatch   bool         `db:"date_mismatch" json:"dateMismatch"`
+	CreatedAt      time.Time    `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time    `db:"updated_at" json:"updatedAt"`
+}
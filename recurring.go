@@ -0,0 +1,179 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
+)
+
+type RecurringRepository interface {
+	RecurringSeries(ctx context.Context, userID uuid.UUID, seriesID string) (*RecurringSeries, error)
+	RecurringSeriesByUserID(ctx context.Context, userID uuid.UUID) ([]*RecurringSeries, error)
+	SaveRecurringSeries(ctx context.Context, series *RecurringSeries) error
+	DeleteRecurringSeries(ctx context.Context, userID uuid.UUID, seriesID string) error
+}
+
+// RecurringFrequency is how often the charges of a recurring series are made
+type RecurringFrequency string
+
+const (
+	RecurringFrequencyWeekly  RecurringFrequency = "weekly"
+	RecurringFrequencyMonthly RecurringFrequency = "monthly"
+	RecurringFrequencyAnnual  RecurringFrequency = "annual"
+)
+
+func (f RecurringFrequency) Valid() bool {
+	switch f {
+	case RecurringFrequencyWeekly, RecurringFrequencyMonthly, RecurringFrequencyAnnual:
+		return true
+	}
+
+	return false
+}
+
+// Next returns the date the charge following one made on date is expected
+func (f RecurringFrequency) Next(date time.Time) time.Time {
+	return f.Add(date, 1)
+}
+
+// Add returns the date n charges after one made on date. Counting from the same date, rather than
+// repeatedly calling Next, keeps a charge made late in the month from drifting. Monthly and annual
+// charges on a day the target month does not have fall on its last day instead
+func (f RecurringFrequency) Add(date time.Time, n int) time.Time {
+	switch f {
+	case RecurringFrequencyWeekly:
+		return date.AddDate(0, 0, 7*n)
+	case RecurringFrequencyAnnual:
+		return addMonths(date, 12*n)
+	}
+
+	return addMonths(date, n)
+}
+
+func addMonths(date time.Time, n int) time.Time {
+	y, m, d := date.Date()
+	first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, date.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+
+	return time.Date(first.Year(), first.Month(), d, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+}
+
+// RecurringKind is whether the money of a recurring series leaves or enters the account
+type RecurringKind string
+
+const (
+	RecurringKindBill   RecurringKind = "bill"
+	RecurringKindIncome RecurringKind = "income"
+)
+
+func (k RecurringKind) Valid() bool {
+	return k == RecurringKindBill || k == RecurringKindIncome
+}
+
+// RecurringSource is where a recurring series came from
+type RecurringSource string
+
+const (
+	// RecurringSourceDetected series were found in the transaction history and are replaced every
+	// time it is mined
+	RecurringSourceDetected RecurringSource = "detected"
+	// RecurringSourceUser series were created by the user and are never changed by detection
+	RecurringSourceUser RecurringSource = "user"
+)
+
+type RecurringStatus string
+
+const (
+	// RecurringStatusActive series have been charged on schedule
+	RecurringStatusActive RecurringStatus = "active"
+	// RecurringStatusMissing series have not been charged for longer than expected
+	RecurringStatusMissing RecurringStatus = "missing"
+)
+
+// RecurringSeries is a stream of transactions that are made at a regular interval for a consistent
+// amount, such as a subscription or a paycheck. Amounts are reported as positive numbers in the
+// currency of the transactions and Kind says which direction the money moves. When the amount of the
+// series has changed, PreviousAmount is what it was before the most recent change and PriceChangedAt
+// is the date of the first transaction at the new amount. ItemID and AccountID are the account the
+// series is paid from or into, detected series use the account of their most recent charge. LastDate
+// is null for series created by the user
+type RecurringSeries struct {
+	SeriesID         string             `db:"series_id" json:"seriesID"`
+	UserID           uuid.UUID          `db:"user_id" json:"userID"`
+	Name             string             `db:"name" json:"name"`
+	Kind             RecurringKind      `db:"kind" json:"kind"`
+	Source           RecurringSource    `db:"source" json:"source"`
+	ItemID           null.String        `db:"item_id" json:"itemID"`
+	AccountID        null.String        `db:"account_id" json:"accountID"`
+	MerchantID       null.String        `db:"merchant_id" json:"merchantID"`
+	CurrencyCode     string             `db:"currency_code" json:"currencyCode"`
+	Frequency        RecurringFrequency `db:"frequency" json:"frequency"`
+	Status           RecurringStatus    `db:"status" json:"status"`
+	Amount           float64            `db:"amount" json:"amount"`
+	PreviousAmount   null.Float64       `db:"previous_amount" json:"previousAmount"`
+	PriceChangedAt   null.Time          `db:"price_changed_at" json:"priceChangedAt"`
+	TransactionCount int64              `db:"transaction_count" json:"transactionCount"`
+	FirstDate        time.Time          `db:"first_date" json:"firstDate"`
+	LastDate         null.Time          `db:"last_date" json:"lastDate"`
+	NextDate         time.Time          `db:"next_date" json:"nextDate"`
+	CreatedAt        time.Time          `db:"created_at" json:"createdAt"`
+	UpdatedAt        time.Time          `db:"updated_at" json:"updatedAt"`
+}
+
+// PriceIncreased reports whether the most recent change to the amount of the series was an increase
+func (s *RecurringSeries) PriceIncreased() bool {
+	return s.PreviousAmount.Valid && s.Amount > s.PreviousAmount.Float64
+}
+
+// SignedAmount returns the amount of the series with the sign used by transactions, negative for bills
+func (s *RecurringSeries) SignedAmount() float64 {
+	if s.Kind == RecurringKindIncome {
+		return s.Amount
+	}
+
+	return -s.Amount
+}
+
+// Occurrences returns the dates the series is expected between from and to, inclusive, counting
+// forward from NextDate
+func (s *RecurringSeries) Occurrences(from, to time.Time) []time.Time {
+
+	var dates = make([]time.Time, 0)
+	for n := 0; ; n++ {
+		date := s.Frequency.Add(s.NextDate, n)
+		if date.After(to) {
+			break
+		}
+
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+
+}
+
+// ProjectedBalance is the expected balance of an account after the recurring series paid from or
+// into it over the coming days. Transactions are ordered by date
+type ProjectedBalance struct {
+	ItemID       string                  `json:"itemID"`
+	AccountID    string                  `json:"accountID"`
+	Currency     string                  `json:"currency"`
+	Current      float64                 `json:"current"`
+	Projected    float64                 `json:"projected"`
+	Transactions []*ProjectedTransaction `json:"transactions"`
+}
+
+// ProjectedTransaction is a single expected transaction of a recurring series. Amount is signed like
+// a transaction and Balance is the balance of the account once it has been made
+type ProjectedTransaction struct {
+	Date    time.Time        `json:"date"`
+	Series  *RecurringSeries `json:"series"`
+	Amount  float64          `json:"amount"`
+	Balance float64          `json:"balance"`
+}
@@ -0,0 +1,142 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/volatiletech/null"
+)
+
+type SpendingGroupBy string
+
+const (
+	SpendingGroupByCategory SpendingGroupBy = "category"
+	SpendingGroupByMerchant SpendingGroupBy = "merchant"
+	SpendingGroupByAccount  SpendingGroupBy = "account"
+)
+
+func (g SpendingGroupBy) Valid() bool {
+	switch g {
+	case SpendingGroupByCategory, SpendingGroupByMerchant, SpendingGroupByAccount:
+		return true
+	}
+
+	return false
+}
+
+// SpendingTotal is the sum of a group of transactions that share a currency and date. Key is the
+// category, merchant or account id the transactions were grouped by and is null for
+// transactions without a category. ItemID is only set when grouping by account
+type SpendingTotal struct {
+	Key          null.String `db:"group_key"`
+	ItemID       null.String `db:"item_id"`
+	Date         time.Time   `db:"date"`
+	CurrencyCode string      `db:"currency_code"`
+	Total        float64     `db:"total"`
+	Count        int64       `db:"count"`
+}
+
+// SpendingGroup is the sum of a group of transactions across every currency, converted
+// into the base currency of the user. Unconverted is the number of transactions left out
+// of Total because there is no exchange rate for their currency on their date
+type SpendingGroup struct {
+	GroupBy     SpendingGroupBy `json:"groupBy"`
+	Key         null.String     `json:"key"`
+	ItemID      null.String     `json:"itemID"`
+	Currency    string          `json:"currency"`
+	Total       float64         `json:"total"`
+	Count       int64           `json:"count"`
+	Average     float64         `json:"average"`
+	Unconverted int64           `json:"unconverted"`
+}
+
+// Interval is the length of the periods that a time series is bucketed into
+type Interval string
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+	IntervalYear  Interval = "year"
+)
+
+func (i Interval) Valid() bool {
+	switch i {
+	case IntervalDay, IntervalWeek, IntervalMonth, IntervalYear:
+		return true
+	}
+
+	return false
+}
+
+// Start returns the start of the interval that t falls in. Weeks start on a Monday
+func (i Interval) Start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch i {
+	case IntervalWeek:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case IntervalYear:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// Next returns the start of the interval that follows the one starting at start
+func (i Interval) Next(start time.Time) time.Time {
+	switch i {
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	case IntervalYear:
+		return start.AddDate(1, 0, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+// CashFlowTotal is the income and expenses of the transactions in a single currency that posted
+// on Date, in the interval starting at Period. Expenses are reported as a positive amount
+type CashFlowTotal struct {
+	Period       time.Time `db:"period"`
+	Date         time.Time `db:"date"`
+	CurrencyCode string    `db:"currency_code"`
+	Income       float64   `db:"income"`
+	Expenses     float64   `db:"expenses"`
+	Count        int64     `db:"count"`
+}
+
+// CashFlowPeriod is the income and expenses of an interval across every currency, converted into
+// the base currency of the user. SavingsRate is the share of income that was not spent and is null
+// for periods without any income. Unconverted is the number of transactions left out of the totals
+// because there is no exchange rate for their currency on their date
+type CashFlowPeriod struct {
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	Currency    string       `json:"currency"`
+	Income      float64      `json:"income"`
+	Expenses    float64      `json:"expenses"`
+	Net         float64      `json:"net"`
+	SavingsRate null.Float64 `json:"savingsRate"`
+	Unconverted int64        `json:"unconverted"`
+}
+
+// NetWorthPeriod is the total of the users assets and liabilities on Date, converted into the base
+// currency of the user. Liabilities are the balances owed on credit and loan accounts and are
+// reported as a positive amount. Unconverted is the number of account balances left out of the
+// totals because there is no exchange rate to convert them with
+type NetWorthPeriod struct {
+	Date        time.Time `json:"date"`
+	Currency    string    `json:"currency"`
+	Assets      float64   `json:"assets"`
+	Liabilities float64   `json:"liabilities"`
+	NetWorth    float64   `json:"netWorth"`
+	Unconverted int64     `json:"unconverted"`
+}
+
+// IsLiability reports whether the balance of an account of accountType is owed rather than owned
+func IsLiability(accountType string) bool {
+	return accountType == "credit" || accountType == "loan"
+}
@@ -0,0 +1,57 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/volatiletech/null"
+)
+
+type StatementRepository interface {
+	StatementProfile(ctx context.Context, itemID, accountID string) (*StatementProfile, error)
+	SaveStatementProfile(ctx context.Context, profile *StatementProfile) (*StatementProfile, error)
+}
+
+// StatementFormat is the file format of a statement imported for an account. QFX files are OFX
+// files and are imported as such
+type StatementFormat string
+
+const (
+	StatementFormatOFX StatementFormat = "ofx"
+	StatementFormatCSV StatementFormat = "csv"
+)
+
+// StatementProfile describes the layout of the CSV statements of an account. Columns are counted
+// from zero. SkipRows is the number of rows before the first transaction, including any header.
+// Amounts are read from AmountColumn, or when it is not set from DebitColumn and CreditColumn, with
+// debits taken as outflows. InvertAmounts flips the sign of every amount, for institutions that
+// report money leaving the account as a positive amount. IDColumn holds a reference the
+// institution assigns to each transaction, it is used to recognise transactions that were already
+// imported when it is set
+type StatementProfile struct {
+	ItemID            string           `db:"item_id" json:"itemID"`
+	AccountID         string           `db:"account_id" json:"accountID"`
+	Delimiter         string           `db:"delimiter" json:"delimiter"`
+	SkipRows          int              `db:"skip_rows" json:"skipRows"`
+	DateColumn        int              `db:"date_column" json:"dateColumn"`
+	DateFormat        ExportDateFormat `db:"date_format" json:"dateFormat"`
+	DescriptionColumn int              `db:"description_column" json:"descriptionColumn"`
+	AmountColumn      null.Int         `db:"amount_column" json:"amountColumn"`
+	DebitColumn       null.Int         `db:"debit_column" json:"debitColumn"`
+	CreditColumn      null.Int         `db:"credit_column" json:"creditColumn"`
+	IDColumn          null.Int         `db:"id_column" json:"idColumn"`
+	InvertAmounts     bool             `db:"invert_amounts" json:"invertAmounts"`
+	CreatedAt         time.Time        `db:"created_at" json:"createdAt"`
+	UpdatedAt         time.Time        `db:"updated_at" json:"updatedAt"`
+}
+
+// StatementImport is the outcome of importing a statement file. Duplicates are the transactions in
+// the file that were already on the account, or that appear in the file more than once. Failed are
+// the transactions that could not be saved
+type StatementImport struct {
+	Format     StatementFormat `json:"format"`
+	Total      int             `json:"total"`
+	Imported   int             `json:"imported"`
+	Duplicates int             `json:"duplicates"`
+	Failed     int             `json:"failed"`
+}
@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/plaid/plaid-go/plaid"
+	"github.com/r3labs/diff"
 	"github.com/volatiletech/null"
 )
 
@@ -20,7 +22,31 @@ type TransactionRepository interface {
 	TransactionsPaginated(ctx context.Context, itemID, accountID string, filters *TransactionFilter) ([]*Transaction, error)
 	CreateTransaction(ctx context.Context, transaction *Transaction) (*Transaction, error)
 	UpdateTransaction(ctx context.Context, transactionID string, transaction *Transaction) (*Transaction, error)
+	UpdateTransactionTx(ctx context.Context, txn Transactioner, transactionID string, transaction *Transaction) error
 	UpdateTransactionMerchantTx(ctx context.Context, txn Transactioner, byMerchantID, toMerchantID string) error
+
+	TransactionChangelogs(ctx context.Context, itemID, transactionID string) ([]*TransactionChangelog, error)
+	CreateTransactionChangelogTx(ctx context.Context, txn Transactioner, changelog *TransactionChangelog) error
+
+	RefundCandidates(ctx context.Context, refund *Transaction, window time.Duration) ([]*Transaction, error)
+	ReceiptCandidates(ctx context.Context, userID uuid.UUID, total float64, from, to time.Time) ([]*Transaction, error)
+	RecurringCandidates(ctx context.Context, userID uuid.UUID, from time.Time) ([]*Transaction, error)
+	StatementCandidates(ctx context.Context, itemID, accountID string, from, to time.Time) ([]*Transaction, error)
+	SpendingTotals(ctx context.Context, userID uuid.UUID, groupBy SpendingGroupBy, filters *TransactionFilter) ([]*SpendingTotal, error)
+	DiscretionarySpending(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*DiscretionarySpend, error)
+	ExportTransactions(ctx context.Context, userID uuid.UUID, filters *TransactionFilter, fn func(row *TransactionExportRow) error) error
+	CashFlowTotals(ctx context.Context, userID uuid.UUID, interval Interval, from, to time.Time, accountIDs []string) ([]*CashFlowTotal, error)
+	TransactionRelation(ctx context.Context, itemID, relationID string) (*TransactionRelation, error)
+	TransactionRelations(ctx context.Context, itemID, transactionID string) ([]*TransactionRelation, error)
+	CreateTransactionRelation(ctx context.Context, relation *TransactionRelation) (*TransactionRelation, error)
+	UpdateTransactionRelation(ctx context.Context, relationID string, relation *TransactionRelation) (*TransactionRelation, error)
+
+	CreateTransactionLocation(ctx context.Context, location *TransactionLocation) error
+
+	TransactionAttachment(ctx context.Context, itemID, attachmentID string) (*TransactionAttachment, error)
+	TransactionAttachments(ctx context.Context, itemID, transactionID string) ([]*TransactionAttachment, error)
+	CreateTransactionAttachment(ctx context.Context, attachment *TransactionAttachment) (*TransactionAttachment, error)
+	DeleteTransactionAttachment(ctx context.Context, itemID, attachmentID string) error
 }
 
 type PaginatedTransactions struct {
@@ -36,11 +62,11 @@ type Transaction struct {
 	CategoryID             null.String `db:"category_id" json:"categoryID"`
 	Name                   string      `db:"name" json:"name"`
 	Pending                bool        `db:"pending" json:"pending"`
-	HasReceipt             bool        `db:"has_receipt" json:"hasReceipt"`
-	ReceiptType            null.String `db:"receipt_type" json:"receiptType"`
+	HasReceipt             bool        `db:"has_receipt" json:"hasReceipt" diff:"-" deepcopier:"skip"`
+	ReceiptType            null.String `db:"receipt_type" json:"receiptType" diff:"-" deepcopier:"skip"`
 	PaymentChannel         string      `db:"payment_channel" json:"paymentChannel"` // ENUM: online, in store, other
 	MerchantID             string      `db:"merchant_id" json:"merchantID"`
-	MerchantName           null.String `json:"merchant_name"`
+	MerchantName           null.String `json:"merchant_name" diff:"-"`
 	UnofficialCurrencyCode null.String `db:"unofficial_currency_code" json:"unofficialCurrencyCode"`
 	ISOCurrencyCode        null.String `db:"iso_currency_code" json:"isoCurrencyCode"`
 	Amount                 float64     `db:"amount" json:"amount"`
@@ -49,9 +75,10 @@ type Transaction struct {
 	AuthorizedDateTime     null.Time   `db:"authorized_datetime" json:"authorizedDateTime"`
 	Date                   time.Time   `db:"date" json:"date"`
 	DateTime               null.Time   `db:"datetime" json:"dateTime" diff:"-"`
-	DeletedAt              null.Time   `db:"deleted_at" json:"deletedAt" diff:"-"`
-	HiddenAt               null.Time   `db:"hidden_at" json:"hiddenAt" diff:"-"`
-	CreatedAt              time.Time   `db:"created_at" json:"-" diff:"-"`
+	DeletedAt              null.Time   `db:"deleted_at" json:"deletedAt" diff:"-" deepcopier:"skip"`
+	HiddenAt               null.Time   `db:"hidden_at" json:"hiddenAt" diff:"-" deepcopier:"skip"`
+	HiddenReason           null.String `db:"hidden_reason" json:"hiddenReason" diff:"-" deepcopier:"skip"`
+	CreatedAt              time.Time   `db:"created_at" json:"-" diff:"-" deepcopier:"skip"`
 	UpdatedAt              time.Time   `db:"updated_at" json:"-" diff:"-"`
 
 	Category    *PlaidCategory          `json:"category" diff:"-"`
@@ -59,8 +86,128 @@ type Transaction struct {
 	Location    *TransactionLocation    `json:"location" diff:"-"`
 }
 
-func (r *Transaction) Filename() string {
-	return fmt.Sprintf("%s.pdf", r.TransactionID)
+// ReceiptKey returns the object key that the receipt for this transaction is stored under.
+// The extension is derived from ReceiptType so it must be set before the receipt is written
+func (r *Transaction) ReceiptKey() string {
+	ext := "pdf"
+	if r.ReceiptType.Valid && r.ReceiptType.String != "" {
+		ext = r.ReceiptType.String
+	}
+
+	return fmt.Sprintf("receipts/%s/%s.%s", r.ItemID, r.TransactionID, ext)
+}
+
+// CurrencyCode returns the ISO currency code of the transaction, falling back to the
+// unofficial code Plaid reports for currencies such as cryptocurrencies
+func (r *Transaction) CurrencyCode() string {
+	if r.ISOCurrencyCode.Valid {
+		return r.ISOCurrencyCode.String
+	}
+
+	return r.UnofficialCurrencyCode.String
+}
+
+type TransactionChangeSource string
+
+const (
+	TransactionChangeSourceImporter TransactionChangeSource = "importer"
+	TransactionChangeSourceUser     TransactionChangeSource = "user"
+	TransactionChangeSourceRule     TransactionChangeSource = "rule"
+)
+
+// TransactionChangelog records a single change made to a transaction, who or what made it,
+// and the before and after value of every field that was modified
+type TransactionChangelog struct {
+	ChangelogID   uint64                  `db:"changelog_id" json:"changelogID"`
+	ItemID        string                  `db:"item_id" json:"itemID"`
+	TransactionID string                  `db:"transaction_id" json:"transactionID"`
+	Source        TransactionChangeSource `db:"source" json:"source"`
+	Actor         string                  `db:"actor" json:"actor"`
+	Changelog     TransactionChanges      `db:"changelog" json:"changelog"`
+	CreatedAt     time.Time               `db:"created_at" json:"createdAt"`
+}
+
+type TransactionChanges diff.Changelog
+
+func (c TransactionChanges) Value() (driver.Value, error) {
+
+	if len(c) == 0 {
+		return []byte(`[]`), nil
+	}
+
+	return json.Marshal(c)
+
+}
+
+func (c *TransactionChanges) Scan(value interface{}) error {
+
+	switch data := value.(type) {
+	case []byte:
+		err := json.Unmarshal(data, c)
+		if err != nil {
+			return fmt.Errorf("failed to scan string into TransactionChanges: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to scan value into TransactionChanges: unsupported type %T", value)
+	}
+
+	return nil
+
+}
+
+type TransactionRelationType string
+
+const (
+	// TransactionRelationTypeRefund links a refund (TransactionID) to the charge it reverses (RelatedTransactionID)
+	TransactionRelationTypeRefund TransactionRelationType = "refund"
+)
+
+type TransactionRelationStatus string
+
+const (
+	TransactionRelationStatusSuggested TransactionRelationStatus = "suggested"
+	TransactionRelationStatusConfirmed TransactionRelationStatus = "confirmed"
+	TransactionRelationStatusRejected  TransactionRelationStatus = "rejected"
+)
+
+var AllTransactionRelationStatuses = []TransactionRelationStatus{
+	TransactionRelationStatusSuggested, TransactionRelationStatusConfirmed, TransactionRelationStatusRejected,
+}
+
+func (s TransactionRelationStatus) IsValid() bool {
+	for _, status := range AllTransactionRelationStatuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TransactionRelation struct {
+	RelationID           string                    `db:"relation_id" json:"relationID"`
+	ItemID               string                    `db:"item_id" json:"itemID"`
+	TransactionID        string                    `db:"transaction_id" json:"transactionID"`
+	RelatedTransactionID string                    `db:"related_transaction_id" json:"relatedTransactionID"`
+	Type                 TransactionRelationType   `db:"type" json:"type"`
+	Status               TransactionRelationStatus `db:"status" json:"status"`
+	CreatedAt            time.Time                 `db:"created_at" json:"createdAt"`
+	UpdatedAt            time.Time                 `db:"updated_at" json:"updatedAt"`
+}
+
+// TransactionAttachment is a file, such as a receipt or invoice, that has been uploaded
+// against a transaction. A transaction may have any number of attachments
+type TransactionAttachment struct {
+	AttachmentID  string      `db:"attachment_id" json:"attachmentID"`
+	ItemID        string      `db:"item_id" json:"itemID"`
+	TransactionID string      `db:"transaction_id" json:"transactionID"`
+	ObjectKey     string      `db:"object_key" json:"-"`
+	ThumbnailKey  null.String `db:"thumbnail_key" json:"-"`
+	Filename      string      `db:"filename" json:"filename"`
+	ContentType   string      `db:"content_type" json:"contentType"`
+	Size          int64       `db:"size" json:"size"`
+	Checksum      string      `db:"checksum" json:"checksum"`
+	UploadedAt    time.Time   `db:"uploaded_at" json:"uploadedAt"`
 }
 
 type TransactionCategory struct {
@@ -71,7 +218,11 @@ type TransactionCategory struct {
 
 type TransactionReceipt struct {
 	Get null.String
+	// Deprecated: objects written to a presigned put url are not tracked against the transaction.
+	// Receipts should be uploaded through the API instead
 	Put null.String
+	// ThumbnailURL is only set for receipts that are images
+	ThumbnailURL null.String
 }
 
 type TransactionFilter struct {
@@ -84,6 +235,7 @@ type TransactionFilter struct {
 	AmountDir         null.Float64
 	CategoryID        null.String
 	MerchantID        null.String
+	Hidden            null.Bool
 }
 
 func (f *TransactionFilter) BuildFromURLValues(values url.Values) error {
@@ -92,6 +244,11 @@ func (f *TransactionFilter) BuildFromURLValues(values url.Values) error {
 		f.CategoryID = null.NewString(categoryID, true)
 	}
 
+	merchantID := values.Get("merchantID")
+	if merchantID != "" {
+		f.MerchantID = null.NewString(merchantID, true)
+	}
+
 	fromTransactionID := values.Get("fromTransactionID")
 	if fromTransactionID != "" {
 		f.FromTransactionID = null.NewString(fromTransactionID, true)
@@ -163,6 +320,16 @@ func (f *TransactionFilter) BuildFromURLValues(values url.Values) error {
 		}
 	}
 
+	hidden := values.Get("hidden")
+	if hidden != "" {
+		parsedBool, err := strconv.ParseBool(hidden)
+		if err != nil {
+			return err
+		}
+
+		f.Hidden = null.NewBool(parsedBool, true)
+	}
+
 	return nil
 }
 
@@ -269,7 +436,7 @@ type TransactionLocation struct {
 }
 
 func (tl *TransactionLocation) IsEmpty() bool {
-	return tl.Address.Valid
+	return !tl.Address.Valid && !tl.City.Valid && !tl.Region.Valid && !tl.PostalCode.Valid && !tl.Country.Valid
 }
 
 type TransactionPaymentMeta struct {
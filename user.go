@@ -6,22 +6,30 @@ import (
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/volatiletech/null"
 )
 
 type UserRepository interface {
 	User(ctx context.Context, id uuid.UUID) (*User, error)
 	UserByEmail(ctx context.Context, email string) (*User, error)
+	Users(ctx context.Context) ([]*User, error)
+	UsersByItemID(ctx context.Context, itemID string) ([]*User, error)
+	UserByCalendarToken(ctx context.Context, token string) (*User, error)
 	CreateUser(ctx context.Context, user *User) (*User, error)
 	UpdateUser(ctx context.Context, id uuid.UUID, user *User) (*User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 }
 
+// User is an account holder. CalendarToken authenticates the calendar feed of the user
 type User struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	Email        string    `db:"email" json:"email"`
-	Auth0Subject string    `db:"auth0_subject" json:"auth0Subject"`
-	CreatedAt    time.Time `db:"created_at" json:"-"`
-	UpdatedAt    time.Time `db:"updated_at" json:"-"`
+	ID            uuid.UUID   `db:"id" json:"id"`
+	Email         string      `db:"email" json:"email"`
+	Auth0Subject  string      `db:"auth0_subject" json:"auth0Subject"`
+	BaseCurrency  string      `db:"base_currency" json:"baseCurrency"`
+	IsAdmin       bool        `db:"is_admin" json:"isAdmin"`
+	CalendarToken null.String `db:"calendar_token" json:"-"`
+	CreatedAt     time.Time   `db:"created_at" json:"-"`
+	UpdatedAt     time.Time   `db:"updated_at" json:"-"`
 }
 
 func (u *User) Validate() error {
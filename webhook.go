@@ -2,9 +2,14 @@ package ledger
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/plaid/plaid-go/plaid"
+	"github.com/volatiletech/null"
 )
 
 type WebhookRepository interface {
@@ -66,3 +71,88 @@ func (c WebhookCode) SendEmail() bool {
 
 	return false
 }
+
+type WebhookEndpointRepository interface {
+	WebhookEndpoint(ctx context.Context, userID uuid.UUID, endpointID string) (*WebhookEndpoint, error)
+	WebhookEndpoints(ctx context.Context, userID uuid.UUID) ([]*WebhookEndpoint, error)
+	CreateWebhookEndpoint(ctx context.Context, endpoint *WebhookEndpoint) (*WebhookEndpoint, error)
+	UpdateWebhookEndpoint(ctx context.Context, endpointID string, endpoint *WebhookEndpoint) (*WebhookEndpoint, error)
+	DeleteWebhookEndpoint(ctx context.Context, userID uuid.UUID, endpointID string) error
+
+	WebhookDeliveries(ctx context.Context, userID uuid.UUID, endpointID string, limit uint64) ([]*WebhookDelivery, error)
+	CreateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error
+}
+
+// WebhookEndpoint is an HTTPS url a user has registered to receive their events at. Every request is
+// signed with Secret so the receiver can check it came from us. An endpoint without EventTypes
+// receives every event
+type WebhookEndpoint struct {
+	EndpointID  string      `db:"endpoint_id" json:"endpointID"`
+	UserID      uuid.UUID   `db:"user_id" json:"userID"`
+	URL         string      `db:"url" json:"url"`
+	Description null.String `db:"description" json:"description"`
+	Secret      string      `db:"secret" json:"-"`
+	EventTypes  EventTypes  `db:"event_types" json:"eventTypes"`
+	Enabled     bool        `db:"enabled" json:"enabled"`
+	CreatedAt   time.Time   `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time   `db:"updated_at" json:"updatedAt"`
+}
+
+// Subscribed returns whether events of eventType are delivered to the endpoint
+func (e *WebhookEndpoint) Subscribed(eventType EventType) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+
+	for _, t := range e.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type EventTypes []EventType
+
+func (t EventTypes) Value() (driver.Value, error) {
+
+	if len(t) == 0 {
+		return []byte(`[]`), nil
+	}
+
+	return json.Marshal(t)
+
+}
+
+func (t *EventTypes) Scan(value interface{}) error {
+
+	switch data := value.(type) {
+	case []byte:
+		err := json.Unmarshal(data, t)
+		if err != nil {
+			return fmt.Errorf("failed to scan string into EventTypes: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to scan value into EventTypes: unsupported type %T", value)
+	}
+
+	return nil
+
+}
+
+// WebhookDelivery is a record of one attempt to deliver an event to an endpoint. StatusCode is
+// not set when the endpoint could not be reached, Error says why the attempt failed
+type WebhookDelivery struct {
+	DeliveryID string      `db:"delivery_id" json:"deliveryID"`
+	EndpointID string      `db:"endpoint_id" json:"endpointID"`
+	UserID     uuid.UUID   `db:"user_id" json:"userID"`
+	EventID    string      `db:"event_id" json:"eventID"`
+	EventType  EventType   `db:"event_type" json:"eventType"`
+	Attempt    uint        `db:"attempt" json:"attempt"`
+	Succeeded  bool        `db:"succeeded" json:"succeeded"`
+	StatusCode null.Int    `db:"status_code" json:"statusCode"`
+	Error      null.String `db:"error" json:"error"`
+	DurationMS uint        `db:"duration_ms" json:"durationMS"`
+	CreatedAt  time.Time   `db:"created_at" json:"createdAt"`
+}